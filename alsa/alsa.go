@@ -18,21 +18,40 @@
 // ALSA output driver.
 package alsa
 
-import "github.com/vchimishuk/chub/alsa/asoundlib"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vchimishuk/chub/alsa/asoundlib"
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/logger"
+)
 
 // Alsa aoutput driter handler structure.
 type Alsa struct {
-	handle *asoundlib.Handle
+	device     string
+	bufferTime time.Duration
+	periodTime time.Duration
+	handle     *asoundlib.Handle
+	// Last reported hardware parameters.
+	params string
 }
 
-// New returns newly initialized alsa output driver.
-func New() *Alsa {
-	return &Alsa{}
+// New returns newly initialized alsa output driver which plays
+// to the given device. Zero bufferTime or periodTime means
+// device default.
+func New(device string, bufferTime, periodTime time.Duration) *Alsa {
+	return &Alsa{
+		device:     device,
+		bufferTime: bufferTime,
+		periodTime: periodTime,
+	}
 }
 
 func (a *Alsa) Open() error {
 	a.handle = asoundlib.New()
-	err := a.handle.Open("default", asoundlib.StreamTypePlayback, asoundlib.ModeBlock)
+	err := a.handle.Open(a.device, asoundlib.StreamTypePlayback, asoundlib.ModeBlock)
 	if err != nil {
 		return err
 	}
@@ -40,24 +59,68 @@ func (a *Alsa) Open() error {
 	a.handle.SampleFormat = asoundlib.SampleFormatS16
 	a.handle.SampleRate = 44100
 	a.handle.Channels = 2
-	a.handle.ApplyHwParams()
+	a.handle.BufferTime = a.bufferTime
+	a.handle.PeriodTime = a.periodTime
+	a.params = ""
+	err = a.applyHwParams()
+	if err != nil {
+		a.handle.Close()
+		return err
+	}
 
 	return nil
 }
 
 func (a *Alsa) SetSampleRate(rate int) error {
 	a.handle.SampleRate = rate
-	a.handle.ApplyHwParams()
+	err := a.applyHwParams()
+	if err != nil {
+		return err
+	}
+	if a.handle.SampleRate != rate {
+		return fmt.Errorf("unsupported sample rate: %d", rate)
+	}
 
-	// TODO:
 	return nil
 }
 
 func (a *Alsa) SetChannels(channels int) error {
+	prev := a.handle.Channels
 	a.handle.Channels = channels
-	a.handle.ApplyHwParams()
+	err := a.applyHwParams()
+	if err != nil {
+		a.handle.Channels = prev
+		a.handle.ApplyHwParams()
+		return err
+	}
+
+	return nil
+}
+
+func (a *Alsa) SetSampleFormat(f format.SampleFormat) error {
+	var sf asoundlib.SampleFormat
+	switch f {
+	case format.SampleFormatS16:
+		sf = asoundlib.SampleFormatS16
+	case format.SampleFormatS24:
+		sf = asoundlib.SampleFormatS24
+	case format.SampleFormatS32:
+		sf = asoundlib.SampleFormatS32
+	case format.SampleFormatF32:
+		sf = asoundlib.SampleFormatFloat
+	default:
+		return errors.New("unsupported sample format")
+	}
+
+	prev := a.handle.SampleFormat
+	a.handle.SampleFormat = sf
+	err := a.applyHwParams()
+	if err != nil {
+		a.handle.SampleFormat = prev
+		a.handle.ApplyHwParams()
+		return err
+	}
 
-	// TODO:
 	return nil
 }
 
@@ -73,6 +136,18 @@ func (a *Alsa) Write(buf []byte) (written int, err error) {
 	return a.handle.Write(buf)
 }
 
+// Delay returns time needed to play data written to the device
+// but not played yet.
+func (a *Alsa) Delay() (time.Duration, error) {
+	frames, err := a.handle.Delay()
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(frames) * time.Second /
+		time.Duration(a.handle.SampleRate), nil
+}
+
 func (a *Alsa) Flush() error {
 	// TODO: Error.
 	a.handle.Reset()
@@ -101,3 +176,26 @@ func (a *Alsa) SetVolume(vol int) error {
 func (a *Alsa) Close() error {
 	return a.handle.Close()
 }
+
+// applyHwParams applies hardware parameters and reports the ones
+// device has actually chosen.
+func (a *Alsa) applyHwParams() error {
+	err := a.handle.ApplyHwParams()
+	if err != nil {
+		return err
+	}
+
+	h := a.handle
+	buf := time.Duration(h.BufferSize) * time.Second / time.Duration(h.SampleRate)
+	per := time.Duration(h.PeriodSize) * time.Second / time.Duration(h.SampleRate)
+	p := fmt.Sprintf("%d Hz, %d channels, %d bytes frame, "+
+		"buffer %d frames (%s), period %d frames (%s)",
+		h.SampleRate, h.Channels, h.FrameSize(),
+		h.BufferSize, buf, h.PeriodSize, per)
+	if p != a.params {
+		logger.Info("ALSA device %s: %s", a.device, p)
+		a.params = p
+	}
+
+	return nil
+}
@@ -24,6 +24,7 @@ import "C"
 
 import (
 	"fmt"
+	"time"
 	"unsafe"
 )
 
@@ -91,11 +92,14 @@ const (
 	// Signed 16 bit CPU endian
 	SampleFormatS16 = C.SND_PCM_FORMAT_S16
 	// SND_PCM_FORMAT_U16 	Unsigned 16 bit CPU endian
-	// SND_PCM_FORMAT_S24 	Signed 24 bit CPU endian
+	// Signed 24 bit CPU endian
+	SampleFormatS24 = C.SND_PCM_FORMAT_S24
 	// SND_PCM_FORMAT_U24 	Unsigned 24 bit CPU endian
-	// SND_PCM_FORMAT_S32 	Signed 32 bit CPU endian
+	// Signed 32 bit CPU endian
+	SampleFormatS32 = C.SND_PCM_FORMAT_S32
 	// SND_PCM_FORMAT_U32 	Unsigned 32 bit CPU endian
-	// SND_PCM_FORMAT_FLOAT 	Float 32 bit CPU endian
+	// Float 32 bit CPU endian
+	SampleFormatFloat = C.SND_PCM_FORMAT_FLOAT
 	// SND_PCM_FORMAT_FLOAT64 	Float 64 bit CPU endian
 	// SND_PCM_FORMAT_IEC958_SUBFRAME 	IEC-958 CPU Endian
 )
@@ -116,6 +120,14 @@ type Handle struct {
 	SampleRate int
 	// Channels in the stream. 2 for stereo.
 	Channels int
+	// Requested ring buffer length. Zero means driver default.
+	BufferTime time.Duration
+	// Requested period length. Zero means driver default.
+	PeriodTime time.Duration
+	// Ring buffer size in frames negotiated with the device.
+	BufferSize int
+	// Period size in frames negotiated with the device.
+	PeriodSize int
 }
 
 // New returns newly initialized ALSA handler.
@@ -151,6 +163,7 @@ func (handle *Handle) ApplyHwParams() error {
 		return fmt.Errorf("Cannot allocate hardware parameter structure. %s",
 			strError(err))
 	}
+	defer C.snd_pcm_hw_params_free(cHwParams)
 	err = C.snd_pcm_hw_params_any(handle.cHandle, cHwParams)
 	if err < 0 {
 		return fmt.Errorf("Cannot initialize hardware parameter structure. %s",
@@ -172,11 +185,29 @@ func (handle *Handle) ApplyHwParams() error {
 		return fmt.Errorf("Cannot set sample rate. %s",
 			strError(err))
 	}
+	// Device can choose different rate if requested one is not supported.
+	handle.SampleRate = int(cSampleRate)
 	err = C.snd_pcm_hw_params_set_channels(handle.cHandle, cHwParams, C.uint(handle.Channels))
 	if err < 0 {
 		return fmt.Errorf("Cannot set number of channels. %s",
 			strError(err))
 	}
+	if handle.BufferTime > 0 {
+		var cBufferTime C.uint = C.uint(handle.BufferTime / time.Microsecond)
+		err = C.snd_pcm_hw_params_set_buffer_time_near(handle.cHandle, cHwParams, &cBufferTime, nil)
+		if err < 0 {
+			return fmt.Errorf("Cannot set buffer time. %s",
+				strError(err))
+		}
+	}
+	if handle.PeriodTime > 0 {
+		var cPeriodTime C.uint = C.uint(handle.PeriodTime / time.Microsecond)
+		err = C.snd_pcm_hw_params_set_period_time_near(handle.cHandle, cHwParams, &cPeriodTime, nil)
+		if err < 0 {
+			return fmt.Errorf("Cannot set period time. %s",
+				strError(err))
+		}
+	}
 	// Drain current data and make sure we aren't underrun.
 	C.snd_pcm_drain(handle.cHandle)
 	err = C.snd_pcm_hw_params(handle.cHandle, cHwParams)
@@ -184,7 +215,21 @@ func (handle *Handle) ApplyHwParams() error {
 		return fmt.Errorf("Cannot set hardware parameters. %s",
 			strError(err))
 	}
-	C.snd_pcm_hw_params_free(cHwParams)
+	// Device is free to choose sizes different from requested ones.
+	var cBufferSize C.snd_pcm_uframes_t
+	err = C.snd_pcm_hw_params_get_buffer_size(cHwParams, &cBufferSize)
+	if err < 0 {
+		return fmt.Errorf("Cannot get buffer size. %s",
+			strError(err))
+	}
+	handle.BufferSize = int(cBufferSize)
+	var cPeriodSize C.snd_pcm_uframes_t
+	err = C.snd_pcm_hw_params_get_period_size(cHwParams, &cPeriodSize, nil)
+	if err < 0 {
+		return fmt.Errorf("Cannot get period size. %s",
+			strError(err))
+	}
+	handle.PeriodSize = int(cPeriodSize)
 
 	return nil
 }
@@ -242,6 +287,26 @@ func (handle *Handle) Write(buf []byte) (wrote int, err error) {
 	return wrote, nil
 }
 
+// Delay returns number of frames written to the device but not
+// played yet.
+func (handle *Handle) Delay() (frames int, err error) {
+	var cDelay C.snd_pcm_sframes_t
+
+	e := C.snd_pcm_delay(handle.cHandle, &cDelay)
+	if e == -C.EPIPE {
+		// Underrun, everything is already played.
+		return 0, nil
+	}
+	if e < 0 {
+		return 0, fmt.Errorf("Delay failed. %s", strError(e))
+	}
+	if cDelay < 0 {
+		return 0, nil
+	}
+
+	return int(cDelay), nil
+}
+
 func (handle *Handle) Reset() {
 	C.snd_pcm_reset(handle.cHandle)
 	// TODO: Error handling.
@@ -290,6 +355,8 @@ func (handle *Handle) SampleSize() int {
 	case SampleFormatS16LE, SampleFormatS16BE,
 		SampleFormatU16LE, SampleFormatU16BE:
 		return 2
+	case SampleFormatS24, SampleFormatS32, SampleFormatFloat:
+		return 4
 	}
 
 	return 1
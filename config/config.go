@@ -20,17 +20,46 @@ package config
 import (
 	"errors"
 	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/vchimishuk/chub/output"
 	"github.com/vchimishuk/config"
+	"golang.org/x/text/encoding/htmlindex"
 )
 
+// Names of audio format implementations.
+var formatNames = []string{"ffmpeg", "flac", "mp3", "ogg", "wav"}
+
 var spec = &config.Spec{
 	Strict: true,
 	Properties: []*config.PropertySpec{
 		&config.PropertySpec{
 			Type:   config.TypeString,
-			Name:   "output",
-			Parser: parseEnum([]string{"alsa", "oss"}),
+			Name:   "cue-encoding",
+			Parser: parseEncoding,
+		},
+		&config.PropertySpec{
+			Type: config.TypeString,
+			Name: "ffmpeg-add-extensions",
+		},
+		&config.PropertySpec{
+			Type: config.TypeString,
+			Name: "ffmpeg-remove-extensions",
+		},
+		&config.PropertySpec{
+			Type:   config.TypeString,
+			Name:   "input",
+			Parser: ParseEnum([]string{"local", "network"}),
+		},
+		&config.PropertySpec{
+			Type: config.TypeString,
+			Name: "network-server",
+		},
+		&config.PropertySpec{
+			Type:   config.TypeInt,
+			Name:   "output-rate",
+			Parser: ParseRange(8000, 384000),
 		},
 		&config.PropertySpec{
 			Type: config.TypeString,
@@ -45,18 +74,93 @@ var spec = &config.Spec{
 			Name: "vfs-root",
 		},
 	},
+	Blocks: []*config.BlockSpec{
+		&config.BlockSpec{
+			Name: "formats",
+			Properties: []*config.PropertySpec{
+				&config.PropertySpec{
+					Type:   config.TypeString,
+					Name:   "*",
+					Parser: ParseEnum(formatNames),
+				},
+			},
+		},
+		&config.BlockSpec{
+			Name: "eq-presets",
+			Properties: []*config.PropertySpec{
+				&config.PropertySpec{
+					Type:   config.TypeString,
+					Name:   "*",
+					Parser: parseGains,
+				},
+			},
+		},
+	},
 }
 
 func ParseFile(path string) (*config.Config, error) {
-	return config.ParseFile(spec, path)
+	return config.ParseFile(fullSpec(), path)
 }
 
 // TODO: Use io.Reader instead of string.
 func Parse(s string) (*config.Config, error) {
-	return config.Parse(spec, s)
+	return config.Parse(fullSpec(), s)
 }
 
-func parseEnum(vals []string) func(v any) (any, error) {
+// fullSpec returns spec extended with properties of all registered
+// output drivers.
+func fullSpec() *config.Spec {
+	props := []*config.PropertySpec{
+		&config.PropertySpec{
+			Type:   config.TypeString,
+			Name:   "output",
+			Parser: parseEnumList(output.Names()),
+		},
+	}
+	props = append(props, spec.Properties...)
+	props = append(props, output.Properties()...)
+
+	return &config.Spec{
+		Strict:     spec.Strict,
+		Properties: props,
+		Blocks:     spec.Blocks,
+	}
+}
+
+// EqPresets returns equalizer presets defined in eq-presets block.
+func EqPresets(c *config.Config) map[string][]int {
+	presets := make(map[string][]int)
+	b := c.Block("eq-presets")
+	if b != nil {
+		for _, p := range b.Properties {
+			presets[p.Name] = p.Value.([]int)
+		}
+	}
+
+	return presets
+}
+
+// Formats returns audio format names to decode files with
+// for file extensions configured in formats block.
+func Formats(c *config.Config) map[string]string {
+	fmts := make(map[string]string)
+	b := c.Block("formats")
+	if b != nil {
+		for _, p := range b.Properties {
+			fmts[strings.ToLower(p.Name)] = p.Value.(string)
+		}
+	}
+
+	return fmts
+}
+
+// Outputs returns list of output drivers to use.
+func Outputs(c *config.Config) []string {
+	return strings.Fields(c.StringOr("output", "alsa"))
+}
+
+// ParseEnum returns property parser which accepts only given values.
+func ParseEnum(vals []string) func(v any) (any, error) {
 	return func(v any) (any, error) {
 		s := v.(string)
 		if !slices.Contains(vals, s) {
@@ -66,3 +170,83 @@ func parseEnum(vals []string) func(v any) (any, error) {
 		return s, nil
 	}
 }
+
+// parseEnumList parses space separated list of unique enum values.
+// Value is kept as a string.
+func parseEnumList(vals []string) func(v any) (any, error) {
+	return func(v any) (any, error) {
+		s := v.(string)
+		fs := strings.Fields(s)
+		if len(fs) == 0 {
+			return nil, errors.New("empty value")
+		}
+		for i, f := range fs {
+			if !slices.Contains(vals, f) {
+				return nil, errors.New("unsupported value")
+			}
+			if slices.Contains(fs[:i], f) {
+				return nil, errors.New("duplicate value")
+			}
+		}
+
+		return s, nil
+	}
+}
+
+// ParseRange returns property parser which accepts integers
+// in lo..hi range.
+func ParseRange(lo int, hi int) func(v any) (any, error) {
+	return func(v any) (any, error) {
+		i := v.(int)
+		if i < lo || i > hi {
+			return nil, errors.New("value out of range")
+		}
+
+		return i, nil
+	}
+}
+
+// parseEncoding accepts text encoding labels, e.g. "windows-1251".
+func parseEncoding(v any) (any, error) {
+	s := v.(string)
+	_, err := htmlindex.Get(s)
+	if err != nil {
+		return nil, errors.New("unsupported encoding")
+	}
+
+	return s, nil
+}
+
+// parseSignedInt parses string containing signed integer.
+func parseSignedInt(v any) (any, error) {
+	i, err := strconv.Atoi(v.(string))
+	if err != nil {
+		return nil, errors.New("invalid integer value")
+	}
+
+	return i, nil
+}
+
+// parseGains parses space separated list of equalizer bands gains.
+func parseGains(v any) (any, error) {
+	var gains []int
+
+	for _, f := range strings.Fields(v.(string)) {
+		g, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, errors.New("invalid gain value")
+		}
+		gains = append(gains, g)
+	}
+
+	return gains, nil
+}
+
+func formatGains(gains []int) string {
+	s := make([]string, 0, len(gains))
+	for _, g := range gains {
+		s = append(s, strconv.Itoa(g))
+	}
+
+	return strings.Join(s, " ")
+}
@@ -18,11 +18,29 @@
 package config
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/vchimishuk/chub/assert"
+	"github.com/vchimishuk/chub/output"
+	"github.com/vchimishuk/config"
 )
 
+func init() {
+	// Output drivers register themselves in their packages
+	// which are not imported here.
+	for _, n := range []string{"alsa", "httpd", "oss", "recorder"} {
+		output.Register(n, nil, nil)
+	}
+	output.Register("test", []*config.PropertySpec{
+		&config.PropertySpec{
+			Type:   config.TypeInt,
+			Name:   "test-level",
+			Parser: ParseRange(1, 10),
+		},
+	}, nil)
+}
+
 func TestOutput(t *testing.T) {
 	c, err := Parse(`output = "alsa"`)
 	assert.Nil(t, err)
@@ -36,6 +54,33 @@ func TestOutput(t *testing.T) {
 	assert.Error(t, err, "1: unsupported value")
 }
 
+func TestOutputs(t *testing.T) {
+	c, err := Parse(`output = "alsa httpd  recorder"`)
+	assert.Nil(t, err)
+	assert.True(t, slices.Equal(Outputs(c),
+		[]string{"alsa", "httpd", "recorder"}))
+
+	c, err = Parse(``)
+	assert.Nil(t, err)
+	assert.True(t, slices.Equal(Outputs(c), []string{"alsa"}))
+
+	_, err = Parse(`output = "alsa unsupported"`)
+	assert.Error(t, err, "1: unsupported value")
+	_, err = Parse(`output = "alsa alsa"`)
+	assert.Error(t, err, "1: duplicate value")
+	_, err = Parse(`output = " "`)
+	assert.Error(t, err, "1: empty value")
+}
+
+func TestOutputProperties(t *testing.T) {
+	c, err := Parse(`test-level = 5`)
+	assert.Nil(t, err)
+	assert.True(t, c.Int("test-level") == 5)
+
+	_, err = Parse(`test-level = 11`)
+	assert.Error(t, err, "1: value out of range")
+}
+
 func TestServerHost(t *testing.T) {
 	c, err := Parse(`server-host = "localhost"`)
 	assert.Nil(t, err)
@@ -53,3 +98,65 @@ func TestVfsRoot(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, c.String("vfs-root") == "/home/user/music")
 }
+
+func TestCueEncoding(t *testing.T) {
+	c, err := Parse(`cue-encoding = "cp1251"`)
+	assert.Nil(t, err)
+	assert.True(t, c.String("cue-encoding") == "cp1251")
+
+	_, err = Parse(`cue-encoding = "klingon"`)
+	assert.Error(t, err, "1: unsupported encoding")
+}
+
+func TestEqPresets(t *testing.T) {
+	c, err := Parse(`eq-presets {
+                             rock = "5 4 3 1 -1 -1 1 3 4 5"
+                         }`)
+	assert.Nil(t, err)
+	p := EqPresets(c)
+	assert.True(t, len(p) == 1)
+	assert.True(t, len(p["rock"]) == 10)
+	assert.True(t, p["rock"][4] == -1)
+
+	_, err = Parse(`eq-presets {
+                            rock = "5 x"
+                        }`)
+	assert.Error(t, err, "2: invalid gain value")
+}
+
+func TestFormats(t *testing.T) {
+	c, err := Parse(`formats {
+                             mp3 = "mp3"
+                             FLAC = "flac"
+                         }`)
+	assert.Nil(t, err)
+	f := Formats(c)
+	assert.True(t, len(f) == 2)
+	assert.True(t, f["mp3"] == "mp3")
+	assert.True(t, f["flac"] == "flac")
+
+	_, err = Parse(`formats {
+                            mp3 = "madplay"
+                        }`)
+	assert.Error(t, err, "2: unsupported value")
+}
+
+func TestOutputRate(t *testing.T) {
+	c, err := Parse(`output-rate = 48000`)
+	assert.Nil(t, err)
+	assert.True(t, c.Int("output-rate") == 48000)
+
+	_, err = Parse(`output-rate = 100`)
+	assert.Error(t, err, "1: value out of range")
+}
+
+func TestInput(t *testing.T) {
+	c, err := Parse(`input = "network"
+                         network-server = "192.168.1.2:7115"`)
+	assert.Nil(t, err)
+	assert.True(t, c.String("input") == "network")
+	assert.True(t, c.String("network-server") == "192.168.1.2:7115")
+
+	_, err = Parse(`input = "usb"`)
+	assert.Error(t, err, "1: unsupported value")
+}
@@ -32,11 +32,43 @@ var stateSpec = &config.Spec{
 			Type: config.TypeInt,
 			Name: "volume",
 		},
+		&config.PropertySpec{
+			Type:   config.TypeString,
+			Name:   "balance",
+			Parser: parseSignedInt,
+		},
+		&config.PropertySpec{
+			Type: config.TypeBool,
+			Name: "eq",
+		},
+		&config.PropertySpec{
+			Type:   config.TypeString,
+			Name:   "eq-gains",
+			Parser: parseGains,
+		},
+		&config.PropertySpec{
+			Type: config.TypeBool,
+			Name: "mono",
+		},
+		&config.PropertySpec{
+			Type: config.TypeBool,
+			Name: "swap-channels",
+		},
 	},
 }
 
 type State struct {
 	Volume int
+	// Equalizer is turned on.
+	Eq bool
+	// Equalizer bands gains.
+	EqGains []int
+	// Stereo balance -100..100.
+	Balance int
+	// Mono output is turned on.
+	Mono bool
+	// Left and right channels are swapped.
+	SwapChannels bool
 }
 
 func LoadState(path string) (*State, error) {
@@ -45,13 +77,32 @@ func LoadState(path string) (*State, error) {
 		return nil, err
 	}
 
+	var gains []int
+	if c.Has("eq-gains") {
+		gains = c.Any("eq-gains").([]int)
+	}
+
 	return &State{
-		Volume: c.IntOr("volume", 50),
+		Volume:       c.IntOr("volume", 50),
+		Eq:           c.BoolOr("eq", false),
+		EqGains:      gains,
+		Balance:      c.AnyOr("balance", 0).(int),
+		Mono:         c.BoolOr("mono", false),
+		SwapChannels: c.BoolOr("swap-channels", false),
 	}, nil
 }
 
 func SaveState(p string, st *State) error {
 	s := fmt.Sprintf("volume = %d\n", st.Volume)
+	s += fmt.Sprintf("eq = %t\n", st.Eq)
+	if st.EqGains != nil {
+		s += fmt.Sprintf("eq-gains = \"%s\"\n", formatGains(st.EqGains))
+	}
+	// Config parser does not support negative integers,
+	// so balance is stored as a string.
+	s += fmt.Sprintf("balance = \"%d\"\n", st.Balance)
+	s += fmt.Sprintf("mono = %t\n", st.Mono)
+	s += fmt.Sprintf("swap-channels = %t\n", st.SwapChannels)
 
 	d, _ := path.Split(p)
 	if d != "" {
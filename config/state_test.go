@@ -19,6 +19,7 @@ package config
 
 import (
 	"os"
+	"slices"
 	"testing"
 
 	"github.com/vchimishuk/chub/assert"
@@ -31,11 +32,22 @@ func TestLoadState(t *testing.T) {
 	f.Close()
 	defer os.Remove(fname)
 
-	st := &State{Volume: 45}
+	st := &State{
+		Volume:       45,
+		Eq:           true,
+		EqGains:      []int{1, -2, 3},
+		Balance:      -20,
+		SwapChannels: true,
+	}
 	err = SaveState(fname, st)
 	assert.Nil(t, err)
 
 	st2, err := LoadState(fname)
 	assert.Nil(t, err)
 	assert.True(t, st2.Volume == 45)
+	assert.True(t, st2.Eq)
+	assert.True(t, slices.Equal(st2.EqGains, []int{1, -2, 3}))
+	assert.True(t, st2.Balance == -20)
+	assert.True(t, !st2.Mono)
+	assert.True(t, st2.SwapChannels)
 }
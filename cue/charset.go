@@ -0,0 +1,175 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package cue
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Encoding of sheets which are not in UTF-8
+// and whose encoding can not be detected.
+var fallbackEncoding encoding.Encoding = charmap.Windows1252
+
+// SetFallbackEncoding sets encoding of sheets which are neither
+// in UTF-8 nor recognized as Japanese or Cyrillic text. Name is
+// an encoding label as defined by WHATWG, e.g. "windows-1250".
+func SetFallbackEncoding(name string) error {
+	e, err := htmlindex.Get(name)
+	if err != nil {
+		return fmt.Errorf("unsupported encoding %s", name)
+	}
+	fallbackEncoding = e
+
+	return nil
+}
+
+// decode converts sheet data to UTF-8. UTF-8 and UTF-16 are recognized
+// by byte order mark, UTF-16 without one by zero bytes. Data which
+// is not valid UTF-8 is checked for Shift-JIS and Windows-1251
+// encodings, otherwise the fallback encoding is used.
+func decode(b []byte) string {
+	var e encoding.Encoding
+
+	switch {
+	case bytes.HasPrefix(b, []byte{0xef, 0xbb, 0xbf}):
+		return string(b[3:])
+	case bytes.HasPrefix(b, []byte{0xff, 0xfe}):
+		e = unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+	case bytes.HasPrefix(b, []byte{0xfe, 0xff}):
+		e = unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+	default:
+		e = detectEncoding(b)
+	}
+	if e == nil {
+		return string(b)
+	}
+	s, err := e.NewDecoder().Bytes(b)
+	if err != nil {
+		return string(b)
+	}
+
+	return string(s)
+}
+
+// detectEncoding guesses encoding of the data without byte order mark.
+// Nil is returned for UTF-8.
+func detectEncoding(b []byte) encoding.Encoding {
+	// Text never contains zero bytes, but every ASCII
+	// character is encoded with one in UTF-16.
+	var even, odd int
+	for i, c := range b {
+		if c == 0 {
+			if i%2 == 0 {
+				even++
+			} else {
+				odd++
+			}
+		}
+	}
+	switch {
+	case odd > len(b)/4:
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	case even > len(b)/4:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	case utf8.Valid(b):
+		return nil
+	case isShiftJIS(b):
+		return japanese.ShiftJIS
+	case isWindows1251(b):
+		return charmap.Windows1251
+	default:
+		return fallbackEncoding
+	}
+}
+
+// isShiftJIS returns true if the data is valid Shift-JIS text which
+// contains full width hiragana or katakana. Japanese text almost
+// always has kana, while in single byte encodings they correspond
+// to rarely used characters.
+func isShiftJIS(b []byte) bool {
+	var kana int
+
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case c < 0x80:
+		case c >= 0xa1 && c <= 0xdf:
+			// Half width katakana.
+		case c >= 0x81 && c <= 0x9f || c >= 0xe0 && c <= 0xfc:
+			if i+1 == len(b) {
+				return false
+			}
+			t := b[i+1]
+			if t < 0x40 || t > 0xfc || t == 0x7f {
+				return false
+			}
+			if c == 0x82 && t >= 0x9f && t <= 0xf1 ||
+				c == 0x83 && t >= 0x40 && t <= 0x96 {
+				kana++
+			}
+			i++
+		default:
+			return false
+		}
+	}
+
+	return kana > 0
+}
+
+// isWindows1251 returns true if the data looks like Cyrillic text
+// in Windows-1251 encoding. Cyrillic words consist of non-ASCII
+// letters only, while Latin words with diacritics in Windows-1252
+// and similar encodings mix them with ASCII ones.
+func isWindows1251(b []byte) bool {
+	var cyrillic, latin int
+	var ascii, other bool
+
+	for i := 0; i <= len(b); i++ {
+		var c byte
+		if i < len(b) {
+			c = b[i]
+		}
+		switch {
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			ascii = true
+		case c >= 0xc0 || c == 0xa8 || c == 0xb8:
+			// Cyrillic letters including Ё and ё.
+			other = true
+		default:
+			// End of the word.
+			if other {
+				if ascii {
+					latin++
+				} else {
+					cyrillic++
+				}
+			}
+			ascii, other = false, false
+		}
+	}
+
+	return cyrillic > latin
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package cue
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func encode(t *testing.T, e encoding.Encoding, s string) []byte {
+	b, err := e.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		t.Fatalf("Failed to encode %s. %s", s, err)
+	}
+
+	return b
+}
+
+func TestDecode(t *testing.T) {
+	const ru = "TITLE \"Кино - Группа крови\""
+	const ja = "TITLE \"さくらんぼ 大塚愛\""
+	const de = "PERFORMER \"Motörhead\"\nTITLE \"Ace of Spades\""
+
+	utf16le := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+	utf16be := unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	tests := []struct {
+		data []byte
+		s    string
+	}{
+		{[]byte(ru), ru},
+		{append([]byte{0xef, 0xbb, 0xbf}, ru...), ru},
+		{encode(t, utf16le, ja), ja},
+		{encode(t, utf16be, de), de},
+		{encode(t, charmap.Windows1251, ru), ru},
+		{encode(t, japanese.ShiftJIS, ja), ja},
+		{encode(t, charmap.Windows1252, de), de},
+	}
+	for _, test := range tests {
+		if s := decode(test.data); s != test.s {
+			t.Fatalf("Expected %q but %q got.", test.s, s)
+		}
+	}
+}
+
+func TestFallbackEncoding(t *testing.T) {
+	defer SetFallbackEncoding("windows-1252")
+
+	const cs = "TITLE \"Dvořák\""
+	err := SetFallbackEncoding("windows-1250")
+	if err != nil {
+		t.Fatalf("Failed to set encoding. %s", err)
+	}
+	if s := decode(encode(t, charmap.Windows1250, cs)); s != cs {
+		t.Fatalf("Expected %q but %q got.", cs, s)
+	}
+	if SetFallbackEncoding("no-such-encoding") == nil {
+		t.Fatalf("Error expected.")
+	}
+}
+
+func TestParseWindows1251(t *testing.T) {
+	data := "PERFORMER \"Кино\"\nTITLE \"Звезда по имени Солнце\"\n" +
+		"FILE \"Кино.flac\" WAVE\n  TRACK 01 AUDIO\n" +
+		"    TITLE \"Песня без слов\"\n    INDEX 01 00:00:00\n"
+
+	sheet, err := Parse(strings.NewReader(
+		string(encode(t, charmap.Windows1251, data))))
+	if err != nil {
+		t.Fatalf("Failed to parse sheet. %s", err)
+	}
+	if sheet.Performer != "Кино" {
+		t.Fatalf("Expected performer %s but %s got.",
+			"Кино", sheet.Performer)
+	}
+	if sheet.Files[0].Name != "Кино.flac" {
+		t.Fatalf("Expected file name %s but %s got.",
+			"Кино.flac", sheet.Files[0].Name)
+	}
+	if sheet.Files[0].Tracks[0].Title != "Песня без слов" {
+		t.Fatalf("Expected track title %s but %s got.",
+			"Песня без слов", sheet.Files[0].Tracks[0].Title)
+	}
+}
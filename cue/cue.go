@@ -71,10 +71,15 @@ func ParseFile(filename string) (sheet *Sheet, err error) {
 }
 
 // Parse parses cue-sheet data from reader and returns filled Sheet struct.
+// Data is converted to UTF-8 from the encoding it is detected to be in.
 func Parse(reader io.Reader) (sheet *Sheet, err error) {
 	sheet = &Sheet{}
 
-	rd := bufio.NewReader(reader)
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	rd := bufio.NewReader(strings.NewReader(decode(data)))
 	lineNumber := 1
 
 	for buf, _, err := rd.ReadLine(); err != io.EOF; buf, _, err = rd.ReadLine() {
@@ -18,6 +18,8 @@
 // The code is based on MOC (Music On Console) written by Damian Pietras,
 // which in turn is based on libxmms-flac by Josh Coalson.
 
+#include <errno.h>
+#include <stdlib.h>
 #include <sys/stat.h>
 #include <stdio.h>
 #include <string.h>
@@ -33,7 +35,7 @@ static FLAC__StreamDecoderReadStatus read_callback(
     *size = fread(buf, 1, *size, file);
     if (ferror(file) != 0) {
         status = FLAC__STREAM_DECODER_READ_STATUS_ABORT;
-    } else if (feof(file) != 0) {
+    } else if (*size == 0 && feof(file) != 0) {
         status = FLAC__STREAM_DECODER_READ_STATUS_END_OF_STREAM;
     } else {
         status = FLAC__STREAM_DECODER_READ_STATUS_CONTINUE;
@@ -48,7 +50,7 @@ static FLAC__StreamDecoderSeekStatus seek_callback(
 {
     FILE *file = ((struct flac_decoder *) data)->file;
 
-    if (fseek(file, offset, SEEK_SET) == 0) {
+    if (fseeko(file, offset, SEEK_SET) == 0) {
         return FLAC__STREAM_DECODER_SEEK_STATUS_OK;
     } else {
         return FLAC__STREAM_DECODER_SEEK_STATUS_ERROR;
@@ -61,12 +63,13 @@ static FLAC__StreamDecoderTellStatus tell_callback(
 {
     FILE *file = ((struct flac_decoder *) data)->file;
 
-    *offset = ftell(file);
-    if (*offset == -1) {
+    off_t o = ftello(file);
+    if (o == -1) {
         return FLAC__STREAM_DECODER_TELL_STATUS_ERROR;
-    } else {
-        return FLAC__STREAM_DECODER_TELL_STATUS_OK;
     }
+    *offset = o;
+
+    return FLAC__STREAM_DECODER_TELL_STATUS_OK;
 }
 
 static FLAC__StreamDecoderLengthStatus length_callback(
@@ -98,14 +101,25 @@ static FLAC__StreamDecoderWriteStatus write_callback(
     const FLAC__int32 *const buf[], void *data)
 {
     struct flac_decoder *decoder = (struct flac_decoder *) data;
-    const unsigned wide_samples = frame->header.blocksize;
+    unsigned int frames = frame->header.blocksize;
+    unsigned int channels = decoder->channels;
 
-    if (decoder->abort) {
+    if (decoder->abort || frames > decoder->block_cap
+            || frame->header.channels != channels) {
+        decoder->error = "unexpected block format";
         return FLAC__STREAM_DECODER_WRITE_STATUS_ABORT;
     }
 
-    decoder->buf_fill = pack_pcm_signed(decoder->buf, buf, wide_samples,
-        decoder->channels, decoder->bits_per_sample);
+    // Interleave channels and make samples 32-bit left-justified.
+    unsigned int shift = 32 - decoder->bits_per_sample;
+    for (unsigned int c = 0; c < channels; c++) {
+        for (unsigned int i = 0; i < frames; i++) {
+            decoder->block[i * channels + c] =
+                (FLAC__int32) ((FLAC__uint32) buf[c][i] << shift);
+        }
+    }
+    decoder->block_len = frames;
+    decoder->block_pos = 0;
 
     return FLAC__STREAM_DECODER_WRITE_STATUS_CONTINUE;
 }
@@ -118,25 +132,18 @@ static void metadata_callback(const FLAC__StreamDecoder *fsd,
     if (metadata->type == FLAC__METADATA_TYPE_STREAMINFO) {
         const FLAC__StreamMetadata_StreamInfo *si = &(metadata->data.stream_info);
 
-        decoder->total_samples = (unsigned int) (si->total_samples & 0xffffffff);
+        decoder->total_samples = si->total_samples;
         decoder->bits_per_sample = si->bits_per_sample;
         decoder->channels = si->channels;
         decoder->sample_rate = si->sample_rate;
-        decoder->len = decoder->total_samples / decoder->sample_rate;
-
-        switch (si->bits_per_sample / 8) {
-        case 1:
-            decoder->format = SFMT_S8;
-            break;
-        case 2:
-            decoder->format = SFMT_S16_LE;
-            break;
-        case 3:
-            decoder->format = SFMT_S32_LE;
-            break;
-        default:
-            decoder->format = SFMT_UNKNOWN;
-            break;
+        decoder->block_cap = si->max_blocksize;
+
+        if (si->bits_per_sample <= 16) {
+            decoder->format = FLAC_SAMPLE_FMT_S16;
+        } else if (si->bits_per_sample <= 24) {
+            decoder->format = FLAC_SAMPLE_FMT_S24;
+        } else {
+            decoder->format = FLAC_SAMPLE_FMT_S32;
         }
     }
 }
@@ -148,90 +155,61 @@ static void error_callback(const FLAC__StreamDecoder *fsd,
 
     if (status != FLAC__STREAM_DECODER_ERROR_STATUS_LOST_SYNC) {
         decoder->abort = 1;
+        decoder->error = FLAC__StreamDecoderErrorStatusString[status];
     }
 }
 
-/* Convert FLAC big-endian data into PCM little-endian. */
-static size_t pack_pcm_signed(FLAC__byte *data,
-    const FLAC__int32 * const input[], unsigned wide_samples,
-    unsigned channels, unsigned bps)
+static int sample_size(enum flac_sample_format format)
 {
-    FLAC__byte * const start = data;
-    FLAC__int32 sample;
-    const FLAC__int32 *input_;
-    unsigned samples, channel;
-    unsigned bytes_per_sample;
-    unsigned incr;
-
-    if (bps == 24) {
-        bps = 32; /* we encode to 32-bit words */
-    }
-    bytes_per_sample = bps / 8;
-    incr = bytes_per_sample * channels;
-
-    for (channel = 0; channel < channels; channel++) {
-        samples = wide_samples;
-        data = start + bytes_per_sample * channel;
-        input_ = input[channel];
-
-        while(samples--) {
-            sample = *input_++;
-
-            switch(bps) {
-            case 8:
-                data[0] = sample;
-                break;
-            case 16:
-                data[1] = (FLAC__byte)(sample >> 8);
-                data[0] = (FLAC__byte)sample;
-                break;
-            case 32:
-                data[3] = (FLAC__byte)(sample >> 16);
-                data[2] = (FLAC__byte)(sample >> 8);
-                data[1] = (FLAC__byte)sample;
-                data[0] = 0;
-                break;
-            }
+    return format == FLAC_SAMPLE_FMT_S16 ? 2 : 4;
+}
 
-            data += incr;
+// Convert left-justified samples into the output format.
+static void pack(char *buf, const FLAC__int32 *samples, unsigned int n,
+    enum flac_sample_format format)
+{
+    switch (format) {
+    case FLAC_SAMPLE_FMT_S16:
+        for (unsigned int i = 0; i < n; i++) {
+            ((int16_t *) buf)[i] = samples[i] >> 16;
+        }
+        break;
+    case FLAC_SAMPLE_FMT_S24:
+        for (unsigned int i = 0; i < n; i++) {
+            ((int32_t *) buf)[i] = samples[i] >> 8;
+        }
+        break;
+    case FLAC_SAMPLE_FMT_S32:
+        memcpy(buf, samples, n * sizeof(FLAC__int32));
+        break;
+    case FLAC_SAMPLE_FMT_F32:
+        for (unsigned int i = 0; i < n; i++) {
+            ((float *) buf)[i] = samples[i] / 2147483648.0f;
         }
+        break;
     }
-
-    return wide_samples * channels * bytes_per_sample;
 }
 
-struct flac_decoder *flac_open(const char *file)
+// Open the file and read stream parameters. Returns NULL and sets
+// err to error code if failed.
+struct flac_decoder *flac_open(const char *file, int *err)
 {
-    struct flac_decoder *decoder = malloc(sizeof(struct flac_decoder));
+    struct flac_decoder *decoder = calloc(1, sizeof(struct flac_decoder));
 
     if (decoder == NULL) {
+        *err = FLAC_ERR_NOMEM;
         return NULL;
     }
 
-    decoder->file = NULL;
-    decoder->fsd = NULL;
-    decoder->abort = 0;
-    decoder->len = -1;
-    decoder->total_samples = 0;
-    decoder->buf_fill = 0;
-    decoder->channels = 0;
-    decoder->format = SFMT_UNKNOWN;
-    decoder->bits_per_sample = 0;
-    decoder->sample_rate = 0;
-    decoder->bitrate = 0;
-    decoder->avg_bitrate = 0;
-    decoder->last_decode_position = 0;
-    decoder->decoded_bytes = 0;
-    decoder->time_offset = 0;
-    decoder->time = 0;
-
     decoder->file = fopen(file, "r");
     if (decoder->file == NULL) {
+        *err = -errno;
         flac_close(decoder);
         return NULL;
     }
     decoder->fsd = FLAC__stream_decoder_new();
     if (decoder->fsd == NULL) {
+        *err = FLAC_ERR_NOMEM;
         flac_close(decoder);
         return NULL;
     }
@@ -252,15 +230,25 @@ struct flac_decoder *flac_open(const char *file)
         error_callback,
         decoder);
     if (st != FLAC__STREAM_DECODER_INIT_STATUS_OK) {
+        *err = FLAC_ERR_INIT;
         flac_close(decoder);
         return NULL;
     }
-    if (!FLAC__stream_decoder_process_until_end_of_metadata(decoder->fsd)) {
+    if (!FLAC__stream_decoder_process_until_end_of_metadata(decoder->fsd)
+            || decoder->abort || decoder->sample_rate == 0
+            || decoder->channels == 0) {
+        *err = FLAC_ERR_METADATA;
         flac_close(decoder);
         return NULL;
     }
 
-    decoder->avg_bitrate = (decoder->bits_per_sample) * decoder->sample_rate;
+    decoder->block = malloc(sizeof(FLAC__int32) * decoder->block_cap
+        * decoder->channels);
+    if (decoder->block == NULL) {
+        *err = FLAC_ERR_NOMEM;
+        flac_close(decoder);
+        return NULL;
+    }
 
     return decoder;
 }
@@ -274,91 +262,203 @@ void flac_close(struct flac_decoder *decoder)
     if (decoder->file) {
         fclose(decoder->file);
     }
+    free(decoder->block);
     free(decoder);
 }
 
+// Decode next portion of data into buf. Returns number of bytes
+// written, 0 at the end of the stream or negative error code.
 int flac_decode(struct flac_decoder *decoder, char *buf, int len)
 {
     FLAC__StreamDecoder *fsd = decoder->fsd;
-    int bytes_per_sample = decoder->bits_per_sample / 8;
 
-    if (decoder->buf_fill == 0) {
-        FLAC__uint64 decode_position;
-
-        if (FLAC__stream_decoder_get_state(fsd) == FLAC__STREAM_DECODER_END_OF_STREAM) {
-            return 0;
-        }
-        if (!FLAC__stream_decoder_process_single(fsd)) {
+    while (decoder->block_pos == decoder->block_len) {
+        if (decoder->eof || FLAC__stream_decoder_get_state(fsd)
+                == FLAC__STREAM_DECODER_END_OF_STREAM) {
             return 0;
         }
-        /* Count the bitrate */
-        if(!FLAC__stream_decoder_get_decode_position(fsd, &decode_position)) {
-            decode_position = 0;
+        if (!FLAC__stream_decoder_process_single(fsd) || decoder->abort) {
+            if (decoder->error == NULL) {
+                decoder->error = FLAC__stream_decoder_get_resolved_state_string(fsd);
+            }
+            return FLAC_ERR_DECODE;
         }
+    }
 
-        int bytes_per_sec = bytes_per_sample * decoder->sample_rate
-            * decoder->channels;
+    int fsz = sample_size(decoder->format) * decoder->channels;
+    unsigned int frames = MIN((unsigned int) len / fsz,
+        decoder->block_len - decoder->block_pos);
+    pack(buf, decoder->block + decoder->block_pos * decoder->channels,
+        frames * decoder->channels, decoder->format);
+    decoder->block_pos += frames;
+    decoder->pos += frames;
 
-        if (decode_position > decoder->last_decode_position) {
-            decoder->bitrate = (decode_position - decoder->last_decode_position) * 8.0
-                / (decoder->buf_fill / (float) bytes_per_sec) / 1000;
-        }
+    return frames * fsz;
+}
 
-        decoder->last_decode_position = decode_position;
+// Set decoding position in milliseconds.
+int flac_seek(struct flac_decoder *decoder, int pos)
+{
+    if (pos < 0) {
+        return FLAC_ERR_SEEK;
     }
 
-    unsigned int to_copy = MIN((unsigned) len, decoder->buf_fill);
-    memcpy(buf, decoder->buf, to_copy);
-    memmove(decoder->buf, decoder->buf + to_copy, decoder->buf_fill - to_copy);
-    decoder->buf_fill -= to_copy;
+    FLAC__uint64 sample = (FLAC__uint64) pos * decoder->sample_rate / 1000;
+    decoder->block_len = 0;
+    decoder->block_pos = 0;
+    if (decoder->total_samples > 0 && sample >= decoder->total_samples) {
+        decoder->pos = decoder->total_samples;
+        decoder->eof = 1;
 
-    decoder->decoded_bytes += to_copy;
-    FLAC__uint32 decoded_samples = decoder->decoded_bytes / bytes_per_sample;
-    FLAC__uint32 decoded_time = decoded_samples / decoder->sample_rate
-        / decoder->channels;
-    decoder->time = decoder->time_offset + decoded_time;
+        return 0;
+    }
+    // Decoder calls write callback with the block starting
+    // from the requested sample.
+    if (!FLAC__stream_decoder_seek_absolute(decoder->fsd, sample)) {
+        FLAC__stream_decoder_flush(decoder->fsd);
+        return FLAC_ERR_SEEK;
+    }
+    decoder->pos = sample;
+    decoder->eof = 0;
 
-    return to_copy;
+    return 0;
 }
 
-int flac_seek(struct flac_decoder *decoder, int pos, int rel)
+// Return current decoding position in milliseconds.
+int flac_time(struct flac_decoder *decoder)
 {
-    if (rel) {
-        pos = decoder->time + pos;
-    }
+    return decoder->pos * 1000 / decoder->sample_rate;
+}
+
+int flac_sample_rate(struct flac_decoder *decoder)
+{
+    return decoder->sample_rate;
+}
+
+int flac_channels(struct flac_decoder *decoder)
+{
+    return decoder->channels;
+}
 
-    if (pos > decoder->len || pos < 0) {
+int flac_sample_format(struct flac_decoder *decoder)
+{
+    return decoder->format;
+}
+
+int flac_set_sample_format(struct flac_decoder *decoder, int format)
+{
+    switch (format) {
+    case FLAC_SAMPLE_FMT_S16:
+    case FLAC_SAMPLE_FMT_S24:
+    case FLAC_SAMPLE_FMT_S32:
+    case FLAC_SAMPLE_FMT_F32:
+        decoder->format = format;
+        return 0;
+    default:
         return -1;
     }
+}
 
-    FLAC__uint64 pos_samples = decoder->sample_rate * pos;
+// Return description of the last decoding error.
+const char *flac_error(struct flac_decoder *decoder)
+{
+    return decoder->error ? decoder->error : "unknown error";
+}
 
-    if (FLAC__stream_decoder_seek_absolute(decoder->fsd, pos_samples)) {
-        decoder->decoded_bytes = 0;
-        decoder->time_offset = pos;
-        decoder->time = pos;
+// Read stream length in milliseconds and Vorbis comments of the file.
+// tags is set to NULL if file has no comments, otherwise it must be
+// freed with FLAC__metadata_object_delete().
+int flac_metadata(const char *file, struct flac_info *info,
+    FLAC__StreamMetadata **tags)
+{
+    FLAC__StreamMetadata si;
 
-        return pos;
-    } else {
-        return -1;
+    if (!FLAC__metadata_get_streaminfo(file, &si)) {
+        return FLAC_ERR_METADATA;
+    }
+    const FLAC__StreamMetadata_StreamInfo *stream = &si.data.stream_info;
+    info->length = 0;
+    if (stream->sample_rate > 0) {
+        info->length = stream->total_samples * 1000 / stream->sample_rate;
+    }
+    info->sample_rate = stream->sample_rate;
+    info->channels = stream->channels;
+    info->bits_per_sample = stream->bits_per_sample;
+    if (!FLAC__metadata_get_tags(file, tags)) {
+        *tags = NULL;
     }
+
+    return 0;
 }
 
-int flac_time(struct flac_decoder *decoder)
+int flac_comments_len(const FLAC__StreamMetadata *tags)
 {
-    return decoder->time;
+    return tags->data.vorbis_comment.num_comments;
 }
 
-int flac_sample_rate(struct flac_decoder *decoder)
+// Return i-th comment in NAME=value form. Comment is not
+// zero-terminated, its length is stored in len.
+const char *flac_comment(const FLAC__StreamMetadata *tags, int i, int *len)
 {
-    return decoder->sample_rate;
+    const FLAC__StreamMetadata_VorbisComment_Entry *e =
+        &tags->data.vorbis_comment.comments[i];
+    *len = e->length;
+
+    return (const char *) e->entry;
 }
-int flac_channels(struct flac_decoder *decoder)
+
+// Find front cover picture or any picture if there is no front cover.
+// *picture is set to NULL if file has no pictures, otherwise it must
+// be freed with FLAC__metadata_object_delete().
+void flac_picture(const char *file, FLAC__StreamMetadata **picture)
 {
-    return decoder->channels;
+    if (!FLAC__metadata_get_picture(file, picture,
+            FLAC__STREAM_METADATA_PICTURE_TYPE_FRONT_COVER,
+            NULL, NULL, -1, -1, -1, -1)
+        && !FLAC__metadata_get_picture(file, picture,
+            (FLAC__StreamMetadata_Picture_Type) -1,
+            NULL, NULL, -1, -1, -1, -1)) {
+        *picture = NULL;
+    }
+}
+
+// Return picture's image data. Its length is stored in len.
+const FLAC__byte *flac_picture_data(const FLAC__StreamMetadata *picture,
+    int *len)
+{
+    *len = picture->data.picture.data_length;
+
+    return picture->data.picture.data;
+}
+
+// Read CUESHEET metadata block. *cuesheet is set to NULL if file
+// has no one, otherwise it must be freed with
+// FLAC__metadata_object_delete().
+void flac_cuesheet(const char *file, FLAC__StreamMetadata **cuesheet)
+{
+    if (!FLAC__metadata_get_cuesheet(file, cuesheet)) {
+        *cuesheet = NULL;
+    }
+}
+
+// Return number of tracks in the cue sheet including the lead-out one.
+int flac_cuesheet_len(const FLAC__StreamMetadata *cuesheet)
+{
+    return cuesheet->data.cue_sheet.num_tracks;
 }
 
-int flac_length(struct flac_decoder *decoder)
+// Return i-th track start (INDEX 01) in samples or -1
+// if the track has no such index.
+long long flac_cuesheet_start(const FLAC__StreamMetadata *cuesheet, int i)
 {
-    return decoder->len;
+    const FLAC__StreamMetadata_CueSheet_Track *t =
+        &cuesheet->data.cue_sheet.tracks[i];
+
+    for (unsigned int j = 0; j < t->num_indices; j++) {
+        if (t->indices[j].number == 1) {
+            return t->offset + t->indices[j].offset;
+        }
+    }
+
+    return -1;
 }
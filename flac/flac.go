@@ -0,0 +1,243 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build flac
+
+// FLAC audio format implemented with libFLAC.
+package flac
+
+// #cgo LDFLAGS: -lFLAC
+// #include <stdlib.h>
+// #include "flac.h"
+import "C"
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/format/vorbis"
+)
+
+type decoder struct {
+	d *C.struct_flac_decoder
+}
+
+func newDecoder(path string) (*decoder, error) {
+	p := C.CString(path)
+	defer C.free(unsafe.Pointer(p))
+
+	var e C.int
+	d := C.flac_open(p, &e)
+	if d == nil {
+		return nil, newError(int(e))
+	}
+
+	return &decoder{d: d}, nil
+}
+
+func (d *decoder) Read(buf []byte) (int, error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	bp := (*C.char)(unsafe.Pointer(&buf[0]))
+	n := int(C.flac_decode(d.d, bp, C.int(len(buf))))
+	if n < 0 {
+		return 0, errors.New(C.GoString(C.flac_error(d.d)))
+	}
+
+	return n, nil
+}
+
+// Seek sets new position in milliseconds.
+func (d *decoder) Seek(pos int) error {
+	if pos < 0 {
+		return errors.New("invalid seek position")
+	}
+	e := C.flac_seek(d.d, C.int(pos))
+	if e < 0 {
+		return newError(int(e))
+	}
+
+	return nil
+}
+
+// Time returns current position in milliseconds.
+func (d *decoder) Time() int {
+	return int(C.flac_time(d.d))
+}
+
+func (d *decoder) SampleRate() int {
+	return int(C.flac_sample_rate(d.d))
+}
+
+func (d *decoder) Channels() int {
+	return int(C.flac_channels(d.d))
+}
+
+func (d *decoder) SampleFormat() format.SampleFormat {
+	return format.SampleFormat(C.flac_sample_format(d.d))
+}
+
+func (d *decoder) SetSampleFormat(f format.SampleFormat) error {
+	if C.flac_set_sample_format(d.d, C.int(f)) != 0 {
+		return errors.New("unsupported sample format")
+	}
+
+	return nil
+}
+
+func (d *decoder) Close() error {
+	C.flac_close(d.d)
+
+	return nil
+}
+
+type flac struct {
+}
+
+func NewFormat() format.Format {
+	return &flac{}
+}
+
+func (f flac) Extensions() []string {
+	return []string{"flac"}
+}
+
+func (f flac) Metadata(path string) (format.Metadata, error) {
+	p := C.CString(path)
+	defer C.free(unsafe.Pointer(p))
+
+	var info C.struct_flac_info
+	var tags *C.FLAC__StreamMetadata
+	e := C.flac_metadata(p, &info, &tags)
+	if e < 0 {
+		return nil, newError(int(e))
+	}
+	si := format.StreamInfo{
+		Length:     int(info.length),
+		Codec:      "flac",
+		BitDepth:   int(info.bits_per_sample),
+		SampleRate: int(info.sample_rate),
+		Channels:   int(info.channels),
+	}
+	// Average bitrate of the whole file. Tags and embedded pictures
+	// are counted too, so it is slightly overestimated.
+	if fi, err := os.Stat(path); err == nil && si.Length > 0 {
+		si.Bitrate = int(fi.Size() * 8 * 1000 / int64(si.Length))
+	}
+	md := &format.Tags{}
+	md.SetInfo(si)
+	if tags != nil {
+		defer C.FLAC__metadata_object_delete(tags)
+
+		n := int(C.flac_comments_len(tags))
+		comments := make([]string, 0, n)
+		for i := 0; i < n; i++ {
+			var l C.int
+			c := C.flac_comment(tags, C.int(i), &l)
+			comments = append(comments, C.GoStringN(c, l))
+		}
+		vorbis.ParseComments(comments, md)
+	}
+	if md.CueSheet() == "" && si.SampleRate > 0 {
+		md.Set(format.TagCueSheet, nativeCueSheet(p, path, si.SampleRate))
+	}
+
+	return md, nil
+}
+
+// nativeCueSheet converts CUESHEET metadata block into the CUE sheet
+// text. Empty string is returned if there is no cue sheet.
+func nativeCueSheet(cpath *C.char, path string, rate int) string {
+	var cs *C.FLAC__StreamMetadata
+	C.flac_cuesheet(cpath, &cs)
+	if cs == nil {
+		return ""
+	}
+	defer C.FLAC__metadata_object_delete(cs)
+
+	var starts []int
+	// The last one is the lead-out track.
+	for i := 0; i < int(C.flac_cuesheet_len(cs))-1; i++ {
+		s := int64(C.flac_cuesheet_start(cs, C.int(i)))
+		if s >= 0 {
+			starts = append(starts, int(s*1000/int64(rate)))
+		}
+	}
+	if len(starts) == 0 {
+		return ""
+	}
+
+	return format.CueSheet(path, starts)
+}
+
+func (f flac) Picture(path string) ([]byte, error) {
+	p := C.CString(path)
+	defer C.free(unsafe.Pointer(p))
+
+	// libFLAC does not distinguish missing pictures from I/O errors.
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	var pic *C.FLAC__StreamMetadata
+	C.flac_picture(p, &pic)
+	if pic == nil {
+		return nil, nil
+	}
+	defer C.FLAC__metadata_object_delete(pic)
+
+	var l C.int
+	data := C.flac_picture_data(pic, &l)
+
+	return C.GoBytes(unsafe.Pointer(data), l), nil
+}
+
+func (f flac) Lyrics(path string) (string, error) {
+	md, err := f.Metadata(path)
+	if err != nil {
+		return "", err
+	}
+
+	return md.(*format.Tags).Lyrics(), nil
+}
+
+func (f flac) Decoder(path string) (format.Decoder, error) {
+	return newDecoder(path)
+}
+
+func newError(e int) error {
+	switch e {
+	case C.FLAC_ERR_NOMEM:
+		return errors.New("out of memory")
+	case C.FLAC_ERR_INIT:
+		return errors.New("failed to initialize decoder")
+	case C.FLAC_ERR_METADATA:
+		return format.ErrNotSupported
+	case C.FLAC_ERR_DECODE:
+		return errors.New("decoding failed")
+	case C.FLAC_ERR_SEEK:
+		return errors.New("seek failed")
+	default:
+		if e < 0 {
+			return syscall.Errno(-e)
+		}
+		return errors.New("unknown error")
+	}
+}
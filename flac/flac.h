@@ -20,55 +20,79 @@
 
 #include <FLAC/all.h>
 
-#define MAX_SUPPORTED_CHANNELS 2
-#define SAMPLES_PER_WRITE 512
-#define SAMPLE_BUF_SIZE ((FLAC__MAX_BLOCK_SIZE + SAMPLES_PER_WRITE) * \
-        MAX_SUPPORTED_CHANNELS * (32 / 8))
+#define MIN(a, b) ((a) < (b) ? (a) : (b))
 
-#define SFMT_UNKNOWN 0
-#define SFMT_S8 1
-#define SFMT_S16_LE 2
-#define SFMT_S32_LE 4
+// Error codes. Failed system calls return negative errno.
+#define FLAC_ERR_NOMEM (-10001)
+#define FLAC_ERR_INIT (-10002)
+#define FLAC_ERR_METADATA (-10003)
+#define FLAC_ERR_DECODE (-10004)
+#define FLAC_ERR_SEEK (-10005)
 
-#define MIN(a, b) ((a) < (b) ? (a) : (b))
+// Sample formats supported by Chub. Must be kept in sync
+// with format.SampleFormat constants.
+enum flac_sample_format {
+    FLAC_SAMPLE_FMT_S16 = 0,
+    FLAC_SAMPLE_FMT_S24,
+    FLAC_SAMPLE_FMT_S32,
+    FLAC_SAMPLE_FMT_F32,
+};
+
+// Stream properties returned by flac_metadata().
+struct flac_info {
+    // Length in milliseconds.
+    int length;
+    int sample_rate;
+    int channels;
+    int bits_per_sample;
+};
 
 struct flac_decoder {
     FILE *file;
     FLAC__StreamDecoder *fsd;
+    // Set by error callback to abort decoding.
     int abort;
-    int len;
-    unsigned int total_samples;
-    FLAC__byte buf[SAMPLE_BUF_SIZE];
-    unsigned int buf_fill;
+    // Description of the last decoding error.
+    const char *error;
+    FLAC__uint64 total_samples;
     unsigned int channels;
-    unsigned int format;
     unsigned int bits_per_sample;
     unsigned int sample_rate;
-    int bitrate;
-    int avg_bitrate;
-    /* Last decoded sample number. */
-    FLAC__uint64 last_decode_position;
-    /* Total bytes decoded since beginning of the decoding process or */
-    /* from last seek request if any. */
-    FLAC__uint32 decoded_bytes;
-    /* Second decoding started from. Initialy decoding starting from 0 second */
-    /* but after seek call offset is set to seek seconds offset argument. */
-    FLAC__uint32 time_offset;
-    /* Current decoding time in seconds. */
-    FLAC__uint32 time;
+    // Output sample format.
+    enum flac_sample_format format;
+    // Interleaved samples of the last decoded block.
+    FLAC__int32 *block;
+    // Number of frames block can store.
+    unsigned int block_cap;
+    // Number of frames in the block.
+    unsigned int block_len;
+    // Number of frames already returned from the block.
+    unsigned int block_pos;
+    // Number of the next frame to be returned by flac_decode().
+    FLAC__uint64 pos;
+    // End of the stream reached by seek.
+    int eof;
 };
 
-static size_t pack_pcm_signed(FLAC__byte *data,
-    const FLAC__int32 * const input[], unsigned wide_samples,
-    unsigned channels, unsigned bps);
-
-struct flac_decoder *flac_open(const char *file);
+struct flac_decoder *flac_open(const char *file, int *err);
 void flac_close(struct flac_decoder *decoder);
 int flac_decode(struct flac_decoder *decoder, char *buf, int len);
-int flac_seek(struct flac_decoder *decoder, int pos, int rel);
+int flac_seek(struct flac_decoder *decoder, int pos);
 int flac_time(struct flac_decoder *decoder);
 int flac_sample_rate(struct flac_decoder *decoder);
 int flac_channels(struct flac_decoder *decoder);
-int flac_length(struct flac_decoder *decoder);
+int flac_sample_format(struct flac_decoder *decoder);
+int flac_set_sample_format(struct flac_decoder *decoder, int format);
+const char *flac_error(struct flac_decoder *decoder);
+int flac_metadata(const char *file, struct flac_info *info,
+    FLAC__StreamMetadata **tags);
+int flac_comments_len(const FLAC__StreamMetadata *tags);
+const char *flac_comment(const FLAC__StreamMetadata *tags, int i, int *len);
+void flac_picture(const char *file, FLAC__StreamMetadata **picture);
+const FLAC__byte *flac_picture_data(const FLAC__StreamMetadata *picture,
+    int *len);
+void flac_cuesheet(const char *file, FLAC__StreamMetadata **cuesheet);
+int flac_cuesheet_len(const FLAC__StreamMetadata *cuesheet);
+long long flac_cuesheet_start(const FLAC__StreamMetadata *cuesheet, int i);
 
 #endif // FLAC_H
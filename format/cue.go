@@ -0,0 +1,43 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package format
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// CueSheet returns text of the CUE sheet which splits the file
+// into tracks starting at the given positions in milliseconds.
+// It is used for formats which keep track offsets in binary form,
+// e.g. FLAC CUESHEET metadata block.
+func CueSheet(file string, starts []int) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "FILE %q WAVE\n", filepath.Base(file))
+	for i, s := range starts {
+		// CUE time is in MM:SS:FF format, where FF is 1/75 of a second.
+		f := s * 75 / 1000
+		fmt.Fprintf(&b, "  TRACK %02d AUDIO\n", i+1)
+		fmt.Fprintf(&b, "    INDEX 01 %02d:%02d:%02d\n",
+			f/75/60, f/75%60, f%75)
+	}
+
+	return b.String()
+}
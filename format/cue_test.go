@@ -0,0 +1,36 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package format
+
+import (
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+)
+
+func TestCueSheet(t *testing.T) {
+	s := CueSheet("/music/album.flac", []int{0, 61500, 6000000})
+	assert.True(t, s == `FILE "album.flac" WAVE
+  TRACK 01 AUDIO
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    INDEX 01 01:01:37
+  TRACK 03 AUDIO
+    INDEX 01 100:00:00
+`)
+}
@@ -0,0 +1,375 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+#include <sys/param.h>
+#include <libavutil/opt.h>
+#include <libavutil/audio_fifo.h>
+#include <libavcodec/avcodec.h>
+#include <libavformat/avformat.h>
+#include <libswresample/swresample.h>
+#include "encoder.h"
+
+// Size of the buffer used by muxer to write its output.
+#define FFMPEG_ENCODER_IO_SIZE 4096
+// Frame size used for codecs which accept frames of any size.
+#define FFMPEG_ENCODER_FRAME_SIZE 1024
+
+
+// AVIO write callback. Appends muxed data to the encoder's output buffer.
+static int ffmpeg_encoder_io_write(void *opaque, uint8_t *buf, int len)
+{
+    struct ffmpeg_encoder *enc = opaque;
+
+    if (enc->out_len + len > enc->out_cap) {
+        int cap = MAX(enc->out_cap * 2, enc->out_len + len);
+        uint8_t *p = realloc(enc->out, cap);
+        if (!p) {
+            return AVERROR(ENOMEM);
+        }
+        enc->out = p;
+        enc->out_cap = cap;
+    }
+    memcpy(enc->out + enc->out_len, buf, len);
+    enc->out_len += len;
+
+    return len;
+}
+
+// Return sample rate supported by the codec which is the closest
+// to the requested one.
+static int ffmpeg_encoder_sample_rate(const AVCodec *codec, int rate)
+{
+    const int *r = codec->supported_samplerates;
+    if (!r) {
+        return rate;
+    }
+
+    int best = r[0];
+    for (; *r; r++) {
+        if (*r == rate) {
+            return rate;
+        }
+        if (abs(*r - rate) < abs(best - rate)) {
+            best = *r;
+        }
+    }
+
+    return best;
+}
+
+// Send frame to the encoder and mux all the packets it produced.
+// NULL frame flushes the encoder.
+static int ffmpeg_encoder_send(struct ffmpeg_encoder *enc, AVFrame *frame)
+{
+    int err = avcodec_send_frame(enc->codec, frame);
+    if (err < 0) {
+        return err;
+    }
+
+    for (;;) {
+        err = avcodec_receive_packet(enc->codec, enc->pkt);
+        if (err == AVERROR(EAGAIN) || err == AVERROR_EOF) {
+            return 0;
+        }
+        if (err < 0) {
+            return err;
+        }
+        av_packet_rescale_ts(enc->pkt, enc->codec->time_base,
+                enc->stream->time_base);
+        enc->pkt->stream_index = enc->stream->index;
+        err = av_write_frame(enc->format, enc->pkt);
+        av_packet_unref(enc->pkt);
+        if (err < 0) {
+            return err;
+        }
+    }
+}
+
+// Encode all complete frames waiting in the FIFO. If flush is set
+// the last incomplete frame is padded with silence and encoded too.
+static int ffmpeg_encoder_encode_fifo(struct ffmpeg_encoder *enc, int flush)
+{
+    AVFrame *frame = enc->frame;
+
+    for (;;) {
+        int size = av_audio_fifo_size(enc->fifo);
+        if (size == 0 || (size < frame->nb_samples && !flush)) {
+            break;
+        }
+
+        int err = av_frame_make_writable(frame);
+        if (err < 0) {
+            return err;
+        }
+        int n = av_audio_fifo_read(enc->fifo, (void **) frame->data,
+                frame->nb_samples);
+        if (n < 0) {
+            return n;
+        }
+        if (n < frame->nb_samples) {
+            av_samples_set_silence(frame->data, n, frame->nb_samples - n,
+                    enc->codec->channels, enc->codec->sample_fmt);
+        }
+        frame->pts = enc->pts;
+        enc->pts += frame->nb_samples;
+
+        err = ffmpeg_encoder_send(enc, frame);
+        if (err < 0) {
+            return err;
+        }
+    }
+    if (flush) {
+        int err = ffmpeg_encoder_send(enc, NULL);
+        if (err < 0) {
+            return err;
+        }
+    }
+    avio_flush(enc->format->pb);
+
+    return 0;
+}
+
+// Allocate and initialize encoder structure.
+// ffmpeg_encoder_free() must be called to free allocated memory.
+struct ffmpeg_encoder *ffmpeg_encoder_alloc()
+{
+    struct ffmpeg_encoder *enc = malloc(sizeof(struct ffmpeg_encoder));
+    memset(enc, 0, sizeof(struct ffmpeg_encoder));
+
+    return enc;
+}
+
+// Free structure allocated by ffmpeg_encoder_alloc().
+void ffmpeg_encoder_free(struct ffmpeg_encoder *enc)
+{
+    free(enc->out);
+    free(enc);
+}
+
+// Open encoder for the stream with given parameters. Encoded data
+// is muxed into the container specified by muxer. Stream header is
+// available in the output buffer after the call.
+int ffmpeg_encoder_open(struct ffmpeg_encoder *enc, const char *codec_name,
+        const char *muxer, int bitrate, int sample_rate, int channels,
+        int sample_fmt)
+{
+    const AVCodec *codec = avcodec_find_encoder_by_name(codec_name);
+    if (!codec) {
+        return AVERROR_ENCODER_NOT_FOUND;
+    }
+    int err = avformat_alloc_output_context2(&enc->format, NULL,
+            muxer, NULL);
+    if (err < 0) {
+        return err;
+    }
+    enc->codec = avcodec_alloc_context3(codec);
+    if (!enc->codec) {
+        return AVERROR(ENOMEM);
+    }
+
+    // Streaming codecs are used for stereo mostly, multichannel
+    // streams are downmixed.
+    int out_channels = MIN(channels, 2);
+    enc->codec->channels = out_channels;
+    enc->codec->channel_layout = av_get_default_channel_layout(out_channels);
+    enc->codec->sample_rate = ffmpeg_encoder_sample_rate(codec, sample_rate);
+    enc->codec->sample_fmt = codec->sample_fmts
+        ? codec->sample_fmts[0] : AV_SAMPLE_FMT_FLTP;
+    enc->codec->bit_rate = bitrate;
+    enc->codec->time_base = av_make_q(1, enc->codec->sample_rate);
+    if (enc->format->oformat->flags & AVFMT_GLOBALHEADER) {
+        enc->codec->flags |= AV_CODEC_FLAG_GLOBAL_HEADER;
+    }
+    err = avcodec_open2(enc->codec, codec, NULL);
+    if (err < 0) {
+        return err;
+    }
+
+    enc->stream = avformat_new_stream(enc->format, NULL);
+    if (!enc->stream) {
+        return AVERROR(ENOMEM);
+    }
+    err = avcodec_parameters_from_context(enc->stream->codecpar, enc->codec);
+    if (err < 0) {
+        return err;
+    }
+    enc->stream->time_base = enc->codec->time_base;
+
+    uint8_t *iobuf = av_malloc(FFMPEG_ENCODER_IO_SIZE);
+    if (!iobuf) {
+        return AVERROR(ENOMEM);
+    }
+    enc->format->pb = avio_alloc_context(iobuf, FFMPEG_ENCODER_IO_SIZE,
+            1, enc, NULL, ffmpeg_encoder_io_write, NULL);
+    if (!enc->format->pb) {
+        av_free(iobuf);
+        return AVERROR(ENOMEM);
+    }
+    enc->format->flags |= AVFMT_FLAG_CUSTOM_IO;
+
+    enc->swr = swr_alloc();
+    if (!enc->swr) {
+        return AVERROR(ENOMEM);
+    }
+    enum AVSampleFormat in_fmt;
+    switch (sample_fmt) {
+    case FFMPEG_SAMPLE_FMT_S24:
+    case FFMPEG_SAMPLE_FMT_S32:
+        in_fmt = AV_SAMPLE_FMT_S32;
+        break;
+    case FFMPEG_SAMPLE_FMT_F32:
+        in_fmt = AV_SAMPLE_FMT_FLT;
+        break;
+    default:
+        in_fmt = AV_SAMPLE_FMT_S16;
+        break;
+    }
+    av_opt_set_int(enc->swr, "in_channel_count", channels, 0);
+    av_opt_set_int(enc->swr, "out_channel_count", out_channels, 0);
+    av_opt_set_int(enc->swr, "in_channel_layout",
+            av_get_default_channel_layout(channels), 0);
+    av_opt_set_int(enc->swr, "out_channel_layout",
+            enc->codec->channel_layout, 0);
+    av_opt_set_int(enc->swr, "in_sample_rate", sample_rate, 0);
+    av_opt_set_int(enc->swr, "out_sample_rate", enc->codec->sample_rate, 0);
+    av_opt_set_sample_fmt(enc->swr, "in_sample_fmt", in_fmt, 0);
+    av_opt_set_sample_fmt(enc->swr, "out_sample_fmt",
+            enc->codec->sample_fmt, 0);
+    err = swr_init(enc->swr);
+    if (err < 0) {
+        return err;
+    }
+
+    enc->fifo = av_audio_fifo_alloc(enc->codec->sample_fmt, out_channels, 1);
+    enc->pkt = av_packet_alloc();
+    enc->frame = av_frame_alloc();
+    if (!enc->fifo || !enc->pkt || !enc->frame) {
+        return AVERROR(ENOMEM);
+    }
+    enc->frame->nb_samples = enc->codec->frame_size;
+    if (!enc->frame->nb_samples
+        || (codec->capabilities & AV_CODEC_CAP_VARIABLE_FRAME_SIZE)) {
+        enc->frame->nb_samples = FFMPEG_ENCODER_FRAME_SIZE;
+    }
+    enc->frame->format = enc->codec->sample_fmt;
+    enc->frame->channels = out_channels;
+    enc->frame->channel_layout = enc->codec->channel_layout;
+    enc->frame->sample_rate = enc->codec->sample_rate;
+    err = av_frame_get_buffer(enc->frame, 0);
+    if (err < 0) {
+        return err;
+    }
+
+    enc->channels = channels;
+    enc->in_fmt = sample_fmt;
+    enc->pts = 0;
+
+    err = avformat_write_header(enc->format, NULL);
+    if (err < 0) {
+        return err;
+    }
+    avio_flush(enc->format->pb);
+
+    return 0;
+}
+
+// Encode len bytes of interleaved PCM data. Encoded data is appended
+// to the output buffer. Returns 0 if success or negative number
+// in case of error.
+int ffmpeg_encoder_write(struct ffmpeg_encoder *enc, const char *buf, int len)
+{
+    int sample_size = enc->in_fmt == FFMPEG_SAMPLE_FMT_S16 ? 2 : 4;
+    int nsamples = len / (sample_size * enc->channels);
+    const uint8_t *in = (const uint8_t *) buf;
+    int32_t *scaled = NULL;
+
+    if (enc->in_fmt == FFMPEG_SAMPLE_FMT_S24) {
+        // Move 24-bit samples to the MSB-aligned 32-bit ones.
+        const int32_t *p = (const int32_t *) buf;
+        scaled = malloc(nsamples * enc->channels * sizeof(int32_t));
+        if (!scaled) {
+            return AVERROR(ENOMEM);
+        }
+        for (int i = 0; i < nsamples * enc->channels; i++) {
+            scaled[i] = (int32_t) ((uint32_t) p[i] << 8);
+        }
+        in = (const uint8_t *) scaled;
+    }
+
+    int out_nsamples = swr_get_out_samples(enc->swr, nsamples);
+    uint8_t **out = NULL;
+    int err = av_samples_alloc_array_and_samples(&out, NULL,
+            enc->codec->channels, out_nsamples, enc->codec->sample_fmt, 0);
+    if (err < 0) {
+        free(scaled);
+        return err;
+    }
+    int n = swr_convert(enc->swr, out, out_nsamples, &in, nsamples);
+    free(scaled);
+    if (n > 0) {
+        n = av_audio_fifo_write(enc->fifo, (void **) out, n);
+    }
+    av_freep(&out[0]);
+    av_freep(&out);
+    if (n < 0) {
+        return n;
+    }
+
+    return ffmpeg_encoder_encode_fifo(enc, 0);
+}
+
+// Encode all buffered data and finish the stream.
+int ffmpeg_encoder_finish(struct ffmpeg_encoder *enc)
+{
+    int err = ffmpeg_encoder_encode_fifo(enc, 1);
+    if (err < 0) {
+        return err;
+    }
+    err = av_write_trailer(enc->format);
+    avio_flush(enc->format->pb);
+
+    return err;
+}
+
+// Release resources allocated by ffmpeg_encoder_open().
+void ffmpeg_encoder_close(struct ffmpeg_encoder *enc)
+{
+    if (enc->fifo) {
+        av_audio_fifo_free(enc->fifo);
+        enc->fifo = NULL;
+    }
+    if (enc->swr) {
+        swr_free(&enc->swr);
+    }
+    if (enc->pkt) {
+        av_packet_free(&enc->pkt);
+    }
+    if (enc->frame) {
+        av_frame_free(&enc->frame);
+    }
+    if (enc->codec) {
+        avcodec_free_context(&enc->codec);
+    }
+    if (enc->format) {
+        if (enc->format->pb) {
+            av_freep(&enc->format->pb->buffer);
+            avio_context_free(&enc->format->pb);
+        }
+        avformat_free_context(enc->format);
+        enc->format = NULL;
+    }
+}
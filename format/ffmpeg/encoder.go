@@ -0,0 +1,138 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package ffmpeg
+
+// #include "encoder.h"
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/vchimishuk/chub/format"
+)
+
+type encoderInfo struct {
+	// FFmpeg encoder name.
+	codec string
+	// FFmpeg muxer name.
+	muxer string
+	// MIME type of the muxed stream.
+	contentType string
+}
+
+// Supported encoders by short codec name.
+var encoders = map[string]encoderInfo{
+	"mp3":    {"libmp3lame", "mp3", "audio/mpeg"},
+	"opus":   {"libopus", "ogg", "audio/ogg"},
+	"vorbis": {"libvorbis", "ogg", "audio/ogg"},
+}
+
+// Encoders returns MIME types of the streams produced by supported
+// encoders by short codec name.
+func Encoders() map[string]string {
+	m := make(map[string]string, len(encoders))
+	for name, info := range encoders {
+		m[name] = info.contentType
+	}
+
+	return m
+}
+
+// Encoder encodes PCM stream and muxes it into the container suitable
+// for streaming (Ogg for Vorbis and Opus, raw stream for MP3).
+type Encoder struct {
+	enc    *C.struct_ffmpeg_encoder
+	header []byte
+}
+
+// NewEncoder returns encoder for PCM stream with given parameters.
+// bitrate is in bits per second.
+func NewEncoder(codec string, bitrate int, rate int, channels int,
+	sfmt format.SampleFormat) (*Encoder, error) {
+
+	info, ok := encoders[codec]
+	if !ok {
+		return nil, fmt.Errorf("unsupported codec: %s", codec)
+	}
+	c := C.CString(info.codec)
+	defer C.free(unsafe.Pointer(c))
+	m := C.CString(info.muxer)
+	defer C.free(unsafe.Pointer(m))
+
+	enc := C.ffmpeg_encoder_alloc()
+	err := C.ffmpeg_encoder_open(enc, c, m, C.int(bitrate), C.int(rate),
+		C.int(channels), C.int(sfmt))
+	if err < 0 {
+		C.ffmpeg_encoder_close(enc)
+		C.ffmpeg_encoder_free(enc)
+
+		return nil, newError(int(err))
+	}
+	e := &Encoder{enc: enc}
+	e.header = e.take()
+
+	return e, nil
+}
+
+// Header returns stream header. It has to be sent to the stream
+// consumers before any other data.
+func (e *Encoder) Header() []byte {
+	return e.header
+}
+
+// Write encodes PCM data and returns muxed stream data ready to be sent.
+// Encoder buffers data internally, so returned data can be empty.
+func (e *Encoder) Write(buf []byte) ([]byte, error) {
+	if len(buf) == 0 {
+		return nil, nil
+	}
+	p := (*C.char)(unsafe.Pointer(&buf[0]))
+	err := C.ffmpeg_encoder_write(e.enc, p, C.int(len(buf)))
+	if err < 0 {
+		return nil, newError(int(err))
+	}
+
+	return e.take(), nil
+}
+
+// Close finishes the stream and releases encoder resources.
+// Returns the rest of muxed stream data.
+func (e *Encoder) Close() ([]byte, error) {
+	err := C.ffmpeg_encoder_finish(e.enc)
+	data := e.take()
+	C.ffmpeg_encoder_close(e.enc)
+	C.ffmpeg_encoder_free(e.enc)
+	e.enc = nil
+	if err < 0 {
+		return data, newError(int(err))
+	}
+
+	return data, nil
+}
+
+// take returns muxed data accumulated by the encoder.
+func (e *Encoder) take() []byte {
+	if e.enc.out_len == 0 {
+		return nil
+	}
+	data := C.GoBytes(unsafe.Pointer(e.enc.out), e.enc.out_len)
+	e.enc.out_len = 0
+
+	return data
+}
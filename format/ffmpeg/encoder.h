@@ -0,0 +1,59 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+#ifndef CHUB_FFMPEG_ENCODER_H
+#define CHUB_FFMPEG_ENCODER_H
+
+#include <libavformat/avformat.h>
+#include <libavutil/audio_fifo.h>
+#include <libswresample/swresample.h>
+#include <libavcodec/avcodec.h>
+#include "ffmpeg.h"
+
+
+struct ffmpeg_encoder {
+    AVFormatContext *format;
+    AVStream *stream;
+    AVCodecContext *codec;
+    AVPacket *pkt;
+    AVFrame *frame;
+    SwrContext *swr;
+    // Converted samples waiting to be encoded. Codecs accept
+    // frames of fixed size only.
+    AVAudioFifo *fifo;
+    // Input stream parameters.
+    int channels;
+    enum ffmpeg_sample_format in_fmt;
+    // Presentation time of the next frame.
+    int64_t pts;
+    // Muxed data not taken by the client yet.
+    uint8_t *out;
+    int out_len;
+    int out_cap;
+};
+
+struct ffmpeg_encoder *ffmpeg_encoder_alloc();
+void ffmpeg_encoder_free(struct ffmpeg_encoder *enc);
+int ffmpeg_encoder_open(struct ffmpeg_encoder *enc, const char *codec,
+        const char *muxer, int bitrate, int sample_rate, int channels,
+        int sample_fmt);
+int ffmpeg_encoder_write(struct ffmpeg_encoder *enc, const char *buf,
+        int len);
+int ffmpeg_encoder_finish(struct ffmpeg_encoder *enc);
+void ffmpeg_encoder_close(struct ffmpeg_encoder *enc);
+
+#endif // CHUB_FFMPEG_ENCODER_H
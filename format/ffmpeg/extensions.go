@@ -0,0 +1,80 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package ffmpeg
+
+import (
+	"slices"
+	"strings"
+)
+
+// Audio file extensions of FFmpeg demuxers. Demuxers report their
+// extensions themselves, but the lists are incomplete (e.g. ogg
+// and wav have none) and contain video formats, so the mapping
+// is kept here.
+var demuxerExts = map[string][]string{
+	"aac":      {"aac"},
+	"ac3":      {"ac3"},
+	"aiff":     {"aif", "aifc", "aiff"},
+	"amr":      {"amr"},
+	"ape":      {"ape"},
+	"asf":      {"wma"},
+	"au":       {"au", "snd"},
+	"caf":      {"caf"},
+	"dsf":      {"dsf"},
+	"dts":      {"dts"},
+	"eac3":     {"eac3"},
+	"flac":     {"flac"},
+	"iff":      {"dff"},
+	"matroska": {"mka"},
+	"mov":      {"m4a", "m4b"},
+	"mp3":      {"mp3"},
+	"mpc":      {"mpc"},
+	"mpc8":     {"mpc"},
+	"ogg":      {"oga", "ogg", "opus", "spx"},
+	"shn":      {"shn"},
+	"tak":      {"tak"},
+	"tta":      {"tta"},
+	"w64":      {"w64"},
+	"wav":      {"wav"},
+	"wv":       {"wv"},
+}
+
+// extensions returns sorted list of extensions of audio files given
+// demuxers can read with add extensions included and remove excluded.
+func extensions(demuxers []string, add []string, remove []string) []string {
+	var exts []string
+	for _, d := range demuxers {
+		exts = append(exts, demuxerExts[d]...)
+	}
+	for _, e := range add {
+		exts = append(exts, normExt(e))
+	}
+	exts = slices.DeleteFunc(exts, func(e string) bool {
+		return slices.ContainsFunc(remove, func(r string) bool {
+			return normExt(r) == e
+		})
+	})
+	slices.Sort(exts)
+
+	return slices.Compact(exts)
+}
+
+// normExt returns lower case extension without leading dot.
+func normExt(e string) string {
+	return strings.ToLower(strings.TrimPrefix(e, "."))
+}
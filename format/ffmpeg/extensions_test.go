@@ -0,0 +1,37 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package ffmpeg
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+)
+
+func TestExtensions(t *testing.T) {
+	exts := extensions([]string{"mov", "mp4", "ogg", "h264", "mpc",
+		"mpc8", "shn"}, nil, nil)
+	assert.True(t, slices.Equal(exts, []string{"m4a", "m4b", "mpc",
+		"oga", "ogg", "opus", "shn", "spx"}))
+
+	exts = extensions([]string{"flac", "ogg"}, []string{"XM", ".mod"},
+		[]string{"spx", ".OPUS"})
+	assert.True(t, slices.Equal(exts,
+		[]string{"flac", "mod", "oga", "ogg", "xm"}))
+}
@@ -37,6 +37,91 @@ static int ffmpeg_time_ms(int64_t time, AVRational base)
     return av_rescale_q(time, base, av_make_q(1, 1000));
 }
 
+// Return FFmpeg sample format used to produce samples in Chub's format.
+static enum AVSampleFormat ffmpeg_av_sample_fmt(enum ffmpeg_sample_format fmt)
+{
+    switch (fmt) {
+    case FFMPEG_SAMPLE_FMT_S24:
+    case FFMPEG_SAMPLE_FMT_S32:
+        return AV_SAMPLE_FMT_S32;
+    case FFMPEG_SAMPLE_FMT_F32:
+        return AV_SAMPLE_FMT_FLT;
+    default:
+        return AV_SAMPLE_FMT_S16;
+    }
+}
+
+// Return Chub's sample format which is closest to the codec's native one.
+static enum ffmpeg_sample_format ffmpeg_native_sample_fmt(AVCodecContext *codec)
+{
+    switch (av_get_packed_sample_fmt(codec->sample_fmt)) {
+    case AV_SAMPLE_FMT_U8:
+    case AV_SAMPLE_FMT_S16:
+        return FFMPEG_SAMPLE_FMT_S16;
+    case AV_SAMPLE_FMT_S32:
+    case AV_SAMPLE_FMT_S64:
+        if (codec->bits_per_raw_sample > 16
+            && codec->bits_per_raw_sample <= 24) {
+            return FFMPEG_SAMPLE_FMT_S24;
+        }
+        return FFMPEG_SAMPLE_FMT_S32;
+    case AV_SAMPLE_FMT_FLT:
+    case AV_SAMPLE_FMT_DBL:
+        return FFMPEG_SAMPLE_FMT_F32;
+    default:
+        return FFMPEG_SAMPLE_FMT_S16;
+    }
+}
+
+// (Re)initialize resampler for the current output parameters.
+static int ffmpeg_init_swr(struct ffmpeg_file *file)
+{
+    if (file->swr) {
+        swr_free(&file->swr);
+    }
+    file->swr = swr_alloc();
+    if (!file->swr) {
+        return -1;
+    }
+    // Channels are passed as is, so input and output layouts are
+    // the same. Channels mapping is done by the player.
+    int64_t layout = file->codec->channel_layout;
+    if (!layout) {
+        layout = av_get_default_channel_layout(file->codec->channels);
+    }
+    av_opt_set_int(file->swr, "in_channel_count",
+            file->codec->channels, 0);
+    av_opt_set_int(file->swr, "out_channel_count",
+            file->channels, 0);
+    av_opt_set_int(file->swr, "in_channel_layout",
+            layout, 0);
+    av_opt_set_int(file->swr, "out_channel_layout",
+            layout, 0);
+    av_opt_set_int(file->swr, "in_sample_rate",
+            file->codec->sample_rate, 0);
+    av_opt_set_int(file->swr, "out_sample_rate",
+            file->sample_rate, 0);
+    av_opt_set_sample_fmt(file->swr, "in_sample_fmt",
+            file->codec->sample_fmt, 0);
+    av_opt_set_sample_fmt(file->swr, "out_sample_fmt",
+            file->sample_fmt,  0);
+    swr_init(file->swr);
+    if (!swr_is_initialized(file->swr)) {
+        return -1;
+    }
+
+    // Buffer has to be reallocated for the new sample size.
+    if (file->buf) {
+        av_freep(&file->buf[0]);
+        av_freep(&file->buf);
+    }
+    file->buf_nsamples = 0;
+    file->buf_len = 0;
+    file->buf_offset = 0;
+
+    return 0;
+}
+
 // Send next packet for decoding.
 // Returns 0 if success or negative number in case of error.
 static int ffmpeg_send_packet(struct ffmpeg_file *file)
@@ -109,6 +194,18 @@ static int ffmpeg_decode_frame(struct ffmpeg_file *file)
 
     int ns = swr_convert(file->swr, file->buf, dst_nsamples,
         (const uint8_t**) frame->data, frame->nb_samples);
+    if (ns < 0) {
+        return ns;
+    }
+    if (file->out_fmt == FFMPEG_SAMPLE_FMT_S24) {
+        // FFmpeg has no 24-bit format, so 24-bit samples are
+        // decoded as MSB-aligned 32-bit ones. Move them to the low
+        // three bytes of the word.
+        int32_t *p = (int32_t *) file->buf[0];
+        for (int i = 0; i < ns * file->channels; i++) {
+            p[i] >>= 8;
+        }
+    }
     int nb = av_samples_get_buffer_size(NULL, file->channels,
             ns, file->sample_fmt, 1);
     if (nb < 0) {
@@ -136,6 +233,33 @@ char *ffmpeg_strerror(int err)
     return buf;
 }
 
+// Return comma separated names of all compiled in demuxers.
+// It is client's responsibility to free memory allocated by string.
+char *ffmpeg_demuxers()
+{
+    const AVInputFormat *f;
+    void *opaque = NULL;
+    size_t len = 1;
+
+    while ((f = av_demuxer_iterate(&opaque))) {
+        len += strlen(f->name) + 1;
+    }
+    char *buf = malloc(len);
+    if (!buf) {
+        return NULL;
+    }
+
+    char *p = buf;
+    opaque = NULL;
+    while ((f = av_demuxer_iterate(&opaque))) {
+        p = stpcpy(p, f->name);
+        *p++ = ',';
+    }
+    *p = '\0';
+
+    return buf;
+}
+
 void ffmpeg_init()
 {
     av_log_set_level(AV_LOG_ERROR);
@@ -143,18 +267,22 @@ void ffmpeg_init()
 
 void ffmpeg_metadata_free(struct ffmpeg_metadata *md)
 {
-    if (md->artist) {
-        free(md->artist);
-    }
-    if (md->album) {
-        free(md->album);
-    }
-    if (md->title) {
-        free(md->title);
-    }
-    if (md->number) {
-        free(md->number);
-    }
+    free(md->artist);
+    free(md->album_artist);
+    free(md->album);
+    free(md->date);
+    free(md->title);
+    free(md->number);
+    free(md->tracks);
+    free(md->disc);
+    free(md->discs);
+    free(md->genre);
+    free(md->composer);
+    free(md->comment);
+    free(md->codec);
+    free(md->cuesheet);
+    free(md->lyrics);
+    free(md->chapters);
     free(md);
 }
 
@@ -204,6 +332,7 @@ int ffmpeg_open(struct ffmpeg_file *file, const char *filename)
 
     file->channels = 2;
     file->sample_rate = 44100;
+    file->out_fmt = FFMPEG_SAMPLE_FMT_S16;
     file->sample_fmt = AV_SAMPLE_FMT_S16;
 
     return 0;
@@ -237,32 +366,141 @@ void ffmpeg_close(struct ffmpeg_file *file)
     }
 }
 
+// Set tag value if it is not set yet.
+static void ffmpeg_set_tag(char **tag, const char *value)
+{
+    if (!*tag && *value) {
+        *tag = strdup(value);
+    }
+}
+
+// Fill metadata tags missing in md from the dictionary.
+static void ffmpeg_read_tags(struct ffmpeg_metadata *md, AVDictionary *m)
+{
+    AVDictionaryEntry *tag = NULL;
+    while ((tag = av_dict_get(m, "", tag, AV_DICT_IGNORE_SUFFIX))) {
+        const char *k = tag->key;
+        const char *v = tag->value;
+
+        if (strcasecmp(k, "artist") == 0) {
+            ffmpeg_set_tag(&md->artist, v);
+        } else if (strcasecmp(k, "album_artist") == 0
+            || strcasecmp(k, "albumartist") == 0
+            || strcasecmp(k, "album artist") == 0) {
+            ffmpeg_set_tag(&md->album_artist, v);
+        } else if (strcasecmp(k, "album") == 0) {
+            ffmpeg_set_tag(&md->album, v);
+        } else if (strcasecmp(k, "date") == 0
+            || strcasecmp(k, "year") == 0) {
+            ffmpeg_set_tag(&md->date, v);
+        } else if (strcasecmp(k, "title") == 0) {
+            ffmpeg_set_tag(&md->title, v);
+        } else if (strcasecmp(k, "track") == 0
+            || strcasecmp(k, "tracknumber") == 0) {
+            ffmpeg_set_tag(&md->number, v);
+        } else if (strcasecmp(k, "tracktotal") == 0
+            || strcasecmp(k, "totaltracks") == 0) {
+            ffmpeg_set_tag(&md->tracks, v);
+        } else if (strcasecmp(k, "disc") == 0
+            || strcasecmp(k, "discnumber") == 0) {
+            ffmpeg_set_tag(&md->disc, v);
+        } else if (strcasecmp(k, "disctotal") == 0
+            || strcasecmp(k, "totaldiscs") == 0) {
+            ffmpeg_set_tag(&md->discs, v);
+        } else if (strcasecmp(k, "genre") == 0) {
+            ffmpeg_set_tag(&md->genre, v);
+        } else if (strcasecmp(k, "composer") == 0) {
+            ffmpeg_set_tag(&md->composer, v);
+        } else if (strcasecmp(k, "comment") == 0
+            || strcasecmp(k, "description") == 0) {
+            ffmpeg_set_tag(&md->comment, v);
+        } else if (strcasecmp(k, "cuesheet") == 0) {
+            ffmpeg_set_tag(&md->cuesheet, v);
+        } else if (strcasecmp(k, "lyrics") == 0
+            || strcasecmp(k, "unsyncedlyrics") == 0
+            // ID3 USLT frames are stored with language suffix.
+            || strncasecmp(k, "lyrics-", 7) == 0) {
+            ffmpeg_set_tag(&md->lyrics, v);
+        }
+    }
+}
+
 struct ffmpeg_metadata *ffmpeg_metadata(struct ffmpeg_file *file)
 {
     AVStream *s = file->format->streams[file->stream];
     struct ffmpeg_metadata *md = zmalloc(sizeof(struct ffmpeg_metadata));
+    AVCodecParameters *par = s->codecpar;
     md->duration = ffmpeg_time_ms(s->duration, s->time_base);
-
-    AVDictionary *m = file->format->metadata;
-    AVDictionaryEntry *tag = NULL;
-    while ((tag = av_dict_get(m, "", tag, AV_DICT_IGNORE_SUFFIX))) {
-        if (strcasecmp(tag->key, "artist") == 0) {
-            md->artist = strdup(tag->value);
-        } else if (strcasecmp(tag->key, "album") == 0) {
-            md->album = strdup(tag->value);
-        } else if (strcasecmp(tag->key, "year") == 0
-            || strcasecmp(tag->key, "date") == 0) {
-            md->year = atoi(tag->value);
-        } else if (strcasecmp(tag->key, "title") == 0) {
-            md->title = strdup(tag->value);
-        } else if (strcasecmp(tag->key, "track") == 0) {
-            md->number = strdup(tag->value);
+    md->codec = strdup(avcodec_get_name(par->codec_id));
+    md->bitrate = par->bit_rate;
+    if (md->bitrate == 0) {
+        md->bitrate = file->format->bit_rate;
+    }
+    // Lossy codecs have no bit depth.
+    md->bit_depth = par->bits_per_raw_sample;
+    if (md->bit_depth == 0 && av_get_exact_bits_per_sample(par->codec_id) > 0) {
+        md->bit_depth = par->bits_per_coded_sample;
+    }
+    md->sample_rate = par->sample_rate;
+    md->channels = par->channels;
+
+    // FLAC demuxer exposes CUESHEET metadata block as chapters.
+    if (strcmp(file->format->iformat->name, "flac") == 0
+        && file->format->nb_chapters > 0) {
+        md->chapters = malloc(file->format->nb_chapters * sizeof(int));
+        for (unsigned int i = 0; md->chapters != NULL
+                 && i < file->format->nb_chapters; i++) {
+            AVChapter *c = file->format->chapters[i];
+            int start = ffmpeg_time_ms(c->start, c->time_base);
+            // Skip lead-out track.
+            if (md->duration <= 0 || start < md->duration) {
+                md->chapters[md->nchapters++] = start;
+            }
         }
     }
 
+    // Some containers (e.g. Ogg) keep tags in the stream.
+    ffmpeg_read_tags(md, file->format->metadata);
+    ffmpeg_read_tags(md, s->metadata);
+
     return md;
 }
 
+// Returns copy of the picture attached to the file, e.g. album cover
+// stored in ID3 APIC frame, FLAC PICTURE block or MP4 covr atom.
+// Front cover is preferred. *data is set to NULL if file has
+// no pictures, otherwise it must be freed by caller.
+int ffmpeg_picture(struct ffmpeg_file *file, uint8_t **data, int *size)
+{
+    AVPacket *pic = NULL;
+
+    *data = NULL;
+    *size = 0;
+    for (unsigned int i = 0; i < file->format->nb_streams; i++) {
+        AVStream *s = file->format->streams[i];
+        if (!(s->disposition & AV_DISPOSITION_ATTACHED_PIC)
+            || s->attached_pic.size <= 0) {
+            continue;
+        }
+        AVDictionaryEntry *e = av_dict_get(s->metadata, "comment", NULL, 0);
+        if (pic == NULL || (e != NULL && strcmp(e->value, "Cover (front)") == 0)) {
+            pic = &s->attached_pic;
+        }
+    }
+    if (pic == NULL) {
+        return 0;
+    }
+
+    *data = malloc(pic->size);
+    if (*data == NULL) {
+        return AVERROR(ENOMEM);
+    }
+    memcpy(*data, pic->data, pic->size);
+    *size = pic->size;
+
+    return 0;
+}
+
 int ffmpeg_open_codec(struct ffmpeg_file *file)
 {
     const AVStream *s = file->format->streams[file->stream];
@@ -293,32 +531,12 @@ int ffmpeg_open_codec(struct ffmpeg_file *file)
         return -1;
     }
 
-    file->swr = swr_alloc();
-    if (!file->swr) {
-        return -1;
-    }
-    av_opt_set_int(file->swr, "in_channel_count",
-            file->codec->channels, 0);
-    av_opt_set_int(file->swr, "out_channel_count",
-            file->channels, 0);
-    av_opt_set_int(file->swr, "in_channel_layout",
-            file->codec->channel_layout, 0);
-    av_opt_set_int(file->swr, "out_channel_layout",
-            AV_CH_LAYOUT_STEREO, 0);
-    av_opt_set_int(file->swr, "in_sample_rate",
-            file->codec->sample_rate, 0);
-    av_opt_set_int(file->swr, "out_sample_rate",
-            file->sample_rate, 0);
-    av_opt_set_sample_fmt(file->swr, "in_sample_fmt",
-            file->codec->sample_fmt, 0);
-    av_opt_set_sample_fmt(file->swr, "out_sample_fmt",
-            file->sample_fmt,  0);
-    swr_init(file->swr);
-    if (!swr_is_initialized(file->swr)) {
-        return -1;
-    }
+    file->sample_rate = file->codec->sample_rate;
+    file->channels = file->codec->channels;
+    file->out_fmt = ffmpeg_native_sample_fmt(file->codec);
+    file->sample_fmt = ffmpeg_av_sample_fmt(file->out_fmt);
 
-    return 0;
+    return ffmpeg_init_swr(file);
 }
 
 // Decode next len bytes. Returns number of bytes decoded, zero on
@@ -357,7 +575,7 @@ int ffmpeg_seek(struct ffmpeg_file *file, int pos)
     }
 
     AVStream *s = file->format->streams[file->stream];
-    int64_t delta_pts = av_rescale_q(pos, av_make_q(1, 1), s->time_base);
+    int64_t delta_pts = av_rescale_q(pos, av_make_q(1, 1000), s->time_base);
     int64_t pts = s->start_time + delta_pts;
     int e = av_seek_frame(file->format, file->stream, pts,
             AVSEEK_FLAG_ANY | AVSEEK_FLAG_BACKWARD);
@@ -389,3 +607,21 @@ int ffmpeg_sample_rate(struct ffmpeg_file *file)
 {
     return file->sample_rate;
 }
+
+int ffmpeg_sample_format(struct ffmpeg_file *file)
+{
+    return file->out_fmt;
+}
+
+// Change output sample format. Data already decoded in the old format
+// is discarded.
+int ffmpeg_set_sample_format(struct ffmpeg_file *file, int fmt)
+{
+    if (fmt == file->out_fmt) {
+        return 0;
+    }
+    file->out_fmt = fmt;
+    file->sample_fmt = ffmpeg_av_sample_fmt(fmt);
+
+    return ffmpeg_init_swr(file);
+}
@@ -25,45 +25,12 @@ import "C"
 
 import (
 	"errors"
-	"strconv"
+	"strings"
 	"unsafe"
 
 	"github.com/vchimishuk/chub/format"
 )
 
-type metadata struct {
-	artist string
-	album  string
-	year   int
-	title  string
-	number int
-	length int
-}
-
-func (m *metadata) Artist() string {
-	return m.artist
-}
-
-func (m *metadata) Album() string {
-	return m.album
-}
-
-func (m *metadata) Year() int {
-	return m.year
-}
-
-func (m *metadata) Title() string {
-	return m.title
-}
-
-func (m *metadata) Number() int {
-	return m.number
-}
-
-func (m *metadata) Length() int {
-	return m.length
-}
-
 type decoder struct {
 	file *C.struct_ffmpeg_file
 }
@@ -127,6 +94,19 @@ func (d *decoder) Channels() int {
 	return int(C.ffmpeg_channels(d.file))
 }
 
+func (d *decoder) SampleFormat() format.SampleFormat {
+	return format.SampleFormat(C.ffmpeg_sample_format(d.file))
+}
+
+func (d *decoder) SetSampleFormat(f format.SampleFormat) error {
+	e := C.ffmpeg_set_sample_format(d.file, C.int(f))
+	if e < 0 {
+		return errors.New("failed to set sample format")
+	}
+
+	return nil
+}
+
 func (d *decoder) Close() error {
 	C.ffmpeg_close(d.file)
 	C.ffmpeg_free(d.file)
@@ -136,23 +116,20 @@ func (d *decoder) Close() error {
 }
 
 type ffmpeg struct {
+	exts []string
 }
 
-func NewFormat() format.Format {
+// NewFormat returns format which supports extensions of audio files
+// FFmpeg library has demuxers for. Extensions from add list are
+// supported in addition, and the ones from remove list are not.
+func NewFormat(add []string, remove []string) format.Format {
 	C.ffmpeg_init()
 
-	return &ffmpeg{}
+	return &ffmpeg{exts: extensions(demuxers(), add, remove)}
 }
 
 func (f ffmpeg) Extensions() []string {
-	return []string{
-		"ape",
-		"flac",
-		"m4a",
-		"mp3",
-		"ogg",
-		"wv",
-	}
+	return f.exts
 }
 
 func (f ffmpeg) Metadata(path string) (format.Metadata, error) {
@@ -171,23 +148,101 @@ func (f ffmpeg) Metadata(path string) (format.Metadata, error) {
 	md := C.ffmpeg_metadata(file)
 	defer C.ffmpeg_metadata_free(md)
 
-	n, _ := strconv.Atoi(C.GoString(md.number))
-	m := &metadata{
-		artist: C.GoString(md.artist),
-		album:  C.GoString(md.album),
-		title:  C.GoString(md.title),
-		year:   int(md.year),
-		number: n,
-		length: int(md.duration),
+	m := &format.Tags{}
+	m.SetInfo(format.StreamInfo{
+		Length:     int(md.duration),
+		Codec:      C.GoString(md.codec),
+		Bitrate:    int(md.bitrate),
+		BitDepth:   int(md.bit_depth),
+		SampleRate: int(md.sample_rate),
+		Channels:   int(md.channels),
+	})
+	m.Set(format.TagArtist, C.GoString(md.artist))
+	m.Set(format.TagAlbumArtist, C.GoString(md.album_artist))
+	m.Set(format.TagAlbum, C.GoString(md.album))
+	m.Set(format.TagDate, C.GoString(md.date))
+	m.Set(format.TagTitle, C.GoString(md.title))
+	// Separate totals take precedence over the ones given
+	// along with numbers, e.g. 3/12.
+	m.Set(format.TagTracks, C.GoString(md.tracks))
+	m.Set(format.TagNumber, C.GoString(md.number))
+	m.Set(format.TagDiscs, C.GoString(md.discs))
+	m.Set(format.TagDisc, C.GoString(md.disc))
+	m.Set(format.TagGenre, C.GoString(md.genre))
+	m.Set(format.TagComposer, C.GoString(md.composer))
+	m.Set(format.TagComment, C.GoString(md.comment))
+	m.Set(format.TagCueSheet, C.GoString(md.cuesheet))
+	m.Set(format.TagLyrics, C.GoString(md.lyrics))
+	if m.CueSheet() == "" && md.nchapters > 0 {
+		chs := unsafe.Slice(md.chapters, md.nchapters)
+		starts := make([]int, len(chs))
+		for i, c := range chs {
+			starts[i] = int(c)
+		}
+		m.Set(format.TagCueSheet, format.CueSheet(path, starts))
 	}
 
 	return m, nil
 }
 
+func (f ffmpeg) Picture(path string) ([]byte, error) {
+	p := C.CString(path)
+	defer C.free(unsafe.Pointer(p))
+
+	file := C.ffmpeg_alloc()
+	defer C.ffmpeg_free(file)
+
+	err := C.ffmpeg_open(file, p)
+	if err < 0 {
+		return nil, newError(int(err))
+	}
+	defer C.ffmpeg_close(file)
+
+	var data *C.uint8_t
+	var size C.int
+	err = C.ffmpeg_picture(file, &data, &size)
+	if err < 0 {
+		return nil, newError(int(err))
+	}
+	if data == nil {
+		return nil, nil
+	}
+	defer C.free(unsafe.Pointer(data))
+
+	return C.GoBytes(unsafe.Pointer(data), size), nil
+}
+
+func (f ffmpeg) Lyrics(path string) (string, error) {
+	md, err := f.Metadata(path)
+	if err != nil {
+		return "", err
+	}
+
+	return md.(*format.Tags).Lyrics(), nil
+}
+
 func (f ffmpeg) Decoder(path string) (format.Decoder, error) {
 	return newDecoder(path)
 }
 
+// demuxers returns names of demuxers compiled into FFmpeg library.
+func demuxers() []string {
+	s := C.ffmpeg_demuxers()
+	if s == nil {
+		return nil
+	}
+	defer C.free(unsafe.Pointer(s))
+
+	var names []string
+	for _, n := range strings.Split(C.GoString(s), ",") {
+		if n != "" {
+			names = append(names, n)
+		}
+	}
+
+	return names
+}
+
 func btoi(b bool) int {
 	if b {
 		return 1
@@ -23,13 +23,43 @@
 #include <libavcodec/avcodec.h>
 
 
+// Sample formats supported by Chub. Must be kept in sync
+// with format.SampleFormat constants.
+enum ffmpeg_sample_format {
+    FFMPEG_SAMPLE_FMT_S16 = 0,
+    FFMPEG_SAMPLE_FMT_S24,
+    FFMPEG_SAMPLE_FMT_S32,
+    FFMPEG_SAMPLE_FMT_F32,
+};
+
 struct ffmpeg_metadata {
     char *artist;
+    char *album_artist;
     char *album;
-    int year;
+    char *date;
     char *title;
+    // Track number, optionally followed by total tracks, e.g. "3/12".
     char *number;
+    char *tracks;
+    // Disc number, optionally followed by total discs, e.g. "1/2".
+    char *disc;
+    char *discs;
+    char *genre;
+    char *composer;
+    char *comment;
     int duration;
+    // Technical properties of the audio stream.
+    char *codec;
+    int bitrate;
+    int bit_depth;
+    int sample_rate;
+    int channels;
+    // Embedded CUE sheet text.
+    char *cuesheet;
+    char *lyrics;
+    // Chapters start positions in milliseconds.
+    int *chapters;
+    int nchapters;
 };
 
 struct ffmpeg_file {
@@ -41,6 +71,9 @@ struct ffmpeg_file {
     SwrContext *swr;
     int channels;
     int sample_rate;
+    // Output sample format as seen by the client.
+    enum ffmpeg_sample_format out_fmt;
+    // FFmpeg sample format used for resampling into out_fmt.
     enum AVSampleFormat sample_fmt;
     // Current decoding time position.
     int64_t time;
@@ -59,11 +92,13 @@ struct ffmpeg_file {
 
 void ffmpeg_init();
 char *ffmpeg_strerror(int err);
+char *ffmpeg_demuxers();
 struct ffmpeg_file *ffmpeg_alloc();
 void ffmpeg_free(struct ffmpeg_file *file);
 int ffmpeg_open(struct ffmpeg_file *file, const char *filename);
 void ffmpeg_close(struct ffmpeg_file *file);
 struct ffmpeg_metadata *ffmpeg_metadata(struct ffmpeg_file *file);
+int ffmpeg_picture(struct ffmpeg_file *file, uint8_t **data, int *size);
 int ffmpeg_open_codec(struct ffmpeg_file *file);
 int ffmpeg_read(struct ffmpeg_file *file, char *buf, int len);
 int ffmpeg_seek(struct ffmpeg_file *file, int pos);
@@ -71,5 +106,7 @@ void ffmpeg_metadata_free(struct ffmpeg_metadata *metadata);
 int ffmpeg_time(struct ffmpeg_file *file);
 int ffmpeg_channels(struct ffmpeg_file *file);
 int ffmpeg_sample_rate(struct ffmpeg_file *file);
+int ffmpeg_sample_format(struct ffmpeg_file *file);
+int ffmpeg_set_sample_format(struct ffmpeg_file *file, int fmt);
 
 #endif // CHUB_FFMPEG_H
@@ -20,26 +20,128 @@ package format
 
 import (
 	"errors"
-	"path"
-	"strings"
 )
 
 var ErrNotSupported = errors.New("not supported audio format")
 
+// SampleFormat describes representation of a single PCM sample.
+// All formats use native byte order.
+type SampleFormat int
+
+const (
+	// Signed 16 bit.
+	SampleFormatS16 SampleFormat = iota
+	// Signed 24 bit stored in the low three bytes of 32-bit word.
+	SampleFormatS24
+	// Signed 32 bit.
+	SampleFormatS32
+	// Float 32 bit, range -1.0 to 1.0.
+	SampleFormatF32
+)
+
+// Size returns one sample size in bytes.
+func (f SampleFormat) Size() int {
+	switch f {
+	case SampleFormatS16:
+		return 2
+	case SampleFormatS24, SampleFormatS32, SampleFormatF32:
+		return 4
+	default:
+		panic("unsupported sample format")
+	}
+}
+
+// Bits returns number of significant bits in the sample.
+func (f SampleFormat) Bits() int {
+	switch f {
+	case SampleFormatS16:
+		return 16
+	case SampleFormatS24:
+		return 24
+	case SampleFormatS32, SampleFormatF32:
+		return 32
+	default:
+		panic("unsupported sample format")
+	}
+}
+
+func (f SampleFormat) String() string {
+	switch f {
+	case SampleFormatS16:
+		return "s16"
+	case SampleFormatS24:
+		return "s24"
+	case SampleFormatS32:
+		return "s32"
+	case SampleFormatF32:
+		return "f32"
+	default:
+		panic("unsupported sample format")
+	}
+}
+
+// ParseSampleFormat returns sample format by its name, e.g. s16.
+func ParseSampleFormat(s string) (SampleFormat, error) {
+	for _, f := range []SampleFormat{SampleFormatS16, SampleFormatS24,
+		SampleFormatS32, SampleFormatF32} {
+
+		if f.String() == s {
+			return f, nil
+		}
+	}
+
+	return 0, errors.New("unsupported sample format")
+}
+
+// Metadata describes audio file's tags. Missing string
+// values are empty and numeric ones are zero.
 type Metadata interface {
 	Artist() string
+	// AlbumArtist returns artist of the whole album,
+	// e.g. "Various Artists" for compilations.
+	AlbumArtist() string
 	Album() string
+	// Year returns year of the release date.
 	Year() int
+	// Date returns release date as it is stored in tags,
+	// e.g. "1999" or "1999-12-31".
+	Date() string
 	Title() string
+	// Number returns track number.
 	Number() int
+	// Tracks returns total number of tracks.
+	Tracks() int
+	// Disc returns disc number.
+	Disc() int
+	// Discs returns total number of discs.
+	Discs() int
+	Genre() string
+	Composer() string
+	Comment() string
+	// Length returns track length in milliseconds.
 	Length() int
+	// Codec returns short lower-case codec name, e.g. "flac" or "mp3".
+	Codec() string
+	// Bitrate returns average stream bitrate in bits per second
+	// or 0 if it is unknown.
+	Bitrate() int
+	// BitDepth returns number of bits per sample of the source stream
+	// or 0 for lossy codecs which do not have one.
+	BitDepth() int
+	// SampleRate returns sample rate of the source stream.
+	SampleRate() int
+	// Channels returns number of channels in the source stream.
+	Channels() int
+	// CueSheet returns text of the CUE sheet embedded into the file,
+	// or empty string if there is no one.
+	CueSheet() string
 }
 
 // Decoder interface represents audio decoder for the particular audio format.
 type Decoder interface {
 	// Read decode piece of data and returns raw PCM audio data.
 	Read(buf []byte) (read int, err error)
-	// Seek sets new position in seconds to start decoding from.
+	// Seek sets new position in milliseconds to start decoding from.
 	Seek(pos int) error
 	// Time returns current decoded position in milliseconds.
 	Time() int
@@ -47,6 +149,13 @@ type Decoder interface {
 	SampleRate() int
 	// Channels returns number of channels in decoded stream.
 	Channels() int
+	// SampleFormat returns sample format of decoded stream. Right after
+	// decoder is created it is the format closest to the native one
+	// of the audio file.
+	SampleFormat() SampleFormat
+	// SetSampleFormat asks decoder to convert decoded stream
+	// to the given sample format.
+	SetSampleFormat(f SampleFormat) error
 	// Close releases decoder resources.
 	Close() error
 }
@@ -57,39 +166,19 @@ type Format interface {
 	Decoder(path string) (Decoder, error)
 }
 
-var formats map[string]Format = make(map[string]Format)
-
-func Register(f Format) {
-	for _, ext := range f.Extensions() {
-		formats[ext] = f
-	}
-}
-
-func GetMetadata(path string) (Metadata, error) {
-	f, ok := formats[ext(path)]
-	if !ok {
-		return nil, ErrNotSupported
-	}
-
-	return f.Metadata(path)
-}
-
-func GetDecoder(path string) (Decoder, error) {
-	for _, f := range formats {
-		d, err := f.Decoder(path)
-		if err == nil {
-			return d, err
-		}
-	}
-
-	return nil, ErrNotSupported
+// PictureReader is implemented by formats which can extract pictures
+// embedded into audio files, e.g. album covers.
+type PictureReader interface {
+	// Picture returns raw image data of the front cover, or of the
+	// first picture found if there is no front cover. Nil is returned
+	// if the file has no pictures.
+	Picture(path string) ([]byte, error)
 }
 
-func ext(p string) string {
-	ext := strings.ToLower(path.Ext(p))
-	if len(ext) > 0 {
-		ext = ext[1:]
-	}
-
-	return ext
+// LyricsReader is implemented by formats which can extract song lyrics
+// embedded into audio files.
+type LyricsReader interface {
+	// Lyrics returns lyrics text. Synchronized lyrics are returned
+	// in LRC format. Empty string is returned if the file has no lyrics.
+	Lyrics(path string) (string, error)
 }
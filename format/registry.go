@@ -0,0 +1,255 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package format
+
+import (
+	"cmp"
+	"errors"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Format priorities. Formats with higher priority are tried first.
+const (
+	// Formats used when others fail to handle the file.
+	PriorityLow = -10
+	// General purpose formats, e.g. FFmpeg.
+	PriorityNormal = 0
+	// Formats specialized for the particular file type.
+	PriorityHigh = 10
+	// Formats chosen by user in the configuration.
+	PriorityUser = 100
+)
+
+type entry struct {
+	f    Format
+	prio int
+	// Registration sequence number.
+	seq int
+}
+
+// Registry keeps audio formats ordered by priority for every file
+// extension. Formats must be registered before the registry is used.
+type Registry struct {
+	exts map[string][]entry
+	seq  int
+}
+
+func NewRegistry() *Registry {
+	return &Registry{exts: make(map[string][]entry)}
+}
+
+// Register adds format for all its extensions with the given priority.
+// Among formats of the same priority the one registered later wins.
+func (r *Registry) Register(f Format, prio int) {
+	r.seq++
+	for _, e := range f.Extensions() {
+		e = strings.ToLower(e)
+		es := append(r.exts[e], entry{f: f, prio: prio, seq: r.seq})
+		slices.SortFunc(es, func(a, b entry) int {
+			if a.prio != b.prio {
+				return cmp.Compare(b.prio, a.prio)
+			}
+			return cmp.Compare(b.seq, a.seq)
+		})
+		r.exts[e] = es
+	}
+}
+
+// Formats returns formats registered for the extension
+// in the order they are tried.
+func (r *Registry) Formats(ext string) []Format {
+	es := r.exts[strings.ToLower(ext)]
+	fs := make([]Format, 0, len(es))
+	for _, e := range es {
+		fs = append(fs, e.f)
+	}
+
+	return fs
+}
+
+// Metadata reads file's metadata with the first format which succeeds.
+func (r *Registry) Metadata(path string) (Metadata, error) {
+	var md Metadata
+	err := r.try(path, func(f Format) error {
+		var err error
+		md, err = f.Metadata(path)
+		return err
+	})
+
+	return md, err
+}
+
+// Decoder opens file with the first format which succeeds.
+func (r *Registry) Decoder(path string) (Decoder, error) {
+	var d Decoder
+	err := r.try(path, func(f Format) error {
+		var err error
+		d, err = f.Decoder(path)
+		return err
+	})
+
+	return d, err
+}
+
+// Picture returns picture embedded into the file using the first
+// format which supports pictures and succeeds.
+func (r *Registry) Picture(path string) ([]byte, error) {
+	var pic []byte
+	err := r.try(path, func(f Format) error {
+		pr, ok := unwrap(f).(PictureReader)
+		if !ok {
+			return ErrNotSupported
+		}
+		var err error
+		pic, err = pr.Picture(path)
+		return err
+	})
+
+	return pic, err
+}
+
+// Lyrics returns lyrics embedded into the file. Formats are tried
+// until one finds lyrics, because some of them support only a part
+// of the ways lyrics are stored in.
+func (r *Registry) Lyrics(path string) (string, error) {
+	var lyrics string
+	err := r.try(path, func(f Format) error {
+		lr, ok := unwrap(f).(LyricsReader)
+		if !ok {
+			return ErrNotSupported
+		}
+		var err error
+		lyrics, err = lr.Lyrics(path)
+		if err == nil && lyrics == "" {
+			err = ErrNotSupported
+		}
+		return err
+	})
+	if errors.Is(err, ErrNotSupported) {
+		return "", nil
+	}
+
+	return lyrics, err
+}
+
+// try calls fn for formats registered for file's extension until
+// one succeeds. If all of them fail formats of the type detected
+// by file's content are tried, so files with wrong or missing
+// extension are supported too.
+func (r *Registry) try(p string, fn func(f Format) error) error {
+	var tried []Format
+	var err error
+	attempt := func(fs []Format) bool {
+		for _, f := range fs {
+			if slices.Contains(tried, unwrap(f)) {
+				continue
+			}
+			tried = append(tried, unwrap(f))
+			e := fn(f)
+			if e == nil {
+				return true
+			}
+			// Prefer errors more specific than ErrNotSupported.
+			if err == nil || errors.Is(err, ErrNotSupported) {
+				err = e
+			}
+		}
+		return false
+	}
+
+	e := ext(p)
+	if attempt(r.Formats(e)) {
+		return nil
+	}
+	typ, serr := Sniff(p)
+	if serr == nil && typ != e && attempt(r.Formats(typ)) {
+		return nil
+	}
+	if err == nil {
+		err = serr
+	}
+	if err == nil {
+		err = ErrNotSupported
+	}
+
+	return err
+}
+
+var registry = NewRegistry()
+
+// Register adds format to the global registry.
+func Register(f Format, prio int) {
+	registry.Register(f, prio)
+}
+
+// GetMetadata reads file's metadata using the global registry.
+func GetMetadata(path string) (Metadata, error) {
+	return registry.Metadata(path)
+}
+
+// GetDecoder opens decoder for the file using the global registry.
+func GetDecoder(path string) (Decoder, error) {
+	return registry.Decoder(path)
+}
+
+// GetPicture returns picture embedded into the file using
+// the global registry.
+func GetPicture(path string) ([]byte, error) {
+	return registry.Picture(path)
+}
+
+// GetLyrics returns lyrics embedded into the file using
+// the global registry.
+func GetLyrics(path string) (string, error) {
+	return registry.Lyrics(path)
+}
+
+// WithExtensions returns format which decodes files with given
+// extensions only, using f.
+func WithExtensions(f Format, exts ...string) Format {
+	return &extFormat{Format: f, exts: exts}
+}
+
+type extFormat struct {
+	Format
+	exts []string
+}
+
+func (f *extFormat) Extensions() []string {
+	return f.exts
+}
+
+// unwrap returns format WithExtensions was called with.
+func unwrap(f Format) Format {
+	if ef, ok := f.(*extFormat); ok {
+		return ef.Format
+	}
+
+	return f
+}
+
+func ext(p string) string {
+	ext := strings.ToLower(path.Ext(p))
+	if len(ext) > 0 {
+		ext = ext[1:]
+	}
+
+	return ext
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package format
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+)
+
+type testFormat struct {
+	name string
+	exts []string
+	err  error
+}
+
+func (f *testFormat) Extensions() []string {
+	return f.exts
+}
+
+func (f *testFormat) Metadata(path string) (Metadata, error) {
+	return nil, f.err
+}
+
+func (f *testFormat) Decoder(path string) (Decoder, error) {
+	return nil, f.err
+}
+
+func TestRegistryPriority(t *testing.T) {
+	a := &testFormat{name: "a", exts: []string{"mp3", "flac"}}
+	b := &testFormat{name: "b", exts: []string{"MP3"}}
+	c := &testFormat{name: "c", exts: []string{"mp3"}}
+	r := NewRegistry()
+	r.Register(a, PriorityNormal)
+	r.Register(b, PriorityLow)
+	r.Register(c, PriorityNormal)
+	r.Register(WithExtensions(b, "flac"), PriorityUser)
+
+	assert.True(t, slices.Equal(r.Formats("mp3"), []Format{c, a, b}))
+	fs := r.Formats("FLAC")
+	assert.True(t, len(fs) == 2)
+	assert.True(t, unwrap(fs[0]) == b)
+	assert.True(t, fs[1] == a)
+	assert.True(t, len(r.Formats("ogg")) == 0)
+}
+
+func TestRegistryFallback(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "track.mp3")
+	assert.Nil(t, os.WriteFile(path, []byte("fLaC\x00\x00\x00\x22data"), 0644))
+
+	var tried []string
+	try := func(r *Registry) error {
+		tried = nil
+		return r.try(path, func(f Format) error {
+			tf := unwrap(f).(*testFormat)
+			tried = append(tried, tf.name)
+			return tf.err
+		})
+	}
+	broken := errors.New("broken file")
+	mp3 := &testFormat{name: "mp3", exts: []string{"mp3"},
+		err: ErrNotSupported}
+	ffmpeg := &testFormat{name: "ffmpeg", exts: []string{"flac", "mp3"},
+		err: broken}
+	flac := &testFormat{name: "flac", exts: []string{"flac"}}
+
+	r := NewRegistry()
+	r.Register(mp3, PriorityHigh)
+	r.Register(ffmpeg, PriorityNormal)
+	assert.True(t, try(r) == broken)
+	assert.True(t, slices.Equal(tried, []string{"mp3", "ffmpeg"}))
+
+	// File is detected as FLAC by its content.
+	r.Register(flac, PriorityLow)
+	assert.Nil(t, try(r))
+	assert.True(t, slices.Equal(tried, []string{"mp3", "ffmpeg", "flac"}))
+
+	r = NewRegistry()
+	assert.True(t, try(r) == ErrNotSupported)
+	_, err := r.Decoder(filepath.Join(dir, "missing"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+type pictureFormat struct {
+	testFormat
+	pic []byte
+}
+
+func (f *pictureFormat) Picture(path string) ([]byte, error) {
+	return f.pic, f.err
+}
+
+func TestRegistryPicture(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.mp3")
+	assert.Nil(t, os.WriteFile(path, nil, 0644))
+
+	plain := &testFormat{name: "plain", exts: []string{"mp3"}}
+	pic := &pictureFormat{testFormat: testFormat{name: "pic"},
+		pic: []byte("image")}
+
+	r := NewRegistry()
+	r.Register(plain, PriorityHigh)
+	_, err := r.Picture(path)
+	assert.True(t, err == ErrNotSupported)
+
+	r.Register(WithExtensions(pic, "mp3"), PriorityLow)
+	p, err := r.Picture(path)
+	assert.Nil(t, err)
+	assert.True(t, string(p) == "image")
+}
+
+type lyricsFormat struct {
+	testFormat
+	lyrics string
+}
+
+func (f *lyricsFormat) Lyrics(path string) (string, error) {
+	return f.lyrics, f.err
+}
+
+func TestRegistryLyrics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.mp3")
+	assert.Nil(t, os.WriteFile(path, nil, 0644))
+
+	none := &lyricsFormat{testFormat: testFormat{exts: []string{"mp3"}}}
+	some := &lyricsFormat{testFormat: testFormat{exts: []string{"mp3"}},
+		lyrics: "text"}
+
+	r := NewRegistry()
+	r.Register(none, PriorityHigh)
+	l, err := r.Lyrics(path)
+	assert.Nil(t, err)
+	assert.True(t, l == "")
+
+	r.Register(some, PriorityLow)
+	l, err = r.Lyrics(path)
+	assert.Nil(t, err)
+	assert.True(t, l == "text")
+}
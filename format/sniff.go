@@ -0,0 +1,100 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package format
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// Signatures of audio formats at the beginning of the file.
+var magics = []struct {
+	offset int
+	sig    string
+	ext    string
+}{
+	{0, "fLaC", "flac"},
+	{0, "OggS", "ogg"},
+	{0, "MAC ", "ape"},
+	{0, "wvpk", "wv"},
+	{0, "TTA1", "tta"},
+	{0, "MPCK", "mpc"},
+	{0, "\x30\x26\xb2\x75\x8e\x66\xcf\x11", "wma"},
+	{4, "ftyp", "m4a"},
+}
+
+// Sniff detects audio format by the file's content. Returns
+// extension typical for files of the format.
+func Sniff(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	return sniff(f)
+}
+
+func sniff(r io.ReadSeeker) (string, error) {
+	var hdr [12]byte
+	_, err := io.ReadFull(r, hdr[:])
+	if err != nil {
+		return "", ErrNotSupported
+	}
+
+	// ID3v2 tag can precede MP3 and sometimes other streams.
+	if bytes.HasPrefix(hdr[:], []byte("ID3")) {
+		size := int64(hdr[6]&0x7f)<<21 | int64(hdr[7]&0x7f)<<14 |
+			int64(hdr[8]&0x7f)<<7 | int64(hdr[9]&0x7f)
+		size += 10
+		if hdr[5]&0x10 != 0 {
+			// Footer is present.
+			size += 10
+		}
+		_, err = r.Seek(size, io.SeekStart)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.ReadFull(r, hdr[:])
+		if err != nil {
+			return "", ErrNotSupported
+		}
+	}
+
+	for _, m := range magics {
+		if bytes.HasPrefix(hdr[m.offset:], []byte(m.sig)) {
+			return m.ext, nil
+		}
+	}
+	switch {
+	case string(hdr[0:4]) == "RIFF" && string(hdr[8:12]) == "WAVE":
+		return "wav", nil
+	case string(hdr[0:4]) == "FORM" &&
+		(string(hdr[8:12]) == "AIFF" || string(hdr[8:12]) == "AIFC"):
+		return "aiff", nil
+	case hdr[0] == 0xff && hdr[1]&0xe0 == 0xe0:
+		// MPEG audio frame sync. Layer bits are zero for AAC ADTS.
+		if hdr[1]&0x06 == 0 {
+			return "aac", nil
+		}
+		return "mp3", nil
+	}
+
+	return "", ErrNotSupported
+}
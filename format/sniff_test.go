@@ -0,0 +1,53 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package format
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+)
+
+func TestSniff(t *testing.T) {
+	id3 := "ID3\x03\x00\x00\x00\x00\x00\x04tag!"
+	tests := []struct {
+		data string
+		ext  string
+	}{
+		{"fLaC\x00\x00\x00\x22\x00\x00\x00\x00", "flac"},
+		{"OggS\x00\x02\x00\x00\x00\x00\x00\x00", "ogg"},
+		{"RIFF\x24\x00\x00\x00WAVEfmt ", "wav"},
+		{"FORM\x00\x00\x00\x00AIFCFVER", "aiff"},
+		{"\x00\x00\x00\x20ftypM4A \x00\x00", "m4a"},
+		{"\xff\xfb\x90\x64\x00\x00\x00\x00\x00\x00\x00\x00", "mp3"},
+		{"\xff\xf1\x50\x80\x00\x00\x00\x00\x00\x00\x00\x00", "aac"},
+		{id3 + "\xff\xfb\x90\x64\x00\x00\x00\x00\x00\x00\x00\x00", "mp3"},
+		{id3 + "fLaC\x00\x00\x00\x22\x00\x00\x00\x00", "flac"},
+	}
+	for _, tc := range tests {
+		ext, err := sniff(bytes.NewReader([]byte(tc.data)))
+		assert.Nil(t, err)
+		assert.True(t, ext == tc.ext)
+	}
+
+	_, err := sniff(bytes.NewReader([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0d")))
+	assert.True(t, err == ErrNotSupported)
+	_, err = sniff(bytes.NewReader([]byte("fLaC")))
+	assert.True(t, err == ErrNotSupported)
+}
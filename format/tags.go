@@ -0,0 +1,249 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package format
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Tag identifies textual tag which can be set with Tags.Set.
+type Tag int
+
+const (
+	TagArtist Tag = iota
+	TagAlbumArtist
+	TagAlbum
+	// TagDate sets both date and year, e.g. 1999-12-31.
+	TagDate
+	TagTitle
+	// TagNumber sets track number and optionally
+	// total number of tracks, e.g. 3/12.
+	TagNumber
+	TagTracks
+	// TagDisc sets disc number and optionally
+	// total number of discs, e.g. 2/3.
+	TagDisc
+	TagDiscs
+	TagGenre
+	TagComposer
+	TagComment
+	TagCueSheet
+	// TagLyrics sets unsynchronized lyrics, which are not
+	// a part of Metadata and available with Tags.Lyrics.
+	TagLyrics
+)
+
+// StreamInfo describes audio stream properties of the file.
+type StreamInfo struct {
+	// Length in milliseconds.
+	Length     int
+	Codec      string
+	Bitrate    int
+	BitDepth   int
+	SampleRate int
+	Channels   int
+}
+
+// Tags is a Metadata implementation to be used by format packages.
+// Zero value is ready to use.
+type Tags struct {
+	artist      string
+	albumArtist string
+	album       string
+	year        int
+	date        string
+	title       string
+	number      int
+	tracks      int
+	disc        int
+	discs       int
+	genre       string
+	composer    string
+	comment     string
+	cueSheet    string
+	lyrics      string
+	info        StreamInfo
+}
+
+// Set sets tag value parsing numeric tags. Values which are
+// already set are kept, so the first non-empty one of repeated
+// tags wins.
+func (t *Tags) Set(tag Tag, v string) {
+	v = strings.TrimSpace(v)
+	switch tag {
+	case TagArtist:
+		setString(&t.artist, v)
+	case TagAlbumArtist:
+		setString(&t.albumArtist, v)
+	case TagAlbum:
+		setString(&t.album, v)
+	case TagDate:
+		setString(&t.date, v)
+		setInt(&t.year, parseYear(v))
+	case TagTitle:
+		setString(&t.title, v)
+	case TagNumber:
+		n, total := parseNumber(v)
+		setInt(&t.number, n)
+		setInt(&t.tracks, total)
+	case TagTracks:
+		setInt(&t.tracks, parseInt(v))
+	case TagDisc:
+		n, total := parseNumber(v)
+		setInt(&t.disc, n)
+		setInt(&t.discs, total)
+	case TagDiscs:
+		setInt(&t.discs, parseInt(v))
+	case TagGenre:
+		setString(&t.genre, v)
+	case TagComposer:
+		setString(&t.composer, v)
+	case TagComment:
+		setString(&t.comment, v)
+	case TagCueSheet:
+		setString(&t.cueSheet, v)
+	case TagLyrics:
+		setString(&t.lyrics, v)
+	}
+}
+
+// SetInfo sets audio stream properties.
+func (t *Tags) SetInfo(info StreamInfo) {
+	t.info = info
+}
+
+func (t *Tags) Artist() string {
+	return t.artist
+}
+
+func (t *Tags) AlbumArtist() string {
+	return t.albumArtist
+}
+
+func (t *Tags) Album() string {
+	return t.album
+}
+
+func (t *Tags) Year() int {
+	return t.year
+}
+
+func (t *Tags) Date() string {
+	return t.date
+}
+
+func (t *Tags) Title() string {
+	return t.title
+}
+
+func (t *Tags) Number() int {
+	return t.number
+}
+
+func (t *Tags) Tracks() int {
+	return t.tracks
+}
+
+func (t *Tags) Disc() int {
+	return t.disc
+}
+
+func (t *Tags) Discs() int {
+	return t.discs
+}
+
+func (t *Tags) Genre() string {
+	return t.genre
+}
+
+func (t *Tags) Composer() string {
+	return t.composer
+}
+
+func (t *Tags) Comment() string {
+	return t.comment
+}
+
+func (t *Tags) Length() int {
+	return t.info.Length
+}
+
+func (t *Tags) Codec() string {
+	return t.info.Codec
+}
+
+func (t *Tags) Bitrate() int {
+	return t.info.Bitrate
+}
+
+func (t *Tags) BitDepth() int {
+	return t.info.BitDepth
+}
+
+func (t *Tags) SampleRate() int {
+	return t.info.SampleRate
+}
+
+func (t *Tags) Channels() int {
+	return t.info.Channels
+}
+
+func (t *Tags) CueSheet() string {
+	return t.cueSheet
+}
+
+// Lyrics returns unsynchronized lyrics.
+func (t *Tags) Lyrics() string {
+	return t.lyrics
+}
+
+// parseYear returns year from the date string, e.g. 1999-12-31.
+func parseYear(s string) int {
+	if len(s) < 4 {
+		return 0
+	}
+	y, _ := strconv.Atoi(s[:4])
+
+	return y
+}
+
+// parseNumber returns number and total from the string like 3/12.
+func parseNumber(s string) (int, int) {
+	n, t, _ := strings.Cut(s, "/")
+
+	return parseInt(n), parseInt(t)
+}
+
+func parseInt(s string) int {
+	i, _ := strconv.Atoi(strings.TrimSpace(s))
+
+	return i
+}
+
+func setString(dst *string, v string) {
+	if *dst == "" {
+		*dst = v
+	}
+}
+
+func setInt(dst *int, v int) {
+	if *dst == 0 {
+		*dst = v
+	}
+}
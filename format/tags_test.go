@@ -0,0 +1,51 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package format
+
+import (
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+)
+
+func TestTagsSet(t *testing.T) {
+	md := &Tags{}
+	md.Set(TagArtist, "")
+	md.Set(TagArtist, " Artist ")
+	md.Set(TagArtist, "Other")
+	md.Set(TagDate, "1999-12-31")
+	md.Set(TagTracks, "14")
+	md.Set(TagNumber, "3/12")
+	md.Set(TagDisc, "2")
+	md.Set(TagDiscs, "3")
+	md.Set(TagLyrics, "Lyrics\n")
+	md.SetInfo(StreamInfo{Length: 1000, Codec: "flac", SampleRate: 44100})
+
+	assert.True(t, md.Artist() == "Artist")
+	assert.True(t, md.Date() == "1999-12-31")
+	assert.True(t, md.Year() == 1999)
+	assert.True(t, md.Number() == 3)
+	assert.True(t, md.Tracks() == 14)
+	assert.True(t, md.Disc() == 2)
+	assert.True(t, md.Discs() == 3)
+	assert.True(t, md.Lyrics() == "Lyrics")
+	assert.True(t, md.Length() == 1000)
+	assert.True(t, md.Codec() == "flac")
+	assert.True(t, md.SampleRate() == 44100)
+	assert.True(t, md.Channels() == 0)
+}
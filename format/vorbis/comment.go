@@ -0,0 +1,66 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+// Package vorbis parses Vorbis comments used by FLAC and Ogg files.
+package vorbis
+
+import (
+	"strings"
+
+	"github.com/vchimishuk/chub/format"
+)
+
+// ParseComments fills metadata from Vorbis comments
+// in NAME=value form. Names are case-insensitive.
+func ParseComments(comments []string, md *format.Tags) {
+	for _, c := range comments {
+		name, value, ok := strings.Cut(c, "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToUpper(name) {
+		case "ARTIST":
+			md.Set(format.TagArtist, value)
+		case "ALBUMARTIST", "ALBUM ARTIST", "ALBUM_ARTIST":
+			md.Set(format.TagAlbumArtist, value)
+		case "ALBUM":
+			md.Set(format.TagAlbum, value)
+		case "TITLE":
+			md.Set(format.TagTitle, value)
+		case "DATE", "YEAR":
+			md.Set(format.TagDate, value)
+		case "TRACKNUMBER":
+			md.Set(format.TagNumber, value)
+		case "TRACKTOTAL", "TOTALTRACKS":
+			md.Set(format.TagTracks, value)
+		case "DISCNUMBER":
+			md.Set(format.TagDisc, value)
+		case "DISCTOTAL", "TOTALDISCS":
+			md.Set(format.TagDiscs, value)
+		case "GENRE":
+			md.Set(format.TagGenre, value)
+		case "COMPOSER":
+			md.Set(format.TagComposer, value)
+		case "COMMENT", "DESCRIPTION":
+			md.Set(format.TagComment, value)
+		case "CUESHEET":
+			md.Set(format.TagCueSheet, value)
+		case "LYRICS", "UNSYNCEDLYRICS":
+			md.Set(format.TagLyrics, value)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package vorbis
+
+import (
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+	"github.com/vchimishuk/chub/format"
+)
+
+func TestParseComments(t *testing.T) {
+	md := &format.Tags{}
+	ParseComments([]string{
+		"artist=Artist",
+		"ARTIST=Other",
+		"Album=Album",
+		"TITLE=Title",
+		"DATE=1999-12-31",
+		"TRACKNUMBER=3/12",
+		"ALBUM ARTIST=Various",
+		"DISCNUMBER=2",
+		"TOTALDISCS=3",
+		"GENRE=Jazz",
+		"COMPOSER=Composer",
+		"DESCRIPTION=Comment",
+		"COMMENT",
+		"CUESHEET=FILE \"a.wav\" WAVE\n",
+		"UNSYNCEDLYRICS=Lyrics",
+	}, md)
+
+	assert.True(t, md.Artist() == "Artist")
+	assert.True(t, md.Album() == "Album")
+	assert.True(t, md.Title() == "Title")
+	assert.True(t, md.Year() == 1999)
+	assert.True(t, md.Date() == "1999-12-31")
+	assert.True(t, md.Number() == 3)
+	assert.True(t, md.Tracks() == 12)
+	assert.True(t, md.AlbumArtist() == "Various")
+	assert.True(t, md.Disc() == 2)
+	assert.True(t, md.Discs() == 3)
+	assert.True(t, md.Genre() == "Jazz")
+	assert.True(t, md.Composer() == "Composer")
+	assert.True(t, md.Comment() == "Comment")
+	assert.True(t, md.CueSheet() == "FILE \"a.wav\" WAVE")
+	assert.True(t, md.Lyrics() == "Lyrics")
+}
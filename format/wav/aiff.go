@@ -0,0 +1,136 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/vchimishuk/chub/format"
+)
+
+func parseAiff(r io.ReadSeeker, aifc bool) (*stream, error) {
+	be := binary.BigEndian
+	s := &stream{md: &format.Tags{}, offset: -1, bigEndian: true}
+	var frames int64 = -1
+	// Text chunks are applied after ID3 tag which takes precedence.
+	var texts []format.Tag
+	var values []string
+
+	err := readChunks(r, be, func(c *chunk) error {
+		switch c.id {
+		case "COMM":
+			b, err := readChunk(r, c, 64)
+			if err != nil {
+				return err
+			}
+			if len(b) < 18 {
+				return errors.New("invalid COMM chunk")
+			}
+			s.chans = int(be.Uint16(b[0:]))
+			frames = int64(be.Uint32(b[2:]))
+			s.bits = int(be.Uint16(b[6:]))
+			s.rate = int(math.Round(extended(b[8:18])))
+			s.size = (s.bits + 7) / 8
+			if !aifc {
+				break
+			}
+			if len(b) < 22 {
+				return errors.New("invalid COMM chunk")
+			}
+			switch string(b[18:22]) {
+			case "NONE", "twos":
+			case "sowt":
+				s.bigEndian = false
+			case "fl32", "FL32":
+				s.float = true
+				s.bits = 32
+				s.size = 4
+			case "fl64", "FL64":
+				s.float = true
+				s.bits = 64
+				s.size = 8
+			default:
+				return errors.New("unsupported AIFC compression")
+			}
+		case "SSND":
+			b, err := readChunk(r, c, 8)
+			if err != nil {
+				return err
+			}
+			if len(b) < 8 {
+				return errors.New("invalid SSND chunk")
+			}
+			off := int64(be.Uint32(b))
+			s.offset = c.offset + 8 + off
+			s.length = c.size - 8 - off
+		case "NAME", "AUTH", "ANNO":
+			b, err := readChunk(r, c, maxMetaSize)
+			if err != nil {
+				return err
+			}
+			v := strings.TrimRight(string(b), "\x00 ")
+			tag := format.TagTitle
+			switch c.id {
+			case "AUTH":
+				tag = format.TagArtist
+			case "ANNO":
+				tag = format.TagComment
+			}
+			texts = append(texts, tag)
+			values = append(values, v)
+		case "ID3 ", "id3 ":
+			b, err := readChunk(r, c, maxMetaSize)
+			if err != nil {
+				return err
+			}
+			parseID3(b, s)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if frames < 0 || s.offset < 0 {
+		return nil, errors.New("invalid AIFF file")
+	}
+	for i, t := range texts {
+		s.md.Set(t, values[i])
+	}
+	if s.chans > 0 {
+		s.length = min(s.length, frames*int64(s.frameSize()))
+	}
+
+	return s, nil
+}
+
+// extended converts 80-bit IEEE 754 extended precision number
+// used by AIFF to store sample rate.
+func extended(b []byte) float64 {
+	exp := int(binary.BigEndian.Uint16(b) & 0x7fff)
+	mant := binary.BigEndian.Uint64(b[2:])
+	if exp == 0 && mant == 0 {
+		return 0
+	}
+
+	return math.Ldexp(float64(mant), exp-16383-63)
+}
@@ -0,0 +1,327 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/lyrics"
+)
+
+// ID3 picture type of the front cover.
+const id3FrontCover = 3
+
+// parseID3 parses ID3v2.3 or ID3v2.4 tag stored in the chunk.
+func parseID3(b []byte, s *stream) {
+	md := s.md
+	if len(b) < 10 || string(b[:3]) != "ID3" {
+		return
+	}
+	ver := b[3]
+	flags := b[5]
+	if ver != 3 && ver != 4 {
+		return
+	}
+	b = b[10:min(len(b), 10+syncsafe(b[6:10]))]
+	if ver == 3 && flags&0x80 != 0 {
+		b = unsync(b)
+	}
+	if flags&0x40 != 0 {
+		// Skip extended header.
+		if len(b) < 4 {
+			return
+		}
+		n := int(binary.BigEndian.Uint32(b)) + 4
+		if ver == 4 {
+			n = syncsafe(b)
+		}
+		b = b[min(len(b), n):]
+	}
+
+	for len(b) >= 10 && b[0] != 0 {
+		id := string(b[:4])
+		n := int(binary.BigEndian.Uint32(b[4:]))
+		if ver == 4 {
+			n = syncsafe(b[4:])
+		}
+		fflags := b[9]
+		b = b[10:]
+		n = min(n, len(b))
+		data := b[:n]
+		b = b[n:]
+
+		if ver == 4 && fflags&0x02 != 0 {
+			data = unsync(data)
+		}
+		if fflags&0x0c != 0 {
+			// Compressed or encrypted frame.
+			continue
+		}
+		switch id {
+		case "TPE1":
+			md.Set(format.TagArtist, id3Text(data))
+		case "TPE2":
+			md.Set(format.TagAlbumArtist, id3Text(data))
+		case "TALB":
+			md.Set(format.TagAlbum, id3Text(data))
+		case "TIT2":
+			md.Set(format.TagTitle, id3Text(data))
+		case "TRCK":
+			md.Set(format.TagNumber, id3Text(data))
+		case "TPOS":
+			md.Set(format.TagDisc, id3Text(data))
+		case "TYER", "TDRC":
+			md.Set(format.TagDate, id3Text(data))
+		case "TCON":
+			md.Set(format.TagGenre, id3Genre(id3Text(data)))
+		case "TCOM":
+			md.Set(format.TagComposer, id3Text(data))
+		case "COMM":
+			md.Set(format.TagComment, id3Comment(data))
+		case "USLT":
+			_, text := id3LangText(data)
+			md.Set(format.TagLyrics, text)
+		case "SYLT":
+			if s.syncedLyrics == "" {
+				s.syncedLyrics = id3SyncedLyrics(data)
+			}
+		case "APIC":
+			typ, pic := id3Picture(data)
+			if pic != nil && (s.picture == nil ||
+				typ == id3FrontCover && !s.cover) {
+				s.picture = pic
+				s.cover = typ == id3FrontCover
+			}
+		}
+	}
+}
+
+// id3Picture decodes APIC frame and returns picture type and image data.
+func id3Picture(b []byte) (byte, []byte) {
+	if len(b) < 1 {
+		return 0, nil
+	}
+	enc := b[0]
+	// Skip MIME type.
+	i := bytes.IndexByte(b[1:], 0)
+	if i < 0 || i+3 > len(b) {
+		return 0, nil
+	}
+	typ := b[i+2]
+	b = b[i+3:]
+	// Skip description.
+	if enc == 1 || enc == 2 {
+		i = 0
+		for i+1 < len(b) && (b[i] != 0 || b[i+1] != 0) {
+			i += 2
+		}
+		i += 2
+	} else {
+		i = bytes.IndexByte(b, 0) + 1
+		if i == 0 {
+			return 0, nil
+		}
+	}
+	if i >= len(b) {
+		return 0, nil
+	}
+
+	return typ, b[i:]
+}
+
+// id3Comment decodes COMM frame. Only comments without description
+// are returned, others are usually used by applications internally.
+func id3Comment(b []byte) string {
+	desc, text := id3LangText(b)
+	if desc != "" {
+		return ""
+	}
+
+	return text
+}
+
+// id3LangText decodes frame consisting of text encoding, language,
+// description and text, e.g. COMM or USLT.
+func id3LangText(b []byte) (string, string) {
+	if len(b) < 4 {
+		return "", ""
+	}
+	enc := b[0]
+	// Skip language.
+	desc, b, ok := id3Term(b[4:], enc)
+	if !ok {
+		return "", ""
+	}
+
+	return id3String(desc, enc), id3String(b, enc)
+}
+
+// id3SyncedLyrics decodes SYLT frame into LRC text. Only lyrics with
+// time stamps in milliseconds are supported.
+func id3SyncedLyrics(b []byte) string {
+	if len(b) < 6 || b[4] != 2 {
+		return ""
+	}
+	enc := b[0]
+	// Skip language, time stamp format, content type and description.
+	_, b, ok := id3Term(b[6:], enc)
+	if !ok {
+		return ""
+	}
+
+	var lines []lyrics.Line
+	for len(b) > 0 {
+		var text []byte
+		text, b, ok = id3Term(b, enc)
+		if !ok || len(b) < 4 {
+			break
+		}
+		t := int(binary.BigEndian.Uint32(b))
+		b = b[4:]
+		// Line breaks are stored in the beginning of the text.
+		s := strings.TrimLeft(id3String(text, enc), "\r\n")
+		lines = append(lines, lyrics.Line{Time: t, Text: s})
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return (&lyrics.Lyrics{Synced: true, Lines: lines}).String()
+}
+
+// id3Term splits zero-terminated string in the given encoding
+// from the rest of the data.
+func id3Term(b []byte, enc byte) ([]byte, []byte, bool) {
+	if enc == 1 || enc == 2 {
+		for i := 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				return b[:i], b[i+2:], true
+			}
+		}
+		return nil, nil, false
+	}
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return nil, nil, false
+	}
+
+	return b[:i], b[i+1:], true
+}
+
+// id3String decodes string in the given encoding.
+func id3String(b []byte, enc byte) string {
+	return id3Text(append([]byte{enc}, b...))
+}
+
+// id3Genre returns genre name. ID3v1 genres can be referenced
+// by number, e.g. "(17)", "(17)Rock" or "17".
+func id3Genre(s string) string {
+	n := strings.TrimSuffix(strings.TrimPrefix(s, "("), ")")
+	if strings.HasPrefix(s, "(") {
+		if i := strings.IndexByte(s, ')'); i > 0 {
+			if i < len(s)-1 {
+				return s[i+1:]
+			}
+			n = s[1:i]
+		}
+	}
+	g, err := strconv.Atoi(n)
+	if err != nil {
+		return s
+	}
+	if g < 0 || g >= len(id3Genres) {
+		return ""
+	}
+
+	return id3Genres[g]
+}
+
+// Standard ID3v1 genres.
+var id3Genres = []string{
+	"Blues", "Classic Rock", "Country", "Dance", "Disco", "Funk",
+	"Grunge", "Hip-Hop", "Jazz", "Metal", "New Age", "Oldies", "Other",
+	"Pop", "R&B", "Rap", "Reggae", "Rock", "Techno", "Industrial",
+	"Alternative", "Ska", "Death Metal", "Pranks", "Soundtrack",
+	"Euro-Techno", "Ambient", "Trip-Hop", "Vocal", "Jazz+Funk", "Fusion",
+	"Trance", "Classical", "Instrumental", "Acid", "House", "Game",
+	"Sound Clip", "Gospel", "Noise", "AlternRock", "Bass", "Soul", "Punk",
+	"Space", "Meditative", "Instrumental Pop", "Instrumental Rock",
+	"Ethnic", "Gothic", "Darkwave", "Techno-Industrial", "Electronic",
+	"Pop-Folk", "Eurodance", "Dream", "Southern Rock", "Comedy", "Cult",
+	"Gangsta", "Top 40", "Christian Rap", "Pop/Funk", "Jungle",
+	"Native American", "Cabaret", "New Wave", "Psychadelic", "Rave",
+	"Showtunes", "Trailer", "Lo-Fi", "Tribal", "Acid Punk", "Acid Jazz",
+	"Polka", "Retro", "Musical", "Rock & Roll", "Hard Rock",
+}
+
+// id3Text decodes text frame and returns its first value.
+func id3Text(b []byte) string {
+	if len(b) < 1 {
+		return ""
+	}
+	enc := b[0]
+	b = b[1:]
+
+	var s string
+	switch enc {
+	case 0:
+		// ISO-8859-1.
+		r := make([]rune, len(b))
+		for i, c := range b {
+			r[i] = rune(c)
+		}
+		s = string(r)
+	case 1, 2:
+		// UTF-16 with BOM or UTF-16BE.
+		var order binary.ByteOrder = binary.BigEndian
+		if enc == 1 && len(b) >= 2 {
+			if b[0] == 0xff && b[1] == 0xfe {
+				order = binary.LittleEndian
+			}
+			b = b[2:]
+		}
+		u := make([]uint16, len(b)/2)
+		for i := range u {
+			u[i] = order.Uint16(b[i*2:])
+		}
+		s = string(utf16.Decode(u))
+	default:
+		s = string(b)
+	}
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+
+	return strings.TrimSpace(s)
+}
+
+// syncsafe decodes 28-bit integer stored in four 7-bit bytes.
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7f)<<21 | int(b[1]&0x7f)<<14 |
+		int(b[2]&0x7f)<<7 | int(b[3]&0x7f)
+}
+
+// unsync reverses ID3 unsynchronisation scheme.
+func unsync(b []byte) []byte {
+	return bytes.ReplaceAll(b, []byte{0xff, 0x00}, []byte{0xff})
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package wav
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/vchimishuk/chub/format"
+)
+
+// convert converts n samples from src in the file format to the
+// given sample format in native byte order and stores them in dst.
+func convert(dst []byte, src []byte, s *stream, f format.SampleFormat,
+	n int) {
+
+	osz := f.Size()
+	for i := 0; i < n; i++ {
+		b := src[i*s.size : (i+1)*s.size]
+		o := dst[i*osz:]
+		if s.float {
+			putFloat(o, f, readFloat(b, s.bigEndian))
+		} else {
+			putInt(o, f, readInt(b, s.bigEndian, s.unsigned))
+		}
+	}
+}
+
+// readInt returns integer sample scaled to 32 bits.
+func readInt(b []byte, bigEndian bool, unsigned bool) int32 {
+	var v uint32
+	if bigEndian {
+		for _, c := range b {
+			v = v<<8 | uint32(c)
+		}
+	} else {
+		for i := len(b) - 1; i >= 0; i-- {
+			v = v<<8 | uint32(b[i])
+		}
+	}
+	v <<= 32 - 8*len(b)
+	if unsigned {
+		v ^= 0x80000000
+	}
+
+	return int32(v)
+}
+
+func readFloat(b []byte, bigEndian bool) float64 {
+	var order binary.ByteOrder = binary.LittleEndian
+	if bigEndian {
+		order = binary.BigEndian
+	}
+	if len(b) == 8 {
+		return math.Float64frombits(order.Uint64(b))
+	}
+
+	return float64(math.Float32frombits(order.Uint32(b)))
+}
+
+func putInt(o []byte, f format.SampleFormat, v int32) {
+	ne := binary.NativeEndian
+	switch f {
+	case format.SampleFormatS16:
+		ne.PutUint16(o, uint16(v>>16))
+	case format.SampleFormatS24:
+		ne.PutUint32(o, uint32(v>>8))
+	case format.SampleFormatS32:
+		ne.PutUint32(o, uint32(v))
+	case format.SampleFormatF32:
+		ne.PutUint32(o, math.Float32bits(float32(float64(v)/(1<<31))))
+	}
+}
+
+func putFloat(o []byte, f format.SampleFormat, v float64) {
+	ne := binary.NativeEndian
+	if f == format.SampleFormatF32 {
+		ne.PutUint32(o, math.Float32bits(float32(v)))
+		return
+	}
+
+	v = max(-1, min(1, v))
+	switch f {
+	case format.SampleFormatS16:
+		ne.PutUint16(o, uint16(int16(math.Round(v*math.MaxInt16))))
+	case format.SampleFormatS24:
+		ne.PutUint32(o, uint32(int32(math.Round(v*(1<<23-1)))))
+	case format.SampleFormatS32:
+		ne.PutUint32(o, uint32(int32(math.Round(v*math.MaxInt32))))
+	}
+}
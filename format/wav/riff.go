@@ -0,0 +1,211 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/vchimishuk/chub/format"
+)
+
+// Maximum size of the metadata chunk to read.
+const maxMetaSize = 16 << 20
+
+// errStop stops chunks iteration.
+var errStop = errors.New("stop")
+
+// chunk is a RIFF (WAV) or IFF (AIFF) chunk header.
+type chunk struct {
+	id   string
+	size int64
+	// Offset of the chunk data in the file.
+	offset int64
+}
+
+// readChunks calls f for every chunk starting from the current
+// position till the end of the file or till f returns errStop.
+func readChunks(r io.ReadSeeker, order binary.ByteOrder,
+	f func(c *chunk) error) error {
+
+	pos, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	for {
+		_, err := r.Seek(pos, io.SeekStart)
+		if err != nil {
+			return err
+		}
+		var hdr [8]byte
+		_, err = io.ReadFull(r, hdr[:])
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		c := &chunk{
+			id:     string(hdr[:4]),
+			size:   int64(order.Uint32(hdr[4:])),
+			offset: pos + int64(len(hdr)),
+		}
+		err = f(c)
+		if err == errStop {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		// Chunks are padded to even size.
+		pos = c.offset + c.size + c.size&1
+	}
+}
+
+// readChunk returns first max bytes of the chunk data.
+func readChunk(r io.ReadSeeker, c *chunk, max int64) ([]byte, error) {
+	_, err := r.Seek(c.offset, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, min(c.size, max))
+	n, err := io.ReadFull(r, b)
+	if err == io.ErrUnexpectedEOF {
+		// Truncated file.
+		err = nil
+	}
+
+	return b[:n], err
+}
+
+func parseWav(r io.ReadSeeker) (*stream, error) {
+	le := binary.LittleEndian
+	s := &stream{md: &format.Tags{}, offset: -1}
+	var hasFmt bool
+	var lists [][]byte
+
+	err := readChunks(r, le, func(c *chunk) error {
+		switch c.id {
+		case "fmt ":
+			b, err := readChunk(r, c, 64)
+			if err != nil {
+				return err
+			}
+			if len(b) < 16 {
+				return errors.New("invalid fmt chunk")
+			}
+			tag := le.Uint16(b[0:])
+			s.chans = int(le.Uint16(b[2:]))
+			s.rate = int(le.Uint32(b[4:]))
+			align := int(le.Uint16(b[12:]))
+			s.bits = int(le.Uint16(b[14:]))
+			if tag == 0xfffe {
+				// WAVE_FORMAT_EXTENSIBLE.
+				if len(b) < 26 {
+					return errors.New("invalid fmt chunk")
+				}
+				if vb := int(le.Uint16(b[18:])); vb != 0 {
+					s.bits = vb
+				}
+				// The first two bytes of the sub-format GUID.
+				tag = le.Uint16(b[24:])
+			}
+			switch tag {
+			case 1:
+			case 3:
+				s.float = true
+			default:
+				return errors.New("unsupported WAV encoding")
+			}
+			if s.chans > 0 {
+				s.size = align / s.chans
+			}
+			s.unsigned = s.size == 1
+			hasFmt = true
+		case "data":
+			s.offset = c.offset
+			s.length = c.size
+			if c.size == 0 || c.size == math.MaxUint32 {
+				// Size is unknown, data lasts till
+				// the end of the file.
+				s.length = math.MaxInt64
+				return errStop
+			}
+		case "LIST":
+			b, err := readChunk(r, c, maxMetaSize)
+			if err != nil {
+				return err
+			}
+			lists = append(lists, b)
+		case "id3 ", "ID3 ":
+			b, err := readChunk(r, c, maxMetaSize)
+			if err != nil {
+				return err
+			}
+			parseID3(b, s)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !hasFmt || s.offset < 0 {
+		return nil, errors.New("invalid WAV file")
+	}
+	// ID3 tag is more complete, so it takes precedence over INFO.
+	for _, b := range lists {
+		parseInfo(b, s.md)
+	}
+
+	return s, nil
+}
+
+// parseInfo parses LIST chunk with INFO metadata.
+func parseInfo(b []byte, md *format.Tags) {
+	if len(b) < 4 || string(b[:4]) != "INFO" {
+		return
+	}
+	b = b[4:]
+	for len(b) >= 8 {
+		id := string(b[:4])
+		n := min(int(binary.LittleEndian.Uint32(b[4:])), len(b)-8)
+		b = b[8:]
+		v := strings.TrimRight(string(b[:n]), "\x00 ")
+		switch id {
+		case "IART":
+			md.Set(format.TagArtist, v)
+		case "INAM":
+			md.Set(format.TagTitle, v)
+		case "IPRD":
+			md.Set(format.TagAlbum, v)
+		case "ICRD":
+			md.Set(format.TagDate, v)
+		case "ITRK", "IPRT":
+			md.Set(format.TagNumber, v)
+		case "IGNR":
+			md.Set(format.TagGenre, v)
+		case "IMUS":
+			md.Set(format.TagComposer, v)
+		case "ICMT":
+			md.Set(format.TagComment, v)
+		}
+		b = b[min(len(b), n+n&1):]
+	}
+}
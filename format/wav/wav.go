@@ -0,0 +1,319 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+// WAV and AIFF audio format. Files are decoded in pure Go,
+// so the format is available without any external libraries.
+package wav
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/vchimishuk/chub/format"
+)
+
+// stream describes PCM data stored in the file.
+type stream struct {
+	rate  int
+	chans int
+	// Number of significant bits in the sample.
+	bits int
+	// Bytes taken by one sample in the file.
+	size int
+	// Samples are IEEE floats, integers otherwise.
+	float bool
+	// Samples are stored in big-endian byte order (AIFF).
+	bigEndian bool
+	// 8-bit samples are unsigned (WAV).
+	unsigned bool
+	// Offset and length of the sample data in the file.
+	offset int64
+	length int64
+	md     *format.Tags
+	// Embedded picture and whether it is the front cover.
+	picture []byte
+	cover   bool
+	// Synchronized lyrics in LRC format.
+	syncedLyrics string
+}
+
+func (s *stream) frameSize() int {
+	return s.size * s.chans
+}
+
+// frames returns number of frames in the file.
+func (s *stream) frames() int64 {
+	return s.length / int64(s.frameSize())
+}
+
+// codec returns FFmpeg-like name of the PCM encoding, e.g. pcm_s16le.
+func (s *stream) codec() string {
+	if s.size == 1 {
+		if s.unsigned {
+			return "pcm_u8"
+		}
+		return "pcm_s8"
+	}
+	t := "s"
+	if s.float {
+		t = "f"
+	}
+	e := "le"
+	if s.bigEndian {
+		e = "be"
+	}
+
+	return fmt.Sprintf("pcm_%s%d%s", t, s.size*8, e)
+}
+
+// sampleFormat returns the format closest to the file's one.
+func (s *stream) sampleFormat() format.SampleFormat {
+	switch {
+	case s.float:
+		return format.SampleFormatF32
+	case s.bits <= 16:
+		return format.SampleFormatS16
+	case s.bits <= 24:
+		return format.SampleFormatS24
+	default:
+		return format.SampleFormatS32
+	}
+}
+
+// validate checks that stream parameters are sane and supported.
+func (s *stream) validate() error {
+	if s.rate <= 0 || s.chans <= 0 {
+		return errors.New("invalid stream format")
+	}
+	if s.float {
+		if s.size != 4 && s.size != 8 {
+			return errors.New("unsupported float sample size")
+		}
+	} else if s.size < 1 || s.size > 4 || s.bits < 1 || s.bits > 32 {
+		return errors.New("unsupported sample size")
+	}
+
+	return nil
+}
+
+type decoder struct {
+	file *os.File
+	s    *stream
+	sfmt format.SampleFormat
+	// Current position in frames.
+	pos int64
+	// Buffer for raw file data.
+	buf []byte
+}
+
+func newDecoder(path string) (*decoder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := parse(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &decoder{file: f, s: s, sfmt: s.sampleFormat()}, nil
+}
+
+func (d *decoder) Read(buf []byte) (int, error) {
+	osz := d.s.chans * d.sfmt.Size()
+	frames := min(int64(len(buf)/osz), d.s.frames()-d.pos)
+	if frames <= 0 {
+		return 0, nil
+	}
+
+	fsz := d.s.frameSize()
+	n := int(frames) * fsz
+	if len(d.buf) < n {
+		d.buf = make([]byte, n)
+	}
+	r, err := d.file.ReadAt(d.buf[:n], d.s.offset+d.pos*int64(fsz))
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	// File can be truncated.
+	frames = int64(r / fsz)
+	convert(buf, d.buf, d.s, d.sfmt, int(frames)*d.s.chans)
+	d.pos += frames
+
+	return int(frames) * osz, nil
+}
+
+// Seek sets new position in milliseconds.
+func (d *decoder) Seek(pos int) error {
+	if pos < 0 {
+		return errors.New("invalid seek position")
+	}
+	d.pos = min(int64(pos)*int64(d.s.rate)/1000, d.s.frames())
+
+	return nil
+}
+
+// Time returns current position in milliseconds.
+func (d *decoder) Time() int {
+	return int(d.pos * 1000 / int64(d.s.rate))
+}
+
+func (d *decoder) SampleRate() int {
+	return d.s.rate
+}
+
+func (d *decoder) Channels() int {
+	return d.s.chans
+}
+
+func (d *decoder) SampleFormat() format.SampleFormat {
+	return d.sfmt
+}
+
+func (d *decoder) SetSampleFormat(f format.SampleFormat) error {
+	switch f {
+	case format.SampleFormatS16, format.SampleFormatS24,
+		format.SampleFormatS32, format.SampleFormatF32:
+		d.sfmt = f
+		return nil
+	default:
+		return errors.New("unsupported sample format")
+	}
+}
+
+func (d *decoder) Close() error {
+	return d.file.Close()
+}
+
+type wav struct {
+}
+
+func NewFormat() format.Format {
+	return &wav{}
+}
+
+func (f wav) Extensions() []string {
+	return []string{
+		"aif",
+		"aifc",
+		"aiff",
+		"wav",
+	}
+}
+
+func (f wav) Metadata(path string) (format.Metadata, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	s, err := parse(file)
+	if err != nil {
+		return nil, err
+	}
+	s.md.SetInfo(format.StreamInfo{
+		Length:     int(s.frames() * 1000 / int64(s.rate)),
+		Codec:      s.codec(),
+		Bitrate:    s.rate * s.frameSize() * 8,
+		BitDepth:   s.bits,
+		SampleRate: s.rate,
+		Channels:   s.chans,
+	})
+
+	return s.md, nil
+}
+
+func (f wav) Picture(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	s, err := parse(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.picture, nil
+}
+
+func (f wav) Lyrics(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	s, err := parse(file)
+	if err != nil {
+		return "", err
+	}
+	if s.syncedLyrics != "" {
+		return s.syncedLyrics, nil
+	}
+
+	return s.md.Lyrics(), nil
+}
+
+func (f wav) Decoder(path string) (format.Decoder, error) {
+	return newDecoder(path)
+}
+
+// parse reads file headers and returns description of the PCM data.
+func parse(r io.ReadSeeker) (*stream, error) {
+	var hdr [12]byte
+	_, err := io.ReadFull(r, hdr[:])
+	if err != nil {
+		return nil, format.ErrNotSupported
+	}
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.Seek(int64(len(hdr)), io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	var s *stream
+	id := string(hdr[0:4])
+	typ := string(hdr[8:12])
+	switch {
+	case id == "RIFF" && typ == "WAVE":
+		s, err = parseWav(r)
+	case id == "FORM" && (typ == "AIFF" || typ == "AIFC"):
+		s, err = parseAiff(r, typ == "AIFC")
+	default:
+		return nil, format.ErrNotSupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = s.validate()
+	if err != nil {
+		return nil, err
+	}
+	// Writers which can not seek back leave data size unset.
+	s.length = max(0, min(s.length, size-s.offset))
+
+	return s, nil
+}
@@ -0,0 +1,431 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package wav
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+	"github.com/vchimishuk/chub/format"
+)
+
+// riffChunk returns chunk with the given id and data.
+func riffChunk(order binary.ByteOrder, id string, data []byte) []byte {
+	b := append([]byte(id), 0, 0, 0, 0)
+	order.PutUint32(b[4:], uint32(len(data)))
+	b = append(b, data...)
+	if len(data)%2 == 1 {
+		b = append(b, 0)
+	}
+
+	return b
+}
+
+func fmtChunk(tag int, chans int, rate int, bits int, align int) []byte {
+	le := binary.LittleEndian
+	b := le.AppendUint16(nil, uint16(tag))
+	b = le.AppendUint16(b, uint16(chans))
+	b = le.AppendUint32(b, uint32(rate))
+	b = le.AppendUint32(b, uint32(rate*align))
+	b = le.AppendUint16(b, uint16(align))
+	b = le.AppendUint16(b, uint16(bits))
+
+	return riffChunk(le, "fmt ", b)
+}
+
+func writeFile(t *testing.T, name string, id string, typ string,
+	order binary.ByteOrder, chunks ...[]byte) string {
+
+	var body []byte
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+	b := riffChunk(order, id, append([]byte(typ), body...))
+	p := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(p, b, 0644))
+
+	return p
+}
+
+func writeWav(t *testing.T, chunks ...[]byte) string {
+	return writeFile(t, "test.wav", "RIFF", "WAVE",
+		binary.LittleEndian, chunks...)
+}
+
+// commChunk returns AIFF COMM chunk, compression is used for AIFC only.
+func commChunk(chans int, frames int, sbits int, rate int,
+	compression string) []byte {
+
+	be := binary.BigEndian
+	b := be.AppendUint16(nil, uint16(chans))
+	b = be.AppendUint32(b, uint32(frames))
+	b = be.AppendUint16(b, uint16(sbits))
+	n := bits.Len64(uint64(rate))
+	b = be.AppendUint16(b, uint16(16383+n-1))
+	b = be.AppendUint64(b, uint64(rate)<<(64-n))
+	if compression != "" {
+		b = append(b, compression...)
+		b = append(b, 0, 0)
+	}
+
+	return riffChunk(be, "COMM", b)
+}
+
+func ssndChunk(data []byte) []byte {
+	return riffChunk(binary.BigEndian, "SSND",
+		append(make([]byte, 8), data...))
+}
+
+func decode(t *testing.T, p string, f format.SampleFormat) []byte {
+	d, err := NewFormat().Decoder(p)
+	assert.Nil(t, err)
+	defer d.Close()
+	assert.Nil(t, d.SetSampleFormat(f))
+
+	var res []byte
+	buf := make([]byte, 64)
+	for {
+		n, err := d.Read(buf)
+		assert.Nil(t, err)
+		if n == 0 {
+			break
+		}
+		res = append(res, buf[:n]...)
+	}
+
+	return res
+}
+
+func s16(b []byte) []int16 {
+	r := make([]int16, len(b)/2)
+	for i := range r {
+		r[i] = int16(binary.NativeEndian.Uint16(b[i*2:]))
+	}
+
+	return r
+}
+
+func s32(b []byte) []int32 {
+	r := make([]int32, len(b)/4)
+	for i := range r {
+		r[i] = int32(binary.NativeEndian.Uint32(b[i*4:]))
+	}
+
+	return r
+}
+
+func equal[T comparable](a []T, b ...T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestWav16(t *testing.T) {
+	p := writeWav(t, fmtChunk(1, 2, 44100, 16, 4),
+		riffChunk(binary.LittleEndian, "data",
+			[]byte{1, 0, 0xff, 0xff, 0, 0x80, 0xff, 0x7f}))
+
+	d, err := NewFormat().Decoder(p)
+	assert.Nil(t, err)
+	assert.True(t, d.SampleRate() == 44100)
+	assert.True(t, d.Channels() == 2)
+	assert.True(t, d.SampleFormat() == format.SampleFormatS16)
+	assert.Nil(t, d.Close())
+
+	assert.True(t, equal(s16(decode(t, p, format.SampleFormatS16)),
+		1, -1, -32768, 32767))
+	assert.True(t, equal(s32(decode(t, p, format.SampleFormatS24)),
+		1<<8, -1<<8, -32768<<8, 32767<<8))
+}
+
+func TestWav8(t *testing.T) {
+	p := writeWav(t, fmtChunk(1, 1, 8000, 8, 1),
+		riffChunk(binary.LittleEndian, "data", []byte{0x80, 0xff, 0}))
+
+	assert.True(t, equal(s16(decode(t, p, format.SampleFormatS16)),
+		0, 127<<8, -32768))
+}
+
+func TestWav24(t *testing.T) {
+	p := writeWav(t, fmtChunk(1, 1, 48000, 24, 3),
+		riffChunk(binary.LittleEndian, "data",
+			[]byte{0x01, 0x02, 0x03, 0xff, 0xff, 0xff}))
+
+	d, err := NewFormat().Decoder(p)
+	assert.Nil(t, err)
+	assert.True(t, d.SampleFormat() == format.SampleFormatS24)
+	assert.Nil(t, d.Close())
+	assert.True(t, equal(s32(decode(t, p, format.SampleFormatS24)),
+		0x030201, -1))
+	assert.True(t, equal(s16(decode(t, p, format.SampleFormatS16)),
+		0x0302, -1))
+}
+
+func TestWavExtensible(t *testing.T) {
+	le := binary.LittleEndian
+	b := le.AppendUint16(nil, 0xfffe)
+	b = le.AppendUint16(b, 1)
+	b = le.AppendUint32(b, 96000)
+	b = le.AppendUint32(b, 96000*4)
+	b = le.AppendUint16(b, 4)
+	b = le.AppendUint16(b, 32)
+	b = le.AppendUint16(b, 22)
+	b = le.AppendUint16(b, 24)
+	b = le.AppendUint32(b, 4)
+	b = append(b, 1, 0, 0, 0, 0, 0, 0x10, 0, 0x80, 0, 0, 0xaa,
+		0, 0x38, 0x9b, 0x71)
+	p := writeWav(t, riffChunk(le, "fmt ", b),
+		riffChunk(le, "data", []byte{0, 1, 2, 3}))
+
+	d, err := NewFormat().Decoder(p)
+	assert.Nil(t, err)
+	assert.True(t, d.SampleRate() == 96000)
+	assert.True(t, d.SampleFormat() == format.SampleFormatS24)
+	assert.Nil(t, d.Close())
+	assert.True(t, equal(s32(decode(t, p, format.SampleFormatS32)),
+		0x03020100))
+}
+
+func TestWavFloat(t *testing.T) {
+	le := binary.LittleEndian
+	var data []byte
+	for _, v := range []float32{0.5, -1, 2} {
+		data = le.AppendUint32(data, math.Float32bits(v))
+	}
+	p := writeWav(t, fmtChunk(3, 1, 44100, 32, 4),
+		riffChunk(le, "data", data))
+
+	d, err := NewFormat().Decoder(p)
+	assert.Nil(t, err)
+	assert.True(t, d.SampleFormat() == format.SampleFormatF32)
+	assert.Nil(t, d.Close())
+	f := s32(decode(t, p, format.SampleFormatF32))
+	assert.True(t, math.Float32frombits(uint32(f[0])) == 0.5)
+	// Out of range values are clipped.
+	assert.True(t, equal(s16(decode(t, p, format.SampleFormatS16)),
+		16384, -32767, 32767))
+}
+
+func TestAiff(t *testing.T) {
+	p := writeFile(t, "test.aiff", "FORM", "AIFF", binary.BigEndian,
+		commChunk(2, 2, 16, 44100, ""),
+		riffChunk(binary.BigEndian, "NAME", []byte("Song")),
+		ssndChunk([]byte{0, 1, 0xff, 0xff, 0x80, 0, 0x7f, 0xff}))
+
+	d, err := NewFormat().Decoder(p)
+	assert.Nil(t, err)
+	assert.True(t, d.SampleRate() == 44100)
+	assert.True(t, d.Channels() == 2)
+	assert.Nil(t, d.Close())
+	assert.True(t, equal(s16(decode(t, p, format.SampleFormatS16)),
+		1, -1, -32768, 32767))
+
+	md, err := NewFormat().Metadata(p)
+	assert.Nil(t, err)
+	assert.True(t, md.Title() == "Song")
+}
+
+func TestAifc(t *testing.T) {
+	p := writeFile(t, "test.aifc", "FORM", "AIFC", binary.BigEndian,
+		commChunk(1, 2, 16, 22050, "sowt"),
+		ssndChunk([]byte{1, 0, 0xff, 0xff}))
+
+	assert.True(t, equal(s16(decode(t, p, format.SampleFormatS16)),
+		1, -1))
+
+	p = writeFile(t, "test.aifc", "FORM", "AIFC", binary.BigEndian,
+		commChunk(1, 1, 16, 22050, "ulaw"),
+		ssndChunk([]byte{1, 0}))
+	_, err := NewFormat().Decoder(p)
+	assert.Error(t, err, "unsupported AIFC compression")
+}
+
+func TestSeek(t *testing.T) {
+	data := make([]byte, 2000*2)
+	for i := 0; i < 2000; i++ {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(i))
+	}
+	p := writeWav(t, fmtChunk(1, 1, 1000, 16, 2),
+		riffChunk(binary.LittleEndian, "data", data))
+
+	d, err := NewFormat().Decoder(p)
+	assert.Nil(t, err)
+	defer d.Close()
+	assert.Nil(t, d.Seek(500))
+	assert.True(t, d.Time() == 500)
+	buf := make([]byte, 4)
+	n, err := d.Read(buf)
+	assert.Nil(t, err)
+	assert.True(t, n == 4)
+	assert.True(t, equal(s16(buf), 500, 501))
+	assert.True(t, d.Time() == 502)
+
+	assert.Nil(t, d.Seek(5000))
+	assert.True(t, d.Time() == 2000)
+	n, err = d.Read(buf)
+	assert.Nil(t, err)
+	assert.True(t, n == 0)
+	assert.Error(t, d.Seek(-1), "invalid seek position")
+}
+
+func TestMetadata(t *testing.T) {
+	le := binary.LittleEndian
+	info := []byte("INFO")
+	info = append(info, riffChunk(le, "IART", []byte("Artist\x00"))...)
+	info = append(info, riffChunk(le, "INAM", []byte("Title\x00"))...)
+	info = append(info, riffChunk(le, "ICRD", []byte("1999-01-01"))...)
+	info = append(info, riffChunk(le, "IGNR", []byte("Jazz\x00"))...)
+
+	// ID3v2.4 tag with UTF-8 album and track number.
+	var frames []byte
+	for _, f := range []struct{ id, v string }{
+		{"TALB", "\x03Альбом"},
+		{"TRCK", "\x007/12"},
+		{"TPOS", "\x001/2"},
+		{"TPE2", "\x00Various"},
+		{"TCOM", "\x00Composer"},
+		{"TCON", "\x00(17)"},
+		{"COMM", "\x00engiTunNORM\x00 000001"},
+		{"COMM", "\x00eng\x00Comment"},
+	} {
+		frames = append(frames, f.id...)
+		frames = append(frames, 0, 0, 0, byte(len(f.v)), 0, 0)
+		frames = append(frames, f.v...)
+	}
+	n := len(frames)
+	id3 := append([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, byte(n >> 7),
+		byte(n & 0x7f)}, frames...)
+
+	p := writeWav(t, fmtChunk(1, 2, 1000, 16, 4),
+		riffChunk(le, "data", make([]byte, 4*1500)),
+		riffChunk(le, "LIST", info),
+		riffChunk(le, "id3 ", id3))
+
+	md, err := NewFormat().Metadata(p)
+	assert.Nil(t, err)
+	assert.True(t, md.Artist() == "Artist")
+	assert.True(t, md.Title() == "Title")
+	assert.True(t, md.Album() == "Альбом")
+	assert.True(t, md.Year() == 1999)
+	assert.True(t, md.Date() == "1999-01-01")
+	assert.True(t, md.Number() == 7)
+	assert.True(t, md.Tracks() == 12)
+	assert.True(t, md.Disc() == 1)
+	assert.True(t, md.Discs() == 2)
+	assert.True(t, md.AlbumArtist() == "Various")
+	assert.True(t, md.Composer() == "Composer")
+	assert.True(t, md.Genre() == "Rock")
+	assert.True(t, md.Comment() == "Comment")
+	assert.True(t, md.Length() == 1500)
+	assert.True(t, md.Codec() == "pcm_s16le")
+	assert.True(t, md.Bitrate() == 32000)
+	assert.True(t, md.BitDepth() == 16)
+	assert.True(t, md.SampleRate() == 1000)
+	assert.True(t, md.Channels() == 2)
+}
+
+func TestPicture(t *testing.T) {
+	le := binary.LittleEndian
+	var frames []byte
+	for _, v := range []string{
+		"\x00image/png\x00\x00Other\x00other",
+		"\x01image/jpeg\x00\x03\xff\xfeC\x00\x00\x00cover",
+	} {
+		frames = append(frames, "APIC"...)
+		frames = append(frames, 0, 0, 0, byte(len(v)), 0, 0)
+		frames = append(frames, v...)
+	}
+	id3 := append([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0,
+		byte(len(frames))}, frames...)
+
+	p := writeWav(t, fmtChunk(1, 2, 1000, 16, 4),
+		riffChunk(le, "data", make([]byte, 4)),
+		riffChunk(le, "id3 ", id3))
+	pic, err := NewFormat().(format.PictureReader).Picture(p)
+	assert.Nil(t, err)
+	assert.True(t, string(pic) == "cover")
+
+	p = writeWav(t, fmtChunk(1, 2, 1000, 16, 4),
+		riffChunk(le, "data", make([]byte, 4)))
+	pic, err = NewFormat().(format.PictureReader).Picture(p)
+	assert.Nil(t, err)
+	assert.True(t, pic == nil)
+}
+
+func TestLyrics(t *testing.T) {
+	le := binary.LittleEndian
+	id3 := func(frames ...string) []byte {
+		var b []byte
+		for _, f := range frames {
+			b = append(b, f[:4]...)
+			b = append(b, 0, 0, 0, byte(len(f)-4), 0, 0)
+			b = append(b, f[4:]...)
+		}
+		return append([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0,
+			byte(len(b))}, b...)
+	}
+	uslt := "USLT\x00eng\x00Plain lyrics"
+	sylt := "SYLT\x00eng\x02\x01\x00" +
+		"First\x00\x00\x00\x03\xe8" +
+		"\nSecond\x00\x00\x00\x07\xd0"
+
+	p := writeWav(t, fmtChunk(1, 2, 1000, 16, 4),
+		riffChunk(le, "data", make([]byte, 4)),
+		riffChunk(le, "id3 ", id3(uslt)))
+	l, err := NewFormat().(format.LyricsReader).Lyrics(p)
+	assert.Nil(t, err)
+	assert.True(t, l == "Plain lyrics")
+
+	p = writeWav(t, fmtChunk(1, 2, 1000, 16, 4),
+		riffChunk(le, "data", make([]byte, 4)),
+		riffChunk(le, "id3 ", id3(uslt, sylt)))
+	l, err = NewFormat().(format.LyricsReader).Lyrics(p)
+	assert.Nil(t, err)
+	assert.True(t, l == "[00:01.00]First\n[00:02.00]Second\n")
+}
+
+func TestID3Genre(t *testing.T) {
+	assert.True(t, id3Genre("(17)") == "Rock")
+	assert.True(t, id3Genre("17") == "Rock")
+	assert.True(t, id3Genre("(17)Hard Rock") == "Hard Rock")
+	assert.True(t, id3Genre("Heavy Metal") == "Heavy Metal")
+	assert.True(t, id3Genre("(999)") == "")
+}
+
+func TestNotSupported(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "test.wav")
+	assert.Nil(t, os.WriteFile(p, []byte("not a wave file"), 0644))
+	_, err := NewFormat().Decoder(p)
+	assert.True(t, err == format.ErrNotSupported)
+
+	p = writeWav(t, riffChunk(binary.LittleEndian, "data", []byte{0}))
+	_, err = NewFormat().Decoder(p)
+	assert.Error(t, err, "invalid WAV file")
+}
@@ -7,3 +7,5 @@ require github.com/vchimishuk/opt v0.0.0-20250103221129-d823c9050e21
 require github.com/vchimishuk/config v0.0.0-20230910195755-ed7bd1b64558
 
 require github.com/akrylysov/pogreb v0.10.2
+
+require golang.org/x/text v0.22.0
@@ -0,0 +1,355 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+// HTTP streaming output driver. It encodes PCM stream and serves it
+// to any number of HTTP clients, like Icecast server does.
+package httpd
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/vfs"
+)
+
+const (
+	// Number of stream bytes between ICY metadata blocks.
+	icyMetaInt = 16000
+	// Maximum number of encoded data chunks queued for a client.
+	// Clients which do not keep up with the stream are disconnected.
+	clientQueueLen = 256
+)
+
+// Encoder encodes PCM stream into the format suitable for streaming.
+type Encoder interface {
+	// Header returns stream header every client has to receive first.
+	Header() []byte
+	// Write encodes PCM data and returns encoded data ready to be sent.
+	Write(buf []byte) ([]byte, error)
+	// Close finishes the stream and returns the rest of encoded data.
+	Close() ([]byte, error)
+}
+
+// EncoderFunc creates encoder for the PCM stream with given parameters.
+type EncoderFunc func(rate int, channels int,
+	sfmt format.SampleFormat) (Encoder, error)
+
+type client struct {
+	data chan []byte
+}
+
+type Httpd struct {
+	addr        string
+	contentType string
+	newEncoder  EncoderFunc
+	open        bool
+	rate        int
+	chans       int
+	sfmt        format.SampleFormat
+	volume      int
+	enc         Encoder
+	ln          net.Listener
+	// Time when first frame after open, resume or parameters change
+	// has been written.
+	start time.Time
+	// Number of frames written since start.
+	frames int64
+	// Mutex guards fields below which are shared with HTTP handlers.
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	// Header of the current stream.
+	header []byte
+	// Current track title.
+	title string
+}
+
+// New returns HTTP output driver which listens for clients on addr.
+// Stream of contentType MIME type is produced by encoders returned
+// by newEncoder.
+func New(addr string, contentType string, newEncoder EncoderFunc) *Httpd {
+	return &Httpd{
+		addr:        addr,
+		contentType: contentType,
+		newEncoder:  newEncoder,
+		rate:        44100,
+		chans:       2,
+		sfmt:        format.SampleFormatS16,
+		clients:     map[*client]struct{}{},
+	}
+}
+
+// Open starts HTTP server if it is not started yet. Server is kept
+// running when the output is closed, so clients stay connected while
+// playback is stopped.
+func (h *Httpd) Open() error {
+	if h.ln == nil {
+		ln, err := net.Listen("tcp", h.addr)
+		if err != nil {
+			return err
+		}
+		h.ln = ln
+		go http.Serve(ln, h)
+	}
+	h.open = true
+	h.frames = 0
+
+	return nil
+}
+
+func (h *Httpd) SetSampleRate(rate int) error {
+	if rate <= 0 {
+		return errors.New("invalid sample rate")
+	}
+	if rate != h.rate {
+		h.rate = rate
+		h.frames = 0
+		return h.finish()
+	}
+
+	return nil
+}
+
+func (h *Httpd) SetChannels(chans int) error {
+	if chans <= 0 {
+		return errors.New("invalid number of channels")
+	}
+	if chans != h.chans {
+		h.chans = chans
+		h.frames = 0
+		return h.finish()
+	}
+
+	return nil
+}
+
+func (h *Httpd) SetSampleFormat(f format.SampleFormat) error {
+	if f != h.sfmt {
+		h.sfmt = f
+		h.frames = 0
+		return h.finish()
+	}
+
+	return nil
+}
+
+// SetTrack updates stream title sent to the clients in ICY metadata.
+func (h *Httpd) SetTrack(t *vfs.Track) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.title = streamTitle(t)
+
+	return nil
+}
+
+// Write encodes data and sends it to all connected clients. Write
+// blocks for the time needed to play the data, so clients receive
+// the stream at real-time pace.
+func (h *Httpd) Write(buf []byte) (int, error) {
+	if !h.open {
+		return 0, errors.New("output is closed")
+	}
+
+	fsz := h.chans * h.sfmt.Size()
+	frames := len(buf) / fsz
+	if frames == 0 {
+		return 0, nil
+	}
+	if h.enc == nil {
+		enc, err := h.newEncoder(h.rate, h.chans, h.sfmt)
+		if err != nil {
+			return 0, err
+		}
+		h.enc = enc
+		h.mu.Lock()
+		h.header = enc.Header()
+		h.mu.Unlock()
+		// Clients in the middle of the previous stream
+		// get new one chained.
+		h.broadcast(enc.Header())
+	}
+	data, err := h.enc.Write(buf[:frames*fsz])
+	if err != nil {
+		return 0, err
+	}
+	h.broadcast(data)
+
+	if h.frames == 0 {
+		h.start = time.Now()
+	}
+	h.frames += int64(frames)
+	d := time.Duration(h.frames) * time.Second / time.Duration(h.rate)
+	time.Sleep(time.Until(h.start.Add(d)))
+
+	return frames * fsz, nil
+}
+
+func (h *Httpd) Flush() error {
+	h.frames = 0
+
+	return nil
+}
+
+func (h *Httpd) Pause() error {
+	h.frames = 0
+
+	return nil
+}
+
+func (h *Httpd) Close() error {
+	h.open = false
+
+	return h.finish()
+}
+
+// Volume returns volume level. Volume level does not affect
+// the stream.
+func (h *Httpd) Volume() (int, error) {
+	return h.volume, nil
+}
+
+func (h *Httpd) SetVolume(vol int) error {
+	h.volume = vol
+
+	return nil
+}
+
+// ServeHTTP sends the stream to the client until it disconnects
+// or falls behind.
+func (h *Httpd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	icy := r.Header.Get("Icy-MetaData") == "1"
+
+	w.Header().Set("Content-Type", h.contentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("icy-name", "Chub")
+	if icy {
+		w.Header().Set("icy-metaint", strconv.Itoa(icyMetaInt))
+	}
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return
+	}
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	c := &client{data: make(chan []byte, clientQueueLen)}
+	h.mu.Lock()
+	header := h.header
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+	defer h.remove(c)
+
+	var out io.Writer = w
+	if icy {
+		out = &icyWriter{w: w, left: icyMetaInt, titleFn: h.streamTitle}
+	}
+	send := func(data []byte) bool {
+		_, err := out.Write(data)
+		if err != nil {
+			return false
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return true
+	}
+
+	if len(header) > 0 && !send(header) {
+		return
+	}
+	for {
+		select {
+		case data, ok := <-c.data:
+			if !ok || !send(data) {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// finish completes current stream if any.
+func (h *Httpd) finish() error {
+	if h.enc == nil {
+		return nil
+	}
+	data, err := h.enc.Close()
+	h.enc = nil
+	h.broadcast(data)
+
+	return err
+}
+
+// broadcast queues data for sending to all the clients.
+func (h *Httpd) broadcast(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c := range h.clients {
+		select {
+		case c.data <- data:
+		default:
+			// Client is too slow.
+			delete(h.clients, c)
+			close(c.data)
+		}
+	}
+}
+
+func (h *Httpd) remove(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[c]; ok {
+		delete(h.clients, c)
+		close(c.data)
+	}
+}
+
+func (h *Httpd) streamTitle() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.title
+}
+
+// streamTitle returns title to show to the clients for the track.
+func streamTitle(t *vfs.Track) string {
+	if t.Tag != nil && t.Tag.Title != "" {
+		if t.Tag.Artist != "" {
+			return t.Tag.Artist + " - " + t.Tag.Title
+		}
+		return t.Tag.Title
+	}
+
+	return strings.TrimSuffix(t.Path.Base(), filepath.Ext(t.Path.Base()))
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package httpd
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/vchimishuk/chub/assert"
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/output"
+	"github.com/vchimishuk/chub/vfs"
+	vconfig "github.com/vchimishuk/config"
+)
+
+// testEncoder passes PCM data as is.
+type testEncoder struct{}
+
+func (e *testEncoder) Header() []byte {
+	return []byte("HDR")
+}
+
+func (e *testEncoder) Write(buf []byte) ([]byte, error) {
+	return append([]byte{}, buf...), nil
+}
+
+func (e *testEncoder) Close() ([]byte, error) {
+	return []byte("END"), nil
+}
+
+func newTestEncoder(rate int, chans int,
+	sfmt format.SampleFormat) (Encoder, error) {
+
+	return &testEncoder{}, nil
+}
+
+func connect(t *testing.T, h *Httpd, icy bool) *http.Response {
+	req, err := http.NewRequest("GET", "http://"+h.ln.Addr().String(), nil)
+	assert.Nil(t, err)
+	if icy {
+		req.Header.Set("Icy-MetaData", "1")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+
+	// Wait for the client to be registered.
+	for i := 0; i < 100; i++ {
+		h.mu.Lock()
+		n := len(h.clients)
+		h.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return resp
+}
+
+func TestStream(t *testing.T) {
+	h := New("127.0.0.1:0", "audio/test", newTestEncoder)
+	assert.Nil(t, h.Open())
+	resp := connect(t, h, false)
+	defer resp.Body.Close()
+	assert.True(t, resp.Header.Get("Content-Type") == "audio/test")
+
+	n, err := h.Write([]byte{1, 2, 3, 4})
+	assert.Nil(t, err)
+	assert.True(t, n == 4)
+	assert.Nil(t, h.Close())
+
+	buf := make([]byte, 10)
+	_, err = io.ReadFull(resp.Body, buf)
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(buf, []byte("HDR\x01\x02\x03\x04END")))
+}
+
+func TestIcy(t *testing.T) {
+	h := New("127.0.0.1:0", "audio/test", newTestEncoder)
+	assert.Nil(t, h.Open())
+	defer h.Close()
+	p, err := vfs.NewPath("/artist/song.flac")
+	assert.Nil(t, err)
+	assert.Nil(t, h.SetTrack(&vfs.Track{Path: p}))
+	resp := connect(t, h, true)
+	defer resp.Body.Close()
+	assert.True(t, resp.Header.Get("icy-metaint") == "16000")
+
+	assert.Nil(t, h.SetSampleRate(400000))
+	_, err = h.Write(make([]byte, icyMetaInt))
+	assert.Nil(t, err)
+
+	// Stream header is counted as stream data too.
+	buf := make([]byte, icyMetaInt+1+32)
+	_, err = io.ReadFull(resp.Body, buf)
+	assert.Nil(t, err)
+	meta := buf[icyMetaInt:]
+	assert.True(t, meta[0] == 2)
+	assert.True(t, string(meta[1:19]) == "StreamTitle='song'")
+}
+
+func TestIcyMeta(t *testing.T) {
+	assert.True(t, bytes.Equal(icyMeta(""), []byte{0}))
+	m := icyMeta("a")
+	assert.True(t, len(m) == 17)
+	assert.True(t, m[0] == 1)
+	assert.True(t, string(m[1:17]) == "StreamTitle='a';")
+}
+
+func TestClosed(t *testing.T) {
+	h := New("127.0.0.1:0", "audio/test", newTestEncoder)
+	_, err := h.Write(make([]byte, 4))
+	assert.Error(t, err, "output is closed")
+}
+
+func TestConfig(t *testing.T) {
+	RegisterEncoder("test", "audio/test",
+		func(bitrate int, rate int, chans int,
+			sfmt format.SampleFormat) (Encoder, error) {
+
+			return &testEncoder{}, nil
+		})
+	spec := &vconfig.Spec{Properties: output.Properties()}
+	c, err := vconfig.Parse(spec, `httpd-addr = "127.0.0.1:0"
+httpd-codec = "test"
+httpd-bitrate = 128`)
+	assert.Nil(t, err)
+	o, err := output.New("httpd", c)
+	assert.Nil(t, err)
+	assert.True(t, o.(*Httpd).contentType == "audio/test")
+
+	_, err = vconfig.Parse(spec, `httpd-codec = "aac"`)
+	assert.Error(t, err, "1: unsupported value")
+	_, err = vconfig.Parse(spec, `httpd-bitrate = 1000`)
+	assert.Error(t, err, "1: value out of range")
+}
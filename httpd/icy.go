@@ -0,0 +1,95 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package httpd
+
+import (
+	"io"
+	"strings"
+)
+
+// Maximum size of ICY metadata block.
+const icyMaxMetaSize = 255 * 16
+
+// icyWriter interleaves stream data with ICY (SHOUTcast) metadata
+// blocks. Metadata block is inserted after every icyMetaInt bytes
+// of the stream.
+type icyWriter struct {
+	w io.Writer
+	// Number of stream bytes left before the next metadata block.
+	left int
+	// Title sent to the client last time.
+	title string
+	// Function returning current stream title.
+	titleFn func() string
+}
+
+func (w *icyWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		k := min(len(p), w.left)
+		m, err := w.w.Write(p[:k])
+		n += m
+		if err != nil {
+			return n, err
+		}
+		p = p[k:]
+		w.left -= k
+
+		if w.left == 0 {
+			// Send metadata only when it changes, empty block otherwise.
+			var meta []byte
+			t := w.titleFn()
+			if t != w.title {
+				meta = icyMeta(t)
+				w.title = t
+			} else {
+				meta = icyMeta("")
+			}
+			_, err := w.w.Write(meta)
+			if err != nil {
+				return n, err
+			}
+			w.left = icyMetaInt
+		}
+	}
+
+	return n, nil
+}
+
+// icyMeta returns ICY metadata block with the stream title. The first
+// byte of the block is its length divided by 16. Empty title produces
+// empty block.
+func icyMeta(title string) []byte {
+	if title == "" {
+		return []byte{0}
+	}
+
+	const prefix = "StreamTitle='"
+	const suffix = "';"
+	if len(prefix)+len(title)+len(suffix) > icyMaxMetaSize {
+		title = strings.ToValidUTF8(
+			title[:icyMaxMetaSize-len(prefix)-len(suffix)], "")
+	}
+	s := prefix + title + suffix
+	n := (len(s) + 15) / 16
+	b := make([]byte, 1+n*16)
+	b[0] = byte(n)
+	copy(b[1:], s)
+
+	return b
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package httpd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vchimishuk/chub/config"
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/output"
+	"github.com/vchimishuk/chub/player"
+	vconfig "github.com/vchimishuk/config"
+)
+
+// CodecEncoderFunc creates encoder for the PCM stream with given
+// parameters. bitrate is in bits per second.
+type CodecEncoderFunc func(bitrate int, rate int, channels int,
+	sfmt format.SampleFormat) (Encoder, error)
+
+type codec struct {
+	contentType string
+	newEncoder  CodecEncoderFunc
+}
+
+// Available encoders by codec name.
+var codecs = map[string]*codec{}
+
+// RegisterEncoder makes codec available for streaming. contentType
+// is MIME type of the stream produced by the encoder.
+func RegisterEncoder(name string, contentType string, f CodecEncoderFunc) {
+	codecs[name] = &codec{contentType: contentType, newEncoder: f}
+}
+
+func parseCodec(v any) (any, error) {
+	s := v.(string)
+	if _, ok := codecs[s]; !ok {
+		return nil, errors.New("unsupported value")
+	}
+
+	return s, nil
+}
+
+func init() {
+	output.Register("httpd", []*vconfig.PropertySpec{
+		&vconfig.PropertySpec{
+			Type: vconfig.TypeString,
+			Name: "httpd-addr",
+		},
+		&vconfig.PropertySpec{
+			Type:   vconfig.TypeString,
+			Name:   "httpd-codec",
+			Parser: parseCodec,
+		},
+		&vconfig.PropertySpec{
+			Type:   vconfig.TypeInt,
+			Name:   "httpd-bitrate",
+			Parser: config.ParseRange(32, 512),
+		},
+	}, func(c *vconfig.Config) (player.Output, error) {
+		name := c.StringOr("httpd-codec", "vorbis")
+		cd, ok := codecs[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported codec: %s", name)
+		}
+		bitrate := c.IntOr("httpd-bitrate", 192) * 1000
+		enc := func(rate int, channels int,
+			sfmt format.SampleFormat) (Encoder, error) {
+
+			return cd.newEncoder(bitrate, rate, channels, sfmt)
+		}
+
+		return New(c.StringOr("httpd-addr", ":8000"),
+			cd.contentType, enc), nil
+	})
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+// Package lyrics implements song lyrics parser. Both plain text and
+// synchronized LRC formats are supported.
+// For LRC format description see: https://en.wikipedia.org/wiki/LRC_(file_format)
+package lyrics
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Line is a single line of lyrics.
+type Line struct {
+	// Time the line starts to be sung at, in milliseconds.
+	// Always zero for unsynchronized lyrics.
+	Time int
+	Text string
+}
+
+// Lyrics of a song.
+type Lyrics struct {
+	// Synced is true if lines have time stamps.
+	Synced bool
+	// Lines sorted by time.
+	Lines []Line
+}
+
+// Parse parses lyrics in LRC or plain text format.
+// Nil is returned if there is no text in s.
+func Parse(s string) *Lyrics {
+	s = strings.TrimPrefix(s, "\ufeff")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+
+	var synced, plain []Line
+	offset := 0
+	for _, l := range strings.Split(s, "\n") {
+		l = strings.TrimSpace(l)
+		var times []int
+		tag := false
+		for strings.HasPrefix(l, "[") {
+			i := strings.IndexByte(l, ']')
+			if i < 0 {
+				break
+			}
+			if t, ok := parseTime(l[1:i]); ok {
+				times = append(times, t)
+			} else if k, v, ok := parseTag(l[1:i]); ok && len(times) == 0 {
+				if k == "offset" {
+					offset, _ = strconv.Atoi(strings.TrimPrefix(v, "+"))
+				}
+				tag = true
+			} else {
+				// Plain text in brackets, e.g. [Chorus].
+				break
+			}
+			l = strings.TrimSpace(l[i+1:])
+		}
+
+		if len(times) > 0 {
+			text := stripWordTimes(l)
+			for _, t := range times {
+				synced = append(synced, Line{Time: t, Text: text})
+			}
+		} else if !tag {
+			plain = append(plain, Line{Text: l})
+		}
+	}
+
+	if len(synced) > 0 {
+		// Positive offset means lyrics come up sooner.
+		for i := range synced {
+			synced[i].Time = max(0, synced[i].Time-offset)
+		}
+		sort.SliceStable(synced, func(i, j int) bool {
+			return synced[i].Time < synced[j].Time
+		})
+		return &Lyrics{Synced: true, Lines: synced}
+	}
+
+	// Trim empty lines around the text.
+	for len(plain) > 0 && plain[0].Text == "" {
+		plain = plain[1:]
+	}
+	for len(plain) > 0 && plain[len(plain)-1].Text == "" {
+		plain = plain[:len(plain)-1]
+	}
+	if len(plain) == 0 {
+		return nil
+	}
+
+	return &Lyrics{Lines: plain}
+}
+
+// LineAt returns index of the line being sung at the given position
+// in milliseconds, or -1 if position is before the first line.
+func (l *Lyrics) LineAt(pos int) int {
+	return sort.Search(len(l.Lines), func(i int) bool {
+		return l.Lines[i].Time > pos
+	}) - 1
+}
+
+// Slice returns synchronized lyrics of the part of the song from start
+// till end, both in milliseconds. Times are made relative to the part
+// start. Nil is returned if there are no lines in the part.
+func (l *Lyrics) Slice(start int, end int) *Lyrics {
+	var lines []Line
+	for _, ln := range l.Lines {
+		if ln.Time >= start && ln.Time < end {
+			lines = append(lines, Line{Time: ln.Time - start, Text: ln.Text})
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return &Lyrics{Synced: l.Synced, Lines: lines}
+}
+
+// String returns lyrics in LRC format if they are synchronized
+// or plain text otherwise.
+func (l *Lyrics) String() string {
+	var b strings.Builder
+	for _, ln := range l.Lines {
+		if l.Synced {
+			fmt.Fprintf(&b, "[%02d:%02d.%02d]", ln.Time/60000,
+				ln.Time/1000%60, ln.Time%1000/10)
+		}
+		b.WriteString(ln.Text)
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// parseTime parses time stamp in mm:ss, mm:ss.xx or mm:ss.xxx format.
+func parseTime(s string) (int, bool) {
+	m, s, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, false
+	}
+	sec, frac, _ := strings.Cut(s, ".")
+	mm, err := strconv.Atoi(m)
+	if err != nil || mm < 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(sec)
+	if err != nil || n < 0 || n > 59 {
+		return 0, false
+	}
+	ms := 0
+	if frac != "" {
+		if len(frac) > 3 {
+			return 0, false
+		}
+		ms, err = strconv.Atoi(frac)
+		if err != nil || ms < 0 {
+			return 0, false
+		}
+		for i := len(frac); i < 3; i++ {
+			ms *= 10
+		}
+	}
+
+	return (mm*60+n)*1000 + ms, true
+}
+
+// parseTag parses ID tag like "ar:Artist".
+func parseTag(s string) (string, string, bool) {
+	k, v, ok := strings.Cut(s, ":")
+	if !ok || k == "" {
+		return "", "", false
+	}
+	for _, c := range k {
+		if c < 'a' || c > 'z' {
+			return "", "", false
+		}
+	}
+
+	return k, strings.TrimSpace(v), true
+}
+
+// stripWordTimes removes word time stamps of the enhanced LRC format,
+// e.g. "<00:12.34>word".
+func stripWordTimes(s string) string {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(s[i:], '>')
+		if j < 0 {
+			break
+		}
+		if _, ok := parseTime(s[i+1 : i+j]); !ok {
+			b.WriteString(s[:i+j+1])
+			s = s[i+j+1:]
+			continue
+		}
+		b.WriteString(s[:i])
+		s = s[i+j+1:]
+	}
+	b.WriteString(s)
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
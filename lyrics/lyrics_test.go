@@ -0,0 +1,61 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package lyrics
+
+import (
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+)
+
+func TestParseSynced(t *testing.T) {
+	l := Parse("\ufeff[ar:Artist]\r\n" +
+		"[offset:+100]\r\n" +
+		"[00:12.5][01:02.30]Chorus <00:13.00>line\r\n" +
+		"[00:01.123]First\r\n" +
+		"[Not a tag]\r\n")
+	assert.True(t, l.Synced)
+	assert.True(t, len(l.Lines) == 3)
+	assert.True(t, l.Lines[0] == Line{Time: 1023, Text: "First"})
+	assert.True(t, l.Lines[1] == Line{Time: 12400, Text: "Chorus line"})
+	assert.True(t, l.Lines[2] == Line{Time: 62200, Text: "Chorus line"})
+
+	assert.True(t, l.LineAt(0) == -1)
+	assert.True(t, l.LineAt(1023) == 0)
+	assert.True(t, l.LineAt(20000) == 1)
+	assert.True(t, l.LineAt(100000) == 2)
+
+	p := l.Slice(10000, 60000)
+	assert.True(t, len(p.Lines) == 1)
+	assert.True(t, p.Lines[0].Time == 2400)
+	assert.True(t, l.Slice(70000, 80000) == nil)
+
+	assert.True(t, l.String() == "[00:01.02]First\n"+
+		"[00:12.40]Chorus line\n[01:02.20]Chorus line\n")
+}
+
+func TestParsePlain(t *testing.T) {
+	l := Parse("\n[Verse]\nFirst line\n\nSecond line\n\n")
+	assert.True(t, !l.Synced)
+	assert.True(t, len(l.Lines) == 4)
+	assert.True(t, l.Lines[0].Text == "[Verse]")
+	assert.True(t, l.Lines[2].Text == "")
+	assert.True(t, l.String() == "[Verse]\nFirst line\n\nSecond line\n")
+
+	assert.True(t, Parse(" \n\n") == nil)
+}
@@ -20,23 +20,36 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/signal"
 	"os/user"
 	"path/filepath"
+	"slices"
 	"strings"
+	"syscall"
 
 	vconfig "github.com/vchimishuk/config"
 
-	"github.com/vchimishuk/chub/alsa"
 	"github.com/vchimishuk/chub/config"
+	"github.com/vchimishuk/chub/cue"
 	"github.com/vchimishuk/chub/format"
 	"github.com/vchimishuk/chub/format/ffmpeg"
+	"github.com/vchimishuk/chub/format/wav"
+	"github.com/vchimishuk/chub/httpd"
 	"github.com/vchimishuk/chub/logger"
-	"github.com/vchimishuk/chub/oss"
+	"github.com/vchimishuk/chub/network"
+	"github.com/vchimishuk/chub/output"
 	"github.com/vchimishuk/chub/player"
 	"github.com/vchimishuk/chub/server"
 	"github.com/vchimishuk/chub/vfs"
 	"github.com/vchimishuk/chub/vfs/db"
 	"github.com/vchimishuk/opt"
+
+	// Output drivers.
+	_ "github.com/vchimishuk/chub/alsa"
+	_ "github.com/vchimishuk/chub/null"
+	_ "github.com/vchimishuk/chub/oss"
+	_ "github.com/vchimishuk/chub/pipe"
+	_ "github.com/vchimishuk/chub/recorder"
 )
 
 const (
@@ -177,6 +190,101 @@ func walkPath(p *vfs.Path) error {
 	return nil
 }
 
+// fallbackFormats contains optional formats built with the corresponding
+// build tags, which are used when FFmpeg fails to handle the file.
+var fallbackFormats = map[string]format.Format{}
+
+// registerFormats registers supported audio formats. FFmpeg decodes
+// everything it can, except WAV and AIFF files decoded natively,
+// unless another format is chosen for the extension in formats block.
+// Other formats are used as a fallback when FFmpeg fails.
+func registerFormats(cfg *vconfig.Config) {
+	add := strings.Fields(cfg.StringOr("ffmpeg-add-extensions", ""))
+	remove := strings.Fields(cfg.StringOr("ffmpeg-remove-extensions", ""))
+	byName := map[string]format.Format{
+		"ffmpeg": ffmpeg.NewFormat(add, remove),
+		"wav":    wav.NewFormat(),
+	}
+	format.Register(byName["ffmpeg"], format.PriorityNormal)
+	format.Register(byName["wav"], format.PriorityHigh)
+	for name, f := range fallbackFormats {
+		byName[name] = f
+		format.Register(f, format.PriorityLow)
+	}
+
+	for ext, name := range config.Formats(cfg) {
+		f, ok := byName[name]
+		if !ok {
+			fatal("%s format is not built in", name)
+		}
+		if !slices.Contains(f.Extensions(), ext) {
+			fatal("%s format does not support %s files", name, ext)
+		}
+		format.Register(format.WithExtensions(f, ext),
+			format.PriorityUser)
+	}
+}
+
+// registerEncoders makes FFmpeg encoders available for httpd output
+// driver. Codec names are checked when configuration is parsed,
+// so encoders have to be registered before that.
+func registerEncoders() {
+	for name, contentType := range ffmpeg.Encoders() {
+		codec := name
+		httpd.RegisterEncoder(codec, contentType,
+			func(bitrate int, rate int, channels int,
+				sfmt format.SampleFormat) (httpd.Encoder, error) {
+
+				e, err := ffmpeg.NewEncoder(codec, bitrate, rate,
+					channels, sfmt)
+				if err != nil {
+					return nil, err
+				}
+				return e, nil
+			})
+	}
+}
+
+// newOutputs creates output drivers listed in the configuration.
+func newOutputs(cfg *vconfig.Config) *player.Outputs {
+	outputs := player.NewOutputs()
+	for _, name := range config.Outputs(cfg) {
+		o, err := output.New(name, cfg)
+		if err == nil {
+			err = outputs.Add(name, o)
+		}
+		if err != nil {
+			fatal("failed to add %s output: %s", name, err)
+		}
+	}
+
+	return outputs
+}
+
+// receive plays the stream of remote Chub instance running network
+// output driver until terminated by a signal.
+func receive(cfg *vconfig.Config, state *config.State) {
+	addr := cfg.StringOr("network-server", "")
+	if addr == "" {
+		fatal("network-server is required for network input")
+	}
+	outputs := newOutputs(cfg)
+	err := outputs.SetVolume(state.Volume)
+	if err != nil {
+		fatal("failed to set volume: %s", err)
+	}
+
+	r := network.NewReceiver(addr, outputs)
+	r.Start()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	<-sig
+	err = r.Close()
+	if err != nil {
+		logger.Error("failed to close network input: %s", err)
+	}
+}
+
 func main() {
 	opts, args, err := opt.Parse(os.Args[1:], OptDescs)
 	if err != nil {
@@ -194,6 +302,7 @@ func main() {
 		os.Exit(1)
 	}
 
+	registerEncoders()
 	cfg, err := parseConfig(opts.StringOr("config", ""))
 	if err != nil {
 		fatal("%s", err)
@@ -213,8 +322,15 @@ func main() {
 		}
 	}
 
-	ffmpegFmt := ffmpeg.NewFormat()
-	format.Register(ffmpegFmt)
+	if cfg.StringOr("input", "local") == "network" &&
+		!opts.Has("init-db") {
+		// Remote instance decodes the tracks, so there is
+		// no need for the library and player.
+		receive(cfg, state)
+		return
+	}
+
+	registerFormats(cfg)
 
 	dbFile, err := expandPath(DefaultDbFile)
 	if err != nil {
@@ -225,6 +341,12 @@ func main() {
 		fatal("cannot open metadata database: %s", err)
 	}
 
+	err = cue.SetFallbackEncoding(cfg.StringOr("cue-encoding",
+		"windows-1252"))
+	if err != nil {
+		panic(err)
+	}
+
 	root := cfg.StringOr("vfs-root", "/")
 	err = vfs.SetRoot(root)
 	if err != nil {
@@ -239,21 +361,30 @@ func main() {
 			logger.Error("failed to read path: %s", err)
 		}
 	} else {
-		var output player.Output
-		switch cfg.StringOr("output", "alsa") {
-		case "alsa":
-			output = alsa.New()
-		case "oss":
-			output = oss.New()
-		default:
-			panic("unsupported output")
-		}
-
-		p := player.New([]format.Format{ffmpegFmt}, output)
+		outputs := newOutputs(cfg)
+		p := player.New(outputs)
 		err = p.SetVolume(state.Volume, false)
 		if err != nil {
 			fatal("failed to set volume: %s", err)
 		}
+		err = p.SetOutputRate(cfg.IntOr("output-rate", 0))
+		if err != nil {
+			fatal("failed to set output rate: %s", err)
+		}
+		err = p.SetEqPresets(config.EqPresets(cfg))
+		if err != nil {
+			fatal("invalid equalizer preset: %s", err)
+		}
+		err = p.SetEq(state.Eq, state.EqGains)
+		if err != nil {
+			logger.Error("failed to restore equalizer: %s", err)
+		}
+		err = p.SetBalance(state.Balance)
+		if err != nil {
+			logger.Error("failed to restore balance: %s", err)
+		}
+		p.SetMono(state.Mono)
+		p.SetSwapChannels(state.SwapChannels)
 
 		s := server.New(p)
 		err = s.Listen(cfg.StringOr("server-host", "0.0.0.0"),
@@ -264,6 +395,11 @@ func main() {
 		s.Serve()
 
 		state.Volume = p.Volume()
+		state.Eq = p.EqEnabled()
+		state.EqGains = p.EqGains()
+		state.Balance = p.Balance()
+		state.Mono = p.Mono()
+		state.SwapChannels = p.SwapChannels()
 		err = saveState(stateFile, state)
 		if err != nil {
 			logger.Error("failed to save state: %s", err)
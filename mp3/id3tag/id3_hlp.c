@@ -16,6 +16,7 @@
 // along with Chub.  If not, see <http://www.gnu.org/licenses/>.
 
 #include <stdlib.h>
+#include <string.h>
 #include "id3_hlp.h"
 
 
@@ -41,14 +42,116 @@ enum id3_field_type id3_hlp_get_frame_type(struct id3_frame *frame)
 
 char *id3_hlp_get_frame_string(struct id3_frame *frame)
 {
-    char *str;
+    char *str = NULL;
 
-    if (id3_field_getnstrings(&frame->fields[1]) != 0) {
-        str = (char *) id3_field_getstrings(&frame->fields[1], 0);
-        if (str != NULL) {
-            str = id3_ucs4_utf8duplicate((id3_ucs4_t const *) str);
+    if (strcmp(frame->id, ID3_FRAME_COMMENT) == 0) {
+        return id3_hlp_get_comment_string(frame);
+    }
+    if (strcmp(frame->id, "USLT") == 0) {
+        return id3_hlp_get_full_string(frame);
+    }
+
+    if (frame->nfields > 1
+            && id3_field_getnstrings(&frame->fields[1]) != 0) {
+        id3_ucs4_t const *ucs = id3_field_getstrings(&frame->fields[1], 0);
+        if (ucs != NULL && strcmp(frame->id, ID3_FRAME_GENRE) == 0) {
+            ucs = id3_genre_name(ucs);
+        }
+        if (ucs != NULL) {
+            str = (char *) id3_ucs4_utf8duplicate(ucs);
         }
     }
 
     return str;
 }
+
+char *id3_hlp_get_comment_string(struct id3_frame *frame)
+{
+    id3_ucs4_t const *desc;
+
+    // Fields are: text encoding, language, description, text.
+    if (frame->nfields < 4) {
+        return NULL;
+    }
+    desc = id3_field_getstring(&frame->fields[2]);
+    if (desc != NULL && desc[0] != 0) {
+        return NULL;
+    }
+
+    return id3_hlp_get_full_string(frame);
+}
+
+char *id3_hlp_get_full_string(struct id3_frame *frame)
+{
+    id3_ucs4_t const *ucs;
+
+    // Fields are: text encoding, language, description, text.
+    if (frame->nfields < 4) {
+        return NULL;
+    }
+    ucs = id3_field_getfullstring(&frame->fields[3]);
+    if (ucs == NULL) {
+        return NULL;
+    }
+
+    return (char *) id3_ucs4_utf8duplicate(ucs);
+}
+
+id3_byte_t const *id3_hlp_get_picture(struct id3_tag *tag, id3_length_t *len)
+{
+    id3_byte_t const *data = NULL;
+    struct id3_frame *frame;
+    unsigned int i = 0;
+
+    // Fields are: text encoding, MIME type, picture type,
+    // description, picture data.
+    while ((frame = id3_tag_findframe(tag, "APIC", i++)) != NULL) {
+        id3_length_t n;
+        id3_byte_t const *d;
+        int front;
+
+        if (frame->nfields < 5) {
+            continue;
+        }
+        d = id3_field_getbinarydata(&frame->fields[4], &n);
+        if (d == NULL || n == 0) {
+            continue;
+        }
+        front = id3_field_getint(&frame->fields[2]) == 3;
+        if (data == NULL || front) {
+            data = d;
+            *len = n;
+        }
+        if (front) {
+            break;
+        }
+    }
+
+    return data;
+}
+
+id3_byte_t const *id3_hlp_get_synced_lyrics(struct id3_tag *tag, int *enc,
+    id3_length_t *len)
+{
+    struct id3_frame *frame;
+    unsigned int i = 0;
+
+    // Fields are: text encoding, language, time stamp format,
+    // content type, description, synchronised text.
+    while ((frame = id3_tag_findframe(tag, "SYLT", i++)) != NULL) {
+        id3_byte_t const *data;
+
+        // Only time stamps in milliseconds are supported.
+        if (frame->nfields < 6
+            || id3_field_getint(&frame->fields[2]) != 2) {
+            continue;
+        }
+        data = id3_field_getbinarydata(&frame->fields[5], len);
+        if (data != NULL && *len > 0) {
+            *enc = id3_field_gettextencoding(&frame->fields[0]);
+            return data;
+        }
+    }
+
+    return NULL;
+}
@@ -37,8 +37,38 @@ char *id3_hlp_get_frame_id(struct id3_frame *frame);
 enum id3_field_type id3_hlp_get_frame_type(struct id3_frame *frame);
 
 /*
- * Returns frames string value.
+ * Returns frames string value or NULL if frame has no string.
+ * Returned string must be freed by caller.
  */
 char *id3_hlp_get_frame_string(struct id3_frame *frame);
 
+/*
+ * Returns text of the comment frame or NULL if the frame has
+ * a non-empty description, e.g. iTunes service comments.
+ * Returned string must be freed by caller.
+ */
+char *id3_hlp_get_comment_string(struct id3_frame *frame);
+
+/*
+ * Returns text of the frame consisting of text encoding, language,
+ * description and text fields, e.g. USLT.
+ * Returned string must be freed by caller.
+ */
+char *id3_hlp_get_full_string(struct id3_frame *frame);
+
+/*
+ * Returns image data of the front cover or of the first picture found
+ * if there is no front cover. Returns NULL if tag has no pictures.
+ * Returned data is owned by the tag.
+ */
+id3_byte_t const *id3_hlp_get_picture(struct id3_tag *tag, id3_length_t *len);
+
+/*
+ * Returns raw data of the first synchronised lyrics frame with
+ * time stamps in milliseconds and its text encoding. Returns NULL
+ * if there is no such frame. Returned data is owned by the tag.
+ */
+id3_byte_t const *id3_hlp_get_synced_lyrics(struct id3_tag *tag, int *enc,
+    id3_length_t *len);
+
 #endif // ID3_HLP_H
@@ -15,6 +15,8 @@
 // You should have received a copy of the GNU General Public License
 // along with Chub. If not, see <http://www.gnu.org/licenses/>.
 
+//go:build mp3
+
 // id3tag package implements ID3 tag parsing functionality.
 // Actually this is a wrapper around C libid3tag.
 package id3tag
@@ -25,11 +27,16 @@ package id3tag
 // #include "id3_hlp.h"
 import "C"
 
-import "unsafe"
+import (
+	"errors"
+	"unsafe"
+)
 
 // Tag struct incapulate parsed file's metadata.
 type Tag struct {
 	frames map[string]string
+	// Synchronised lyrics in LRC format.
+	syncedLyrics string
 }
 
 // Parse returns filled Tag object for the given music file.
@@ -39,18 +46,18 @@ func Parse(filename string) (tag Tag, err error) {
 
 	// Open file.
 	cId3File, err := C.id3_file_open(cFilename, C.ID3_FILE_MODE_READONLY)
-	if err != nil {
+	if cId3File == nil {
+		if err == nil {
+			err = errors.New("failed to open file")
+		}
 		return Tag{}, err
 	}
-	// TODO: Can't find out why we have segfault sometimes on this close.
-	//       But it seems that without this close file descriptors do not
-	//       leak. So, we can live without it.
-	// defer C.id3_file_close(cId3File)
-
-	// Read tag.
-	cTag, err := C.id3_file_tag(cId3File)
-	if err != nil {
-		return Tag{}, err
+	defer C.id3_file_close(cId3File)
+
+	// Read tag. It is owned by the file and freed on close.
+	cTag := C.id3_file_tag(cId3File)
+	if cTag == nil {
+		return Tag{}, errors.New("failed to read tag")
 	}
 
 	tag = Tag{frames: make(map[string]string)}
@@ -58,14 +65,24 @@ func Parse(filename string) (tag Tag, err error) {
 	// Parse all frames.
 	for i := C.uint(0); i < cTag.nframes; i++ {
 		cFrame := C.id3_hlp_get_tag_frame(cTag, i)
-		cId := C.id3_hlp_get_frame_id(cFrame)
-		// XXX: As I understood cId memory will be GCed with id
-		//      (they share same memory).
-		id := C.GoString(cId)
+		id := C.GoString(C.id3_hlp_get_frame_id(cFrame))
 		cVal := C.id3_hlp_get_frame_string(cFrame)
-		val := C.GoString(cVal)
+		if cVal == nil {
+			continue
+		}
+		// Keep the first frame if there are several ones with the same ID.
+		if _, ok := tag.frames[id]; !ok {
+			tag.frames[id] = C.GoString(cVal)
+		}
+		C.free(unsafe.Pointer(cVal))
+	}
 
-		tag.frames[id] = val
+	var enc C.int
+	var n C.id3_length_t
+	data := C.id3_hlp_get_synced_lyrics(cTag, &enc, &n)
+	if data != nil {
+		b := C.GoBytes(unsafe.Pointer(data), C.int(n))
+		tag.syncedLyrics = syncedLyrics(b, int(enc))
 	}
 
 	return tag, nil
@@ -86,11 +103,46 @@ func (tag Tag) Title() string {
 	return tag.frames["TIT2"]
 }
 
+// AlbumArtist returns name of the album's artist.
+func (tag Tag) AlbumArtist() string {
+	return tag.frames["TPE2"]
+}
+
 // Number returns track's number.
 func (tag Tag) Number() string {
 	return tag.frames["TRCK"]
 }
 
+// Disc returns disc number.
+func (tag Tag) Disc() string {
+	return tag.frames["TPOS"]
+}
+
+// Genre returns track's genre.
+func (tag Tag) Genre() string {
+	return tag.frames["TCON"]
+}
+
+// Composer returns name of the composer.
+func (tag Tag) Composer() string {
+	return tag.frames["TCOM"]
+}
+
+// Comment returns track's comment string.
+func (tag Tag) Comment() string {
+	return tag.frames["COMM"]
+}
+
+// Lyrics returns unsynchronised lyrics.
+func (tag Tag) Lyrics() string {
+	return tag.frames["USLT"]
+}
+
+// SyncedLyrics returns synchronised lyrics in LRC format.
+func (tag Tag) SyncedLyrics() string {
+	return tag.syncedLyrics
+}
+
 // Year returns track's year.
 func (tag Tag) Year() string {
 	year, present := tag.frames["TDRC"]
@@ -101,8 +153,30 @@ func (tag Tag) Year() string {
 	return year
 }
 
-// Comment returns track's comment string.
-//func (tag *Tag) Comment() string {
-//	fmt.Printf("%v\n", tag.frames)
-//	return tag.frames["TCON"]
-//}
+// Picture returns front cover image embedded into the file
+// or nil if there is no one.
+func Picture(filename string) ([]byte, error) {
+	cFilename := C.CString(filename)
+	defer C.free(unsafe.Pointer(cFilename))
+
+	cId3File, err := C.id3_file_open(cFilename, C.ID3_FILE_MODE_READONLY)
+	if cId3File == nil {
+		if err == nil {
+			err = errors.New("failed to open file")
+		}
+		return nil, err
+	}
+	defer C.id3_file_close(cId3File)
+
+	cTag := C.id3_file_tag(cId3File)
+	if cTag == nil {
+		return nil, nil
+	}
+	var n C.id3_length_t
+	data := C.id3_hlp_get_picture(cTag, &n)
+	if data == nil {
+		return nil, nil
+	}
+
+	return C.GoBytes(unsafe.Pointer(data), C.int(n)), nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build mp3
+
+package id3tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/vchimishuk/chub/lyrics"
+)
+
+// ID3 text encodings.
+const (
+	encodingLatin1  = 0
+	encodingUTF16   = 1
+	encodingUTF16BE = 2
+)
+
+// syncedLyrics decodes SYLT frame content, which is a sequence
+// of zero-terminated strings followed by time stamps, into LRC text.
+func syncedLyrics(b []byte, enc int) string {
+	var lines []lyrics.Line
+	for len(b) > 0 {
+		text, rest, ok := splitString(b, enc)
+		if !ok || len(rest) < 4 {
+			break
+		}
+		t := int(binary.BigEndian.Uint32(rest))
+		b = rest[4:]
+		// Line breaks are stored in the beginning of the text.
+		s := strings.TrimLeft(decodeString(text, enc), "\r\n")
+		lines = append(lines, lyrics.Line{Time: t, Text: s})
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return (&lyrics.Lyrics{Synced: true, Lines: lines}).String()
+}
+
+// splitString splits zero-terminated string in the given encoding
+// from the rest of the data.
+func splitString(b []byte, enc int) ([]byte, []byte, bool) {
+	if enc == encodingUTF16 || enc == encodingUTF16BE {
+		for i := 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				return b[:i], b[i+2:], true
+			}
+		}
+		return nil, nil, false
+	}
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return nil, nil, false
+	}
+
+	return b[:i], b[i+1:], true
+}
+
+func decodeString(b []byte, enc int) string {
+	switch enc {
+	case encodingLatin1:
+		r := make([]rune, len(b))
+		for i, c := range b {
+			r[i] = rune(c)
+		}
+		return string(r)
+	case encodingUTF16, encodingUTF16BE:
+		var order binary.ByteOrder = binary.BigEndian
+		if enc == encodingUTF16 && len(b) >= 2 {
+			if b[0] == 0xff && b[1] == 0xfe {
+				order = binary.LittleEndian
+				b = b[2:]
+			} else if b[0] == 0xfe && b[1] == 0xff {
+				b = b[2:]
+			}
+		}
+		u := make([]uint16, len(b)/2)
+		for i := range u {
+			u[i] = order.Uint16(b[2*i:])
+		}
+		return string(utf16.Decode(u))
+	default:
+		return string(b)
+	}
+}
@@ -15,29 +15,57 @@
 // You should have received a copy of the GNU General Public License
 // along with Chub.  If not, see <http://www.gnu.org/licenses/>.
 
+#include <errno.h>
 #include <stdlib.h>
 #include <stdio.h>
 #include <stdint.h>
-#include <limits.h>
 #include <string.h>
+#include <sys/stat.h>
 #include "mp3.h"
 
-#define CLAMP(x, l, h) (((x) > (h)) ? (h) : (((x) < (l)) ? (l) : (x)))
+#define MIN(a, b) ((a) < (b) ? (a) : (b))
+
+/* Number of bytes to look for the first frame in. */
+#define SYNC_SEARCH_SIZE (64 * 1024)
+/* Delay introduced by libmad decoder, in samples. */
+#define DECODER_DELAY 529
+/*
+ * Number of frames decoded before seek position to fill
+ * the bit reservoir.
+ */
+#define SEEK_PRIME_FRAMES 10
+
+/* Xing header flags. */
+#define XING_FRAMES 0x01
+#define XING_BYTES 0x02
+#define XING_TOC 0x04
+#define XING_SCALE 0x08
+
+/* Frame was lost because of decoding error. */
+#define FRAME_LOST 2
+
+static uint32_t be32(const unsigned char *p)
+{
+    return ((uint32_t) p[0] << 24) | (p[1] << 16) | (p[2] << 8) | p[3];
+}
+
+static uint32_t syncsafe(const unsigned char *p)
+{
+    return ((p[0] & 0x7f) << 21) | ((p[1] & 0x7f) << 14)
+        | ((p[2] & 0x7f) << 7) | (p[3] & 0x7f);
+}
 
 /*
  * Fill buffer with new data from input file.
- * Returns size of actual data in the buffer.
+ * Returns size of actual data in the buffer, 0 at the end
+ * of the audio data or negative error code.
  */
-static size_t mp3_fill_buffer(struct mp3_decoder *decoder)
+static int mp3_fill_buffer(struct mp3_decoder *decoder)
 {
-    size_t offset;
-    size_t free_size;
+    size_t offset = 0;
     size_t read_len;
 
-    if (decoder->stream.next_frame == NULL) {
-        /* Place contents at the beginning */
-        offset = 0;
-    } else {
+    if (decoder->stream.next_frame != NULL) {
         /*
          * We still need to save the fragmented frame. Copy it
          * to the beginning and load as much as possible.
@@ -45,249 +73,486 @@ static size_t mp3_fill_buffer(struct mp3_decoder *decoder)
         offset = decoder->stream.bufend - decoder->stream.next_frame;
         memmove(decoder->buf, decoder->stream.next_frame, offset);
     }
+    decoder->buf_offset = decoder->file_offset - offset;
 
-    free_size = BUFFER_SIZE - offset;
-    read_len = fread(decoder->buf + offset, 1, free_size, decoder->file);
-
-    if (read_len < free_size) {
-        if (feof(decoder->file)) {
-            if (read_len == 0) {
-                return 0;
-            }
+    read_len = MIN(BUFFER_SIZE - offset,
+        (size_t) (decoder->audio_end - decoder->file_offset));
+    read_len = fread(decoder->buf + offset, 1, read_len, decoder->file);
+    if (ferror(decoder->file)) {
+        return -EIO;
+    }
+    if (read_len == 0) {
+        return 0;
+    }
+    decoder->file_offset += read_len;
 
-            /*
-             * Place some null bytes at the end
-             * because we may overrun it
-             */
-            memset(decoder->buf + offset + read_len, 0x00000000, MAD_BUFFER_GUARD);
-            read_len += MAD_BUFFER_GUARD;
-        } else if (ferror(decoder->file)) {
-            return -1;
-        }
+    if (decoder->file_offset == decoder->audio_end) {
+        /*
+         * Place some null bytes at the end
+         * because we may overrun it
+         */
+        memset(decoder->buf + offset + read_len, 0, MAD_BUFFER_GUARD);
+        read_len += MAD_BUFFER_GUARD;
     }
 
     return offset + read_len;
 }
 
 /*
- * Decode frame.
- * Returns -1 on error.
+ * Decode next frame or only its header if header_only is set.
+ * Returns 0 on success, 1 at the end of the stream, FRAME_LOST if
+ * frame header was decoded but frame data was not and negative
+ * error code if failed.
  */
-static int mp3_read_frame(struct mp3_decoder *decoder)
+static int mp3_read_frame(struct mp3_decoder *decoder, int header_only)
 {
-    size_t data_size;
+    struct mad_stream *stream = &decoder->stream;
+    int r;
 
     for (; ;) {
-        if (decoder->stream.buffer == NULL || decoder->stream.error == MAD_ERROR_BUFLEN) {
-            data_size = mp3_fill_buffer(decoder);
-            if (data_size <= 0) {
-                return -1;
+        if (stream->buffer == NULL || stream->error == MAD_ERROR_BUFLEN) {
+            r = mp3_fill_buffer(decoder);
+            if (r < 0) {
+                decoder->error = strerror(-r);
+                return r;
+            }
+            if (r == 0) {
+                return 1;
             }
 
-            mad_stream_buffer(&decoder->stream, decoder->buf, data_size);
-            decoder->stream.error = MAD_ERROR_NONE;
+            mad_stream_buffer(stream, decoder->buf, r);
+            stream->error = MAD_ERROR_NONE;
         }
 
-        if (mad_header_decode(&decoder->header, &decoder->stream) == 0) {
-            mad_timer_add(&decoder->timer, decoder->frame.header.duration);
-            decoder->position = decoder->timer.seconds;
-            decoder->frame.header = decoder->header;
+        if (header_only) {
+            r = mad_header_decode(&decoder->frame.header, stream);
+        } else {
+            r = mad_frame_decode(&decoder->frame, stream);
+        }
+        if (r == 0) {
             return 0;
         }
 
-        if (!MAD_RECOVERABLE(decoder->stream.error) && (decoder->stream.error != MAD_ERROR_BUFLEN)) {
-            return -1;
+        if (stream->error == MAD_ERROR_BADDATAPTR) {
+            /* Bit reservoir data is missing, e.g. right after seek. */
+            return FRAME_LOST;
+        }
+        if (!MAD_RECOVERABLE(stream->error)
+                && stream->error != MAD_ERROR_BUFLEN) {
+            decoder->error = mad_stream_errorstr(stream);
+            return MP3_ERR_DECODE;
         }
     }
 }
 
 /*
- * Reinitialize decoder, so file will be decoded from the beginning.
+ * Reinitialize decoder, so file will be decoded from the given offset.
  */
-static void mp3_rewind(struct mp3_decoder *decoder)
+static int mp3_reset(struct mp3_decoder *decoder, off_t offset)
 {
-    rewind(decoder->file);
-
-    decoder->position = 0;
-    decoder->current_sample = 0;
+    if (fseeko(decoder->file, offset, SEEK_SET) != 0) {
+        return -errno;
+    }
+    decoder->file_offset = offset;
+    decoder->buf_offset = offset;
 
+    mad_synth_finish(&decoder->synth);
+    mad_frame_finish(&decoder->frame);
+    mad_stream_finish(&decoder->stream);
     mad_stream_init(&decoder->stream);
     mad_frame_init(&decoder->frame);
-    mad_header_init(&decoder->header);
     mad_synth_init(&decoder->synth);
-    mad_timer_reset(&decoder->timer);
+    decoder->synth.pcm.length = 0;
+    decoder->sample = 0;
+    decoder->eof = 0;
+    decoder->error = NULL;
+
+    return 0;
 }
 
 /*
- * Calculate and returns length of the file in seconds.
+ * Parse Xing (VBR) or Info (CBR) header stored in the first frame
+ * and the LAME extension of it. Returns -1 if there is no header,
+ * 0 if there is no LAME extension and 1 if delay and padding
+ * are read from the extension.
  */
-static void mp3_fill_info(struct mp3_decoder *decoder)
+static int mp3_parse_xing(struct mp3_decoder *decoder, uint64_t *frames,
+    int *delay, int *padding)
 {
-    /*
-     * There are three ways of calculating the length of an mp3:
-     * 1) Constant bitrate: One frame can provide the information
-     *    needed: # of frames and duration. Just see how long it
-     *    is and do the division.
-     * 2) Variable bitrate: Xing tag. It provides the number of
-     *    frames. Each frame has the same number of samples, so
-     *    just use that.
-     * 3) All: Count up the frames and duration of each frame
-     *    by decoding each one. We do this if we've no other
-     *    choice, i.e. if it's a VBR file with no Xing tag.
-     *
-     * See source code of mocp project.
-     */
-
-    /*
-     * TODO: This method can be improved and realized with algorythm
-     *       described above. Now it is done in the same way
-     *       as in herrie audio player.
-     */
-
-    off_t offset;
-    off_t total;
-    int first_frame = 1;
-
-    do {
-        if (mp3_read_frame(decoder) != 0) {
-            decoder->length = decoder->timer.seconds;
-            offset = ftello(decoder->file);
-            mp3_rewind(decoder);
-
-            return;
+    const struct mad_header *header = &decoder->frame.header;
+    const unsigned char *p = decoder->stream.this_frame;
+    const unsigned char *end = decoder->stream.bufend;
+    int mono = header->mode == MAD_MODE_SINGLE_CHANNEL;
+    uint32_t flags;
+
+    if (header->layer != MAD_LAYER_III) {
+        return -1;
+    }
+    /* Header is placed right after side information. */
+    p += 4;
+    if (header->flags & MAD_FLAG_PROTECTION) {
+        p += 2;
+    }
+    if (header->flags & MAD_FLAG_LSF_EXT) {
+        p += mono ? 9 : 17;
+    } else {
+        p += mono ? 17 : 32;
+    }
+    if (end - p < 8 || (memcmp(p, "Xing", 4) != 0
+            && memcmp(p, "Info", 4) != 0)) {
+        return -1;
+    }
+    flags = be32(p + 4);
+    p += 8;
+
+    *frames = 0;
+    if (flags & XING_FRAMES) {
+        if (end - p < 4) {
+            return -1;
         }
+        *frames = be32(p);
+        p += 4;
+    }
+    if (flags & XING_BYTES) {
+        p += 4;
+    }
+    if (flags & XING_TOC) {
+        p += 100;
+    }
+    if (flags & XING_SCALE) {
+        p += 4;
+    }
 
-        if (first_frame) {
-            if (mad_frame_decode(&decoder->frame, &decoder->stream) != -1) {
-                decoder->sample_rate = decoder->frame.header.samplerate;
-                decoder->channels = MAD_NCHANNELS(&decoder->frame.header);
-                first_frame = 0;
-            }
+    /* LAME tag is written by LAME and FFmpeg encoders. */
+    if (end - p < 24 || (memcmp(p, "LAME", 4) != 0
+            && memcmp(p, "Lavc", 4) != 0 && memcmp(p, "Lavf", 4) != 0)) {
+        return 0;
+    }
+    *delay = (p[21] << 4) | (p[22] >> 4);
+    *padding = ((p[22] & 0x0f) << 8) | p[23];
+
+    return 1;
+}
+
+/*
+ * Read stream parameters from the first frame and calculate
+ * length of the file.
+ */
+static int mp3_read_info(struct mp3_decoder *decoder)
+{
+    struct stat stats;
+    unsigned char h[10];
+    off_t end;
+    off_t first;
+    off_t next;
+    uint64_t frames;
+    int delay;
+    int padding;
+    int r;
+
+    if (fstat(fileno(decoder->file), &stats) != 0) {
+        return -errno;
+    }
+    decoder->audio_start = 0;
+    decoder->audio_end = stats.st_size;
+
+    /* Skip ID3v2 tag at the beginning and ID3v1 at the end. */
+    if (fread(h, 1, sizeof(h), decoder->file) == sizeof(h)
+            && memcmp(h, "ID3", 3) == 0) {
+        decoder->audio_start = sizeof(h) + syncsafe(h + 6);
+        if (h[5] & 0x10) {
+            /* Footer is present. */
+            decoder->audio_start += sizeof(h);
         }
-    } while (decoder->timer.seconds < 420);
+    }
+    if (decoder->audio_end - decoder->audio_start >= 128
+            && fseeko(decoder->file, decoder->audio_end - 128, SEEK_SET) == 0
+            && fread(h, 1, 3, decoder->file) == 3
+            && memcmp(h, "TAG", 3) == 0) {
+        decoder->audio_end -= 128;
+    }
+    if (decoder->audio_start >= decoder->audio_end) {
+        return MP3_ERR_FORMAT;
+    }
 
-    /* Extrapolate the time. Not really accurate, but good enough */
-    offset = ftello(decoder->file);
-    fseek(decoder->file, 0, SEEK_END);
-    total = ftello(decoder->file);
+    /* Do not scan whole file if it is not an MPEG stream at all. */
+    end = decoder->audio_end;
+    decoder->audio_end = MIN(end, decoder->audio_start + SYNC_SEARCH_SIZE);
+    r = mp3_reset(decoder, decoder->audio_start);
+    if (r < 0) {
+        return r;
+    }
+    r = mp3_read_frame(decoder, 1);
+    decoder->audio_end = end;
+    if (r < 0) {
+        return r;
+    }
+    if (r == 1) {
+        return MP3_ERR_FORMAT;
+    }
 
-    decoder->length = ((double) total / offset) * decoder->timer.seconds;
+    decoder->sample_rate = decoder->frame.header.samplerate;
+    decoder->channels = MAD_NCHANNELS(&decoder->frame.header);
+    decoder->frame_samples = 32 * MAD_NSBSAMPLES(&decoder->frame.header);
+    first = decoder->buf_offset + (decoder->stream.this_frame - decoder->buf);
+    next = decoder->buf_offset + (decoder->stream.next_frame - decoder->buf);
 
-    mp3_rewind(decoder);
+    r = mp3_parse_xing(decoder, &frames, &delay, &padding);
+    if (r >= 0) {
+        /* Frame with the header contains no audio. */
+        decoder->audio_start = next;
+    } else {
+        decoder->audio_start = first;
+    }
+    if (r >= 0 && frames > 0) {
+        decoder->total = frames * decoder->frame_samples;
+        if (r == 1 && decoder->total > (uint64_t) (delay + padding)) {
+            decoder->delay = DECODER_DELAY + delay;
+            decoder->total -= delay + padding;
+        }
+        decoder->length = decoder->total * 1000 / decoder->sample_rate;
+    } else if (decoder->frame.header.bitrate > 0) {
+        /* Assume constant bitrate. */
+        decoder->length = (decoder->audio_end - first) * 8000
+            / decoder->frame.header.bitrate;
+    }
+    decoder->skip = decoder->delay;
 
-    return;
+    return mp3_reset(decoder, decoder->audio_start);
 }
 
 /*
- * Convert a fixed point sample to a short.
+ * Convert a fixed point sample into the output format.
  */
-static inline int16_t mp3_fixed_to_short(mad_fixed_t fixed)
+static void mp3_put_sample(char *buf, mad_fixed_t fixed,
+    enum mp3_sample_format format)
 {
+    int16_t s16;
+    int32_t s32;
+    float f32;
+
     if (fixed >= MAD_F_ONE) {
-        return SHRT_MAX;
-    } else if (fixed <= -MAD_F_ONE) {
-        return -SHRT_MAX;
+        fixed = MAD_F_ONE - 1;
+    } else if (fixed < -MAD_F_ONE) {
+        fixed = -MAD_F_ONE;
+    }
+
+    switch (format) {
+    case MP3_SAMPLE_FMT_S16:
+        s16 = fixed >> (MAD_F_FRACBITS - 15);
+        memcpy(buf, &s16, sizeof(s16));
+        break;
+    case MP3_SAMPLE_FMT_S24:
+        s32 = fixed >> (MAD_F_FRACBITS - 23);
+        memcpy(buf, &s32, sizeof(s32));
+        break;
+    case MP3_SAMPLE_FMT_S32:
+        s32 = (int32_t) ((uint32_t) fixed << (31 - MAD_F_FRACBITS));
+        memcpy(buf, &s32, sizeof(s32));
+        break;
+    case MP3_SAMPLE_FMT_F32:
+        f32 = (float) fixed / MAD_F_ONE;
+        memcpy(buf, &f32, sizeof(f32));
+        break;
     }
-    return fixed >> (MAD_F_FRACBITS - 15);
 }
 
-static int fsize(FILE *file)
+static int mp3_sample_size(enum mp3_sample_format format)
 {
-    int pos = ftell(file);
-    int size;
-
-    fseek(file, 0, SEEK_END);
-    size = ftell(file);
-    fseek(file, pos, SEEK_SET);
-
-    return size;
+    return format == MP3_SAMPLE_FMT_S16 ? 2 : 4;
 }
 
-struct mp3_decoder *mp3_open(const char *filename)
+struct mp3_decoder *mp3_open(const char *filename, int *err)
 {
-    struct mp3_decoder *d = malloc(sizeof(struct mp3_decoder));
+    struct mp3_decoder *d = calloc(1, sizeof(struct mp3_decoder));
+    int r;
 
     if (d == NULL) {
+        *err = MP3_ERR_NOMEM;
         return NULL;
     }
+    mad_stream_init(&d->stream);
+    mad_frame_init(&d->frame);
+    mad_synth_init(&d->synth);
 
     d->file = fopen(filename, "r");
     if (d->file == NULL) {
-        free(d);
-
+        *err = -errno;
+        mp3_close(d);
         return NULL;
     }
 
-    d->file_size = fsize(d->file);
-    mp3_rewind(d);
-    mp3_fill_info(d);
+    r = mp3_read_info(d);
+    if (r < 0) {
+        *err = r;
+        mp3_close(d);
+        return NULL;
+    }
 
     return d;
 }
 
-size_t mp3_decode(struct mp3_decoder *decoder, char *buf, size_t len)
+/*
+ * Decode next portion of data into buf. Returns number of bytes
+ * written, 0 at the end of the stream or negative error code.
+ */
+int mp3_decode(struct mp3_decoder *decoder, char *buf, int len)
 {
-    int16_t *words_buf = (int16_t *)buf;
-    size_t words_len = len / 2;
+    struct mad_pcm *pcm = &decoder->synth.pcm;
+    int ssize = mp3_sample_size(decoder->format);
+    int fsize = ssize * decoder->channels;
+    int written = 0;
+    int r;
+    int c;
+
+    if (decoder->error != NULL) {
+        return MP3_ERR_DECODE;
+    }
 
-    size_t written = 0;
-    int i;
+    while (written + fsize <= len) {
+        if (decoder->sample >= pcm->length) {
+            if (decoder->eof
+                    || (decoder->total > 0 && decoder->pos >= decoder->total)) {
+                break;
+            }
 
-    do {
-        if (decoder->current_sample == 0) {
-            if (mp3_read_frame(decoder) == -1) {
-                return written * 2;
+            r = mp3_read_frame(decoder, 0);
+            if (r < 0) {
+                /* Report error with the next call. */
+                return written > 0 ? written : MP3_ERR_DECODE;
+            }
+            if (r == 1) {
+                decoder->eof = 1;
+                break;
             }
-            if (mad_frame_decode(&decoder->frame, &decoder->stream) == -1) {
+            if (r == FRAME_LOST) {
+                /* Keep position right if lost samples are skipped anyway. */
+                decoder->skip -= MIN(decoder->skip,
+                    (uint64_t) 32 * MAD_NSBSAMPLES(&decoder->frame.header));
                 continue;
             }
 
-            /* decoder->sample_rate = decoder->frame.header.samplerate; */
-            /* decoder->channels = MAD_NCHANNELS(&decoder->frame.header); */
-
             mad_synth_frame(&decoder->synth, &decoder->frame);
+            decoder->sample = 0;
         }
 
-        while ((decoder->current_sample < decoder->synth.pcm.length) && (written < words_len)) {
-            for (i = 0; i < MAD_NCHANNELS(&decoder->frame.header); i++) {
-                words_buf[written++] = mp3_fixed_to_short(decoder->synth.pcm.samples[i][decoder->current_sample]);
+        if (decoder->skip > 0) {
+            uint64_t n = MIN(decoder->skip,
+                (uint64_t) (pcm->length - decoder->sample));
+            decoder->skip -= n;
+            decoder->sample += n;
+            continue;
+        }
 
+        while (decoder->sample < pcm->length && written + fsize <= len) {
+            if (decoder->total > 0 && decoder->pos >= decoder->total) {
+                /* Encoder padding. */
+                decoder->sample = pcm->length;
+                break;
+            }
+            for (c = 0; c < decoder->channels; c++) {
+                /* Mono frames can appear in stereo stream. */
+                mad_fixed_t s = pcm->samples[MIN(c, pcm->channels - 1)]
+                    [decoder->sample];
+                mp3_put_sample(buf + written, s, decoder->format);
+                written += ssize;
             }
+            decoder->sample++;
+            decoder->pos++;
+        }
+    }
+
+    return written;
+}
+
+/*
+ * Set decoding position in milliseconds. Frame headers are scanned
+ * from the beginning of the file, so position is sample accurate
+ * for both constant and variable bitrate streams.
+ */
+int mp3_seek(struct mp3_decoder *decoder, int pos)
+{
+    uint64_t target;
+    uint64_t sample = 0;
+    uint64_t prime;
+    int r;
+
+    if (pos < 0) {
+        return MP3_ERR_SEEK;
+    }
+    target = (uint64_t) pos * decoder->sample_rate / 1000;
 
-            decoder->current_sample++;
+    r = mp3_reset(decoder, decoder->audio_start);
+    if (r < 0) {
+        decoder->error = strerror(-r);
+        return r;
+    }
+    if (decoder->total > 0 && target >= decoder->total) {
+        decoder->pos = decoder->total;
+        decoder->eof = 1;
+
+        return 0;
+    }
+
+    /* Stop a few frames before the target to fill the bit reservoir. */
+    prime = (uint64_t) SEEK_PRIME_FRAMES * decoder->frame_samples;
+    while (sample + decoder->frame_samples + prime <= target + decoder->delay) {
+        r = mp3_read_frame(decoder, 1);
+        if (r < 0) {
+            return MP3_ERR_SEEK;
         }
+        if (r == 1) {
+            decoder->pos = target;
+            decoder->eof = 1;
 
-        /* Move to the next frame. */
-        if (decoder->current_sample == decoder->synth.pcm.length) {
-            decoder->current_sample = 0;
+            return 0;
         }
-    } while (written < words_len);
+        sample += 32 * MAD_NSBSAMPLES(&decoder->frame.header);
+    }
+    decoder->skip = target + decoder->delay - sample;
+    decoder->pos = target;
 
-    return written * 2;
+    return 0;
 }
 
-void mp3_seek(struct mp3_decoder *decoder, int pos, int rel)
+/*
+ * Return current decoding position in milliseconds.
+ */
+int mp3_time(struct mp3_decoder *decoder)
 {
-    if (rel) {
-        pos += decoder->position;
-    }
+    return decoder->pos * 1000 / decoder->sample_rate;
+}
 
-    /* Calculate the new relative position. */
-    pos = CLAMP(pos, 0, (int) decoder->length);
-    int new_pos = ((double) pos / decoder->length) * decoder->file_size;
+int mp3_set_sample_format(struct mp3_decoder *decoder, int format)
+{
+    switch (format) {
+    case MP3_SAMPLE_FMT_S16:
+    case MP3_SAMPLE_FMT_S24:
+    case MP3_SAMPLE_FMT_S32:
+    case MP3_SAMPLE_FMT_F32:
+        decoder->format = format;
+        return 0;
+    default:
+        return -1;
+    }
+}
 
-    mp3_rewind(decoder);
-    fseek(decoder->file, new_pos, SEEK_SET);
-    decoder->timer.seconds = pos;
-    decoder->timer.fraction = 0;
+/*
+ * Return description of the last decoding error.
+ */
+const char *mp3_error(struct mp3_decoder *decoder)
+{
+    return decoder->error != NULL ? decoder->error : "unknown error";
 }
 
 void mp3_close(struct mp3_decoder *decoder)
 {
+    mad_synth_finish(&decoder->synth);
     mad_frame_finish(&decoder->frame);
     mad_stream_finish(&decoder->stream);
-    mad_synth_finish(&decoder->synth);
 
-    fclose(decoder->file);
+    if (decoder->file != NULL) {
+        fclose(decoder->file);
+    }
     free(decoder);
 }
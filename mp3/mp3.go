@@ -0,0 +1,196 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build mp3
+
+// MP3 audio format implemented with libmad. Encoder delay and padding
+// stored in Xing/LAME header are removed, so albums are played gapless.
+package mp3
+
+// #cgo LDFLAGS: -lm -lmad
+// #include <stdlib.h>
+// #include "mp3.h"
+import "C"
+
+import (
+	"errors"
+	"syscall"
+	"unsafe"
+
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/mp3/id3tag"
+)
+
+type decoder struct {
+	d *C.struct_mp3_decoder
+}
+
+func newDecoder(path string) (*decoder, error) {
+	p := C.CString(path)
+	defer C.free(unsafe.Pointer(p))
+
+	var e C.int
+	d := C.mp3_open(p, &e)
+	if d == nil {
+		return nil, newError(int(e))
+	}
+
+	return &decoder{d: d}, nil
+}
+
+func (d *decoder) Read(buf []byte) (int, error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	bp := (*C.char)(unsafe.Pointer(&buf[0]))
+	n := int(C.mp3_decode(d.d, bp, C.int(len(buf))))
+	if n < 0 {
+		return 0, errors.New(C.GoString(C.mp3_error(d.d)))
+	}
+
+	return n, nil
+}
+
+// Seek sets new position in milliseconds.
+func (d *decoder) Seek(pos int) error {
+	if pos < 0 {
+		return errors.New("invalid seek position")
+	}
+	e := C.mp3_seek(d.d, C.int(pos))
+	if e < 0 {
+		return newError(int(e))
+	}
+
+	return nil
+}
+
+// Time returns current position in milliseconds.
+func (d *decoder) Time() int {
+	return int(C.mp3_time(d.d))
+}
+
+func (d *decoder) SampleRate() int {
+	return int(d.d.sample_rate)
+}
+
+func (d *decoder) Channels() int {
+	return int(d.d.channels)
+}
+
+func (d *decoder) SampleFormat() format.SampleFormat {
+	return format.SampleFormat(d.d.format)
+}
+
+func (d *decoder) SetSampleFormat(f format.SampleFormat) error {
+	if C.mp3_set_sample_format(d.d, C.int(f)) != 0 {
+		return errors.New("unsupported sample format")
+	}
+
+	return nil
+}
+
+func (d *decoder) Close() error {
+	C.mp3_close(d.d)
+
+	return nil
+}
+
+type mp3 struct {
+}
+
+func NewFormat() format.Format {
+	return &mp3{}
+}
+
+func (f mp3) Extensions() []string {
+	return []string{"mp3"}
+}
+
+func (f mp3) Metadata(path string) (format.Metadata, error) {
+	d, err := newDecoder(path)
+	if err != nil {
+		return nil, err
+	}
+	si := format.StreamInfo{
+		Length:     int(d.d.length),
+		Codec:      "mp3",
+		SampleRate: int(d.d.sample_rate),
+		Channels:   int(d.d.channels),
+	}
+	if si.Length > 0 {
+		size := int64(d.d.audio_end - d.d.audio_start)
+		si.Bitrate = int(size * 8 * 1000 / int64(si.Length))
+	}
+	d.Close()
+
+	tag, err := id3tag.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	md := &format.Tags{}
+	md.SetInfo(si)
+	md.Set(format.TagArtist, tag.Artist())
+	md.Set(format.TagAlbum, tag.Album())
+	md.Set(format.TagTitle, tag.Title())
+	md.Set(format.TagAlbumArtist, tag.AlbumArtist())
+	md.Set(format.TagDate, tag.Year())
+	md.Set(format.TagNumber, tag.Number())
+	md.Set(format.TagDisc, tag.Disc())
+	md.Set(format.TagGenre, tag.Genre())
+	md.Set(format.TagComposer, tag.Composer())
+	md.Set(format.TagComment, tag.Comment())
+
+	return md, nil
+}
+
+func (f mp3) Picture(path string) ([]byte, error) {
+	return id3tag.Picture(path)
+}
+
+func (f mp3) Lyrics(path string) (string, error) {
+	tag, err := id3tag.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	if l := tag.SyncedLyrics(); l != "" {
+		return l, nil
+	}
+
+	return tag.Lyrics(), nil
+}
+
+func (f mp3) Decoder(path string) (format.Decoder, error) {
+	return newDecoder(path)
+}
+
+func newError(e int) error {
+	switch e {
+	case C.MP3_ERR_NOMEM:
+		return errors.New("out of memory")
+	case C.MP3_ERR_FORMAT:
+		return format.ErrNotSupported
+	case C.MP3_ERR_DECODE:
+		return errors.New("decoding failed")
+	case C.MP3_ERR_SEEK:
+		return errors.New("seek failed")
+	default:
+		if e < 0 {
+			return syscall.Errno(-e)
+		}
+		return errors.New("unknown error")
+	}
+}
@@ -19,34 +19,79 @@
 #define MP3_H
 
 #include <stdio.h>
+#include <stdint.h>
+#include <sys/types.h>
 #include <mad.h>
 
 /* Size of the read buffer. */
 #define BUFFER_SIZE (5 * 8192)
 
+/* Error codes. Failed system calls return negative errno. */
+#define MP3_ERR_NOMEM (-10001)
+#define MP3_ERR_FORMAT (-10002)
+#define MP3_ERR_DECODE (-10003)
+#define MP3_ERR_SEEK (-10004)
+
+/*
+ * Sample formats supported by Chub. Must be kept in sync
+ * with format.SampleFormat constants.
+ */
+enum mp3_sample_format {
+    MP3_SAMPLE_FMT_S16 = 0,
+    MP3_SAMPLE_FMT_S24,
+    MP3_SAMPLE_FMT_S32,
+    MP3_SAMPLE_FMT_F32,
+};
+
 struct mp3_decoder {
+    FILE *file;
+    /* Offset of the first audio frame in the file. */
+    off_t audio_start;
+    /* Offset of the end of the audio data, i.e. ID3v1 tag start. */
+    off_t audio_end;
+    /* File offset of the first byte in the buffer. */
+    off_t buf_offset;
+    /* File offset the next read starts from. */
+    off_t file_offset;
     /* Sample rate of the stream. */
     int sample_rate;
     /* Number of channels in the stream. */
     int channels;
-    /* Length of the file in seconds. */
+    /* Number of samples in one frame. */
+    int frame_samples;
+    /* Output sample format. */
+    enum mp3_sample_format format;
+    /* Length of the file in milliseconds. */
     int length;
-    /* Current decoding position in seconds. */
-    int position;
-    FILE *file;
-    int file_size;
-    int current_sample;
+    /*
+     * Number of samples in the stream without encoder delay
+     * and padding. 0 if stream has no Xing header.
+     */
+    uint64_t total;
+    /* Number of samples to discard from the beginning of the stream. */
+    int delay;
+    /* Number of decoded samples left to discard. */
+    uint64_t skip;
+    /* Number of the next sample returned by mp3_decode(). */
+    uint64_t pos;
+    /* Number of the next synthesized sample of the current frame. */
+    int sample;
+    /* End of the stream reached. */
+    int eof;
+    /* Description of the last decoding error. */
+    const char *error;
     struct mad_stream stream;
     struct mad_frame frame;
-    struct mad_header header;
     struct mad_synth synth;
-    mad_timer_t timer;
     unsigned char buf[BUFFER_SIZE + MAD_BUFFER_GUARD];
 };
 
-struct mp3_decoder *mp3_open(const char *filename);
-size_t mp3_decode(struct mp3_decoder *decoder, char *buf, size_t len);
-void mp3_seek(struct mp3_decoder *decoder, int pos, int rel);
+struct mp3_decoder *mp3_open(const char *filename, int *err);
+int mp3_decode(struct mp3_decoder *decoder, char *buf, int len);
+int mp3_seek(struct mp3_decoder *decoder, int pos);
+int mp3_time(struct mp3_decoder *decoder);
+int mp3_set_sample_format(struct mp3_decoder *decoder, int format);
+const char *mp3_error(struct mp3_decoder *decoder);
 void mp3_close(struct mp3_decoder *decoder);
 
 #endif // MP3_H
@@ -0,0 +1,68 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build mp3
+
+package mp3
+
+import (
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+	"github.com/vchimishuk/chub/format"
+)
+
+const testFile = "id3tag/track.mp3"
+
+func TestMetadata(t *testing.T) {
+	md, err := NewFormat().Metadata(testFile)
+	assert.Nil(t, err)
+	assert.True(t, md.Artist() == "AC/DC")
+	assert.True(t, md.Number() == 1)
+	assert.True(t, md.Year() == 1977)
+	assert.True(t, md.Length() > 0)
+}
+
+func TestDecoder(t *testing.T) {
+	md, err := NewFormat().Metadata(testFile)
+	assert.Nil(t, err)
+	d, err := NewFormat().Decoder(testFile)
+	assert.Nil(t, err)
+	defer d.Close()
+	assert.True(t, d.SampleRate() > 0)
+	assert.True(t, d.Channels() > 0)
+	assert.Nil(t, d.SetSampleFormat(format.SampleFormatF32))
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := d.Read(buf)
+		assert.Nil(t, err)
+		if n == 0 {
+			break
+		}
+		assert.True(t, n%(4*d.Channels()) == 0)
+	}
+	// Length is estimated for CBR streams.
+	assert.True(t, d.Time() > md.Length()*9/10)
+	assert.True(t, d.Time() < md.Length()*11/10)
+
+	assert.Nil(t, d.Seek(md.Length()/2))
+	assert.True(t, md.Length()/2-d.Time() <= 1)
+	n, err := d.Read(buf)
+	assert.Nil(t, err)
+	assert.True(t, n > 0)
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"sync"
+	"time"
+)
+
+// Number of the latest clock samples offset is estimated from.
+const clockSamples = 32
+
+type clockSample struct {
+	// Remote clock minus local clock.
+	offset time.Duration
+	// Round-trip time of the request.
+	rtt time.Duration
+}
+
+// clock estimates offset of the sender's clock relative to the local one.
+// Offset is taken from the sample with the smallest round-trip time
+// among recent ones, since it is the least affected by network queueing.
+type clock struct {
+	mu      sync.Mutex
+	samples []clockSample
+	offset  time.Duration
+}
+
+// add adds new sample: local time request was sent at, remote time
+// and local time response was received at.
+func (c *clock) add(sent, remote, recv int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := clockSample{
+		offset: time.Duration(remote - (sent+recv)/2),
+		rtt:    time.Duration(recv - sent),
+	}
+	if s.rtt < 0 {
+		// Local clock has been changed.
+		c.samples = nil
+		return
+	}
+	c.samples = append(c.samples, s)
+	if len(c.samples) > clockSamples {
+		c.samples = c.samples[1:]
+	}
+
+	best := c.samples[0]
+	for _, s := range c.samples[1:] {
+		if s.rtt < best.rtt {
+			best = s
+		}
+	}
+	c.offset = best.offset
+}
+
+// synced returns true if there are enough samples to trust the offset.
+func (c *clock) synced() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.samples) >= clockSamples/4
+}
+
+// local converts remote time to the local one.
+func (c *clock) local(remote int64) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return time.Unix(0, remote-int64(c.offset))
+}
@@ -0,0 +1,265 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vchimishuk/chub/assert"
+	"github.com/vchimishuk/chub/format"
+)
+
+// testOutput records data written.
+type testOutput struct {
+	mu     sync.Mutex
+	open   bool
+	closes int
+	rate   int
+	chans  int
+	sfmt   format.SampleFormat
+	data   []byte
+	// Sample formats output rejects.
+	unsupported []format.SampleFormat
+}
+
+func (o *testOutput) Open() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.open = true
+	return nil
+}
+
+func (o *testOutput) SetSampleRate(rate int) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.rate = rate
+	return nil
+}
+
+func (o *testOutput) SetChannels(chans int) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.chans = chans
+	return nil
+}
+
+func (o *testOutput) SetSampleFormat(f format.SampleFormat) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if slices.Contains(o.unsupported, f) {
+		return errors.New("unsupported sample format")
+	}
+	o.sfmt = f
+	return nil
+}
+
+func (o *testOutput) Write(buf []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.data = append(o.data, buf...)
+	return len(buf), nil
+}
+
+func (o *testOutput) Flush() error {
+	return nil
+}
+
+func (o *testOutput) Pause() error {
+	return nil
+}
+
+func (o *testOutput) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.open = false
+	o.closes++
+	return nil
+}
+
+func (o *testOutput) Volume() (int, error) {
+	return 0, nil
+}
+
+func (o *testOutput) SetVolume(vol int) error {
+	return nil
+}
+
+func TestClock(t *testing.T) {
+	c := &clock{}
+	assert.True(t, !c.synced())
+
+	// Remote clock is 1s ahead. Slow response is ignored.
+	c.add(1000, 1000+1e9+500, 2000)
+	c.add(3000, 3000+1e9+5000, 13000)
+	for i := 0; i < clockSamples; i++ {
+		c.add(20000, 20000+1e9+100, 20200)
+	}
+	assert.True(t, c.synced())
+	assert.True(t, c.local(5e9).UnixNano() == 4e9)
+	assert.True(t, len(c.samples) == clockSamples)
+}
+
+func TestProto(t *testing.T) {
+	var b bytes.Buffer
+	assert.Nil(t, writeMsg(&b, msgData, putTime(42), []byte{1, 2, 3}))
+	typ, payload, err := readMsg(&b)
+	assert.Nil(t, err)
+	assert.True(t, typ == msgData)
+	assert.True(t, getTime(payload) == 42)
+	assert.True(t, bytes.Equal(payload[8:], []byte{1, 2, 3}))
+
+	b.Write([]byte{msgData, 0xff, 0xff, 0xff, 0xff})
+	_, _, err = readMsg(&b)
+	assert.Error(t, err, "message is too long")
+}
+
+func TestSwapSamples(t *testing.T) {
+	defer func(le bool) { nativeLittleEndian = le }(nativeLittleEndian)
+
+	buf := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	nativeLittleEndian = true
+	swapSamples(buf, format.SampleFormatS16)
+	assert.True(t, bytes.Equal(buf, []byte{1, 2, 3, 4, 5, 6, 7, 8}))
+
+	nativeLittleEndian = false
+	swapSamples(buf, format.SampleFormatS16)
+	assert.True(t, bytes.Equal(buf, []byte{2, 1, 4, 3, 6, 5, 8, 7}))
+	swapSamples(buf, format.SampleFormatS24)
+	assert.True(t, bytes.Equal(buf, []byte{3, 4, 1, 2, 7, 8, 5, 6}))
+}
+
+func TestSetFormat(t *testing.T) {
+	o := &testOutput{unsupported: []format.SampleFormat{
+		format.SampleFormatS32,
+		format.SampleFormatS24,
+	}}
+	f, err := setFormat(o, 48000, 2, format.SampleFormatS24)
+	assert.Nil(t, err)
+	assert.True(t, f == format.SampleFormatF32 && o.sfmt == f)
+
+	f, err = setFormat(o, 48000, 2, format.SampleFormatS16)
+	assert.Nil(t, err)
+	assert.True(t, f == format.SampleFormatS16)
+}
+
+func TestStream(t *testing.T) {
+	s := New("127.0.0.1:0", 100*time.Millisecond)
+	assert.Nil(t, s.Open())
+	assert.Nil(t, s.SetSampleRate(8000))
+
+	o := &testOutput{}
+	r := NewReceiver(s.ln.Addr().String(), o)
+	r.Start()
+	defer r.Close()
+
+	// Wait for the receiver to connect and sync its clock.
+	for i := 0; i < 100; i++ {
+		s.mu.Lock()
+		n := len(s.peers)
+		s.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(fastSyncInterval * clockSamples / 2)
+
+	// 10ms chunks of stereo s16 data.
+	var sent []byte
+	for i := 0; i < 10; i++ {
+		buf := bytes.Repeat([]byte{byte(i)}, 320)
+		n, err := s.Write(buf)
+		assert.Nil(t, err)
+		assert.True(t, n == len(buf))
+		sent = append(sent, buf...)
+	}
+	d, err := s.Delay()
+	assert.Nil(t, err)
+	assert.True(t, d > 0 && d <= 100*time.Millisecond)
+	assert.Nil(t, s.Close())
+
+	// Receiver plays queued data and closes the output.
+	for i := 0; i < 100; i++ {
+		o.mu.Lock()
+		closes := o.closes
+		o.mu.Unlock()
+		if closes > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	assert.True(t, o.closes == 1 && !o.open)
+	assert.True(t, o.rate == 8000 && o.chans == 2)
+	assert.True(t, bytes.Equal(o.data, sent))
+}
+
+func TestStreamSeek(t *testing.T) {
+	s := New("127.0.0.1:0", 200*time.Millisecond)
+	assert.Nil(t, s.Open())
+	assert.Nil(t, s.SetSampleRate(8000))
+
+	o := &testOutput{}
+	r := NewReceiver(s.ln.Addr().String(), o)
+	r.Start()
+	defer r.Close()
+
+	for i := 0; i < 100; i++ {
+		s.mu.Lock()
+		n := len(s.peers)
+		s.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(fastSyncInterval * clockSamples / 2)
+
+	// Data queued by the receiver before the seek is dropped.
+	for i := 0; i < 5; i++ {
+		_, err := s.Write(bytes.Repeat([]byte{1}, 320))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, s.Flush())
+	assert.Nil(t, s.Close())
+	assert.Nil(t, s.Open())
+	sent := bytes.Repeat([]byte{2}, 320)
+	_, err := s.Write(sent)
+	assert.Nil(t, err)
+	assert.Nil(t, s.Close())
+
+	for i := 0; i < 100; i++ {
+		o.mu.Lock()
+		closes := o.closes
+		o.mu.Unlock()
+		if closes > 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	assert.True(t, o.closes == 2)
+	assert.True(t, bytes.Equal(o.data, sent))
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/vchimishuk/chub/format"
+)
+
+// Protocol version. Sender and receiver must use the same one.
+const protoVersion = 2
+
+// Message types. Every message starts with the type byte followed
+// by 32-bit payload length and the payload. All numbers are big-endian,
+// times are nanoseconds since Unix epoch.
+const (
+	// Handshake. Payload: protocol version (1 byte). Sent by receiver
+	// first and echoed by sender.
+	msgHello byte = 'H'
+	// Stream format. Payload: sample rate (4 bytes), channels (1 byte),
+	// sample format (1 byte).
+	msgFormat byte = 'F'
+	// PCM data. Payload: time to play the first frame at in sender's
+	// clock (8 bytes), little-endian samples.
+	msgData byte = 'D'
+	// Drop all queued data, e.g. on pause or seek. No payload.
+	msgClear byte = 'C'
+	// End of the stream. Queued data is played. No payload.
+	msgStop byte = 'S'
+	// Clock sync. Request payload: receiver's time (8 bytes).
+	// Response payload: receiver's time from the request (8 bytes),
+	// sender's time (8 bytes).
+	msgTime byte = 'T'
+)
+
+// Maximum accepted payload length.
+const maxPayload = 1 << 20
+
+// encodeMsg returns message of the given type with payload assembled
+// from parts.
+func encodeMsg(typ byte, parts ...[]byte) []byte {
+	n := 0
+	for _, p := range parts {
+		n += len(p)
+	}
+	buf := make([]byte, 5, 5+n)
+	buf[0] = typ
+	binary.BigEndian.PutUint32(buf[1:], uint32(n))
+	for _, p := range parts {
+		buf = append(buf, p...)
+	}
+
+	return buf
+}
+
+// writeMsg writes message of the given type with payload assembled
+// from parts.
+func writeMsg(w io.Writer, typ byte, parts ...[]byte) error {
+	_, err := w.Write(encodeMsg(typ, parts...))
+
+	return err
+}
+
+// readMsg reads next message returning its type and payload.
+func readMsg(r io.Reader) (byte, []byte, error) {
+	var hdr [5]byte
+	_, err := io.ReadFull(r, hdr[:])
+	if err != nil {
+		return 0, nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[1:])
+	if n > maxPayload {
+		return 0, nil, errors.New("message is too long")
+	}
+	payload := make([]byte, n)
+	_, err = io.ReadFull(r, payload)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return hdr[0], payload, nil
+}
+
+func putTime(t int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(t))
+}
+
+func getTime(b []byte) int64 {
+	return int64(binary.BigEndian.Uint64(b))
+}
+
+// True if host byte order is little-endian, so samples are sent as is.
+var nativeLittleEndian = binary.NativeEndian.Uint16([]byte{1, 0}) == 1
+
+// swapSamples converts samples between native and little-endian
+// byte order in place.
+func swapSamples(buf []byte, f format.SampleFormat) {
+	if nativeLittleEndian {
+		return
+	}
+	sz := f.Size()
+	for i := 0; i+sz <= len(buf); i += sz {
+		slices.Reverse(buf[i : i+sz])
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/logger"
+	"github.com/vchimishuk/chub/player"
+)
+
+const (
+	// Time to wait before reconnecting to the sender.
+	retryInterval = time.Second
+	// Clock sync requests interval.
+	syncInterval = 2 * time.Second
+	// Clock sync requests interval while clock is not synced yet.
+	fastSyncInterval = 50 * time.Millisecond
+	// Chunks which are late more than that are dropped to catch up.
+	maxLate = 50 * time.Millisecond
+	// Maximum number of received messages waiting to be played.
+	chunkQueueLen = 4096
+)
+
+// Sample formats to convert the stream to when output does not support
+// sender's one in order of preference.
+var sampleFormatsPref = []format.SampleFormat{
+	format.SampleFormatS32,
+	format.SampleFormatS24,
+	format.SampleFormatF32,
+	format.SampleFormatS16,
+}
+
+type chunk struct {
+	typ byte
+	// Generation is increased by every clear message, so data
+	// received before it is dropped.
+	gen     uint64
+	payload []byte
+}
+
+// Receiver plays the stream received from the network output driver
+// of remote Chub instance. Every chunk is written to the output so it
+// is heard at the time sender has scheduled it for, according to the
+// output delay and the clock offset between the hosts.
+type Receiver struct {
+	addr  string
+	out   player.Output
+	close chan struct{}
+	done  chan struct{}
+	// Mutex guards conn.
+	mu sync.Mutex
+	// Current sender connection.
+	conn net.Conn
+}
+
+// NewReceiver returns receiver which connects to the sender listening
+// on addr and plays the stream to out.
+func NewReceiver(addr string, out player.Output) *Receiver {
+	return &Receiver{
+		addr:  addr,
+		out:   out,
+		close: make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// Start starts receiving in background. Receiver reconnects to the
+// sender until it is closed.
+func (r *Receiver) Start() {
+	go r.run()
+}
+
+// Close disconnects from the sender and closes the output.
+func (r *Receiver) Close() error {
+	close(r.close)
+	r.mu.Lock()
+	if r.conn != nil {
+		r.conn.Close()
+	}
+	r.mu.Unlock()
+	<-r.done
+
+	return nil
+}
+
+func (r *Receiver) closed() bool {
+	select {
+	case <-r.close:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *Receiver) run() {
+	defer close(r.done)
+
+	for {
+		err := r.session()
+		if r.closed() {
+			return
+		}
+		logger.Error("network input %s: %s", r.addr, err)
+		select {
+		case <-r.close:
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// session receives and plays the stream till connection is broken.
+func (r *Receiver) session() error {
+	conn, err := net.DialTimeout("tcp", r.addr, helloTimeout)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	if r.closed() {
+		r.mu.Unlock()
+		conn.Close()
+		return nil
+	}
+	r.conn = conn
+	r.mu.Unlock()
+	defer conn.Close()
+
+	err = writeMsg(conn, msgHello, []byte{protoVersion})
+	if err != nil {
+		return err
+	}
+	conn.SetReadDeadline(time.Now().Add(helloTimeout))
+	typ, payload, err := readMsg(conn)
+	if err != nil {
+		return err
+	}
+	if typ != msgHello || len(payload) != 1 || payload[0] != protoVersion {
+		return errors.New("unsupported protocol")
+	}
+	conn.SetReadDeadline(time.Time{})
+	logger.Info("network input connected to %s", r.addr)
+
+	clk := &clock{}
+	stop := make(chan struct{})
+	go syncClock(conn, clk, stop)
+	defer close(stop)
+
+	var gen atomic.Uint64
+	q := make(chan *chunk, chunkQueueLen)
+	played := make(chan struct{})
+	go func() {
+		r.play(q, clk, &gen)
+		close(played)
+	}()
+
+	err = receive(conn, q, clk, &gen)
+	// Drop everything not played yet.
+	gen.Add(1)
+	close(q)
+	<-played
+
+	return err
+}
+
+// syncClock periodically sends clock sync requests.
+func syncClock(conn net.Conn, clk *clock, stop <-chan struct{}) {
+	for {
+		conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		err := writeMsg(conn, msgTime, putTime(time.Now().UnixNano()))
+		if err != nil {
+			return
+		}
+		d := syncInterval
+		if !clk.synced() {
+			d = fastSyncInterval
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(d):
+		}
+	}
+}
+
+// receive reads messages from the sender and queues them for playing.
+func receive(conn net.Conn, q chan<- *chunk, clk *clock,
+	gen *atomic.Uint64) error {
+
+	for {
+		typ, payload, err := readMsg(conn)
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case msgTime:
+			if len(payload) == 16 {
+				clk.add(getTime(payload), getTime(payload[8:]),
+					time.Now().UnixNano())
+			}
+		case msgClear:
+			q <- &chunk{typ: typ, gen: gen.Add(1)}
+		case msgFormat, msgData, msgStop:
+			q <- &chunk{typ: typ, gen: gen.Load(), payload: payload}
+		}
+	}
+}
+
+// play writes queued chunks to the output at their time.
+func (r *Receiver) play(q <-chan *chunk, clk *clock, gen *atomic.Uint64) {
+	var open bool
+	// Sample format of the stream and the one output is configured for.
+	var sfmt, ofmt format.SampleFormat
+	var conv []byte
+
+	shutdown := func(err error) {
+		logger.Error("network input output failed: %s", err)
+		r.out.Close()
+		open = false
+	}
+
+	for c := range q {
+		switch c.typ {
+		case msgFormat:
+			if len(c.payload) != 6 || c.payload[4] == 0 ||
+				c.payload[5] > byte(format.SampleFormatF32) {
+				continue
+			}
+			if !open {
+				err := r.out.Open()
+				if err != nil {
+					logger.Error("failed to open output: %s", err)
+					continue
+				}
+				open = true
+			}
+			sfmt = format.SampleFormat(c.payload[5])
+			var err error
+			ofmt, err = setFormat(r.out,
+				int(binary.BigEndian.Uint32(c.payload)),
+				int(c.payload[4]), sfmt)
+			if err != nil {
+				shutdown(err)
+			}
+		case msgStop:
+			if open {
+				r.out.Close()
+				open = false
+			}
+		case msgClear:
+			if open {
+				r.out.Flush()
+			}
+		case msgData:
+			if !open || c.gen != gen.Load() || len(c.payload) < 8 ||
+				!clk.synced() || r.closed() {
+				continue
+			}
+			at := clk.local(getTime(c.payload))
+			wait := time.Until(at) - outputDelay(r.out)
+			if wait < -maxLate {
+				logger.Debug("network input is late for %s", -wait)
+				continue
+			}
+			if wait > 0 {
+				select {
+				case <-r.close:
+					continue
+				case <-time.After(wait):
+				}
+				// Stream could be cleared while waiting.
+				if c.gen != gen.Load() {
+					continue
+				}
+			}
+			data := c.payload[8:]
+			swapSamples(data, sfmt)
+			if ofmt != sfmt {
+				conv = player.ConvertSamples(conv[:0], data,
+					sfmt, ofmt)
+				data = conv
+			}
+			err := writeAll(r.out, data)
+			if err != nil {
+				shutdown(err)
+			}
+		}
+	}
+
+	if open {
+		r.out.Close()
+	}
+}
+
+// setFormat configures output for the stream. If output does not
+// support stream's sample format another one is used, so the data
+// has to be converted. Returns sample format output is configured for.
+func setFormat(out player.Output, rate int, chans int,
+	sfmt format.SampleFormat) (format.SampleFormat, error) {
+
+	ofmt := sfmt
+	err := out.SetSampleFormat(sfmt)
+	for _, f := range sampleFormatsPref {
+		if err == nil {
+			break
+		}
+		if f != sfmt && out.SetSampleFormat(f) == nil {
+			ofmt = f
+			err = nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	err = out.SetSampleRate(rate)
+	if err != nil {
+		return 0, err
+	}
+
+	return ofmt, out.SetChannels(chans)
+}
+
+// outputDelay returns time needed by the output to play data already
+// written to it.
+func outputDelay(out player.Output) time.Duration {
+	if do, ok := out.(player.DelayOutput); ok {
+		d, err := do.Delay()
+		if err == nil {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// writeAll writes all bytes in the given buffer into the output
+// performing multiple Write() calls if needed.
+func writeAll(out player.Output, buf []byte) error {
+	for len(buf) > 0 {
+		n, err := out.Write(buf)
+		if err != nil {
+			return err
+		}
+		buf = buf[n:]
+	}
+
+	return nil
+}
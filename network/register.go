@@ -1,4 +1,4 @@
-// Copyright 2016 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
 //
 // This file is part of Chub.
 //
@@ -15,41 +15,28 @@
 // You should have received a copy of the GNU General Public License
 // along with Chub. If not, see <http://www.gnu.org/licenses/>.
 
-package flac
+package network
 
 import (
+	"time"
+
+	"github.com/vchimishuk/chub/output"
 	"github.com/vchimishuk/chub/player"
-	"github.com/vchimishuk/chub/vfs"
+	"github.com/vchimishuk/config"
 )
 
-var Format format
-
-type format struct {
-}
-
-func (f format) Extensions() []string {
-	return []string{"flac"}
-}
-
-func (f format) Length(file string) int {
-	d := NewDecoder()
-	defer d.Close()
-
-	d.Open(file)
-	// TODO: Check error. Add error in Decoder.Length() signature.
-
-	return d.Length()
-}
-
-func (f format) Tag(file string) (*vfs.Tag, error) {
-	// TODO:
-	return &vfs.Tag{Artist: "FLAC",
-			Album:  "FLAC",
-			Title:  "FLAC",
-			Number: 0},
-		nil
-}
-
-func (f format) Decoder() player.Decoder {
-	return NewDecoder()
+func init() {
+	output.Register("network", []*config.PropertySpec{
+		&config.PropertySpec{
+			Type: config.TypeString,
+			Name: "network-addr",
+		},
+		&config.PropertySpec{
+			Type: config.TypeDuration,
+			Name: "network-latency",
+		},
+	}, func(c *config.Config) (player.Output, error) {
+		return New(c.StringOr("network-addr", ":7115"),
+			c.DurationOr("network-latency", time.Second)), nil
+	})
 }
@@ -0,0 +1,318 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+// Network output driver and receiver. Sender streams raw PCM data
+// to remote Chub instances running in network input mode. Every data
+// chunk carries the time it has to be played at, so all the receivers
+// play it in sync with each other.
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/logger"
+)
+
+const (
+	// Maximum number of messages queued for a receiver. Receivers
+	// which do not keep up with the stream are disconnected.
+	peerQueueLen = 1024
+	// Maximum time single network write can take.
+	writeTimeout = 2 * time.Second
+	// Maximum time to wait for the receiver's handshake.
+	helloTimeout = 5 * time.Second
+)
+
+type peer struct {
+	conn net.Conn
+	// Mutex serializes writes to conn.
+	mu   sync.Mutex
+	data chan []byte
+}
+
+func (p *peer) write(msg []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := p.conn.Write(msg)
+
+	return err
+}
+
+type Sender struct {
+	addr string
+	// Time between chunk is sent and it has to be played. Receivers
+	// buffer that much data, so it has to cover network jitter.
+	latency time.Duration
+	open    bool
+	rate    int
+	chans   int
+	sfmt    format.SampleFormat
+	volume  int
+	ln      net.Listener
+	// Time when first frame after open, resume or flush has been
+	// written.
+	start time.Time
+	// Duration of data written since start.
+	written time.Duration
+	// Mutex guards fields below which are shared with connections
+	// handlers.
+	mu    sync.Mutex
+	peers map[*peer]struct{}
+	// Format message of the current stream.
+	format []byte
+}
+
+// New returns network output driver which listens for receivers
+// on addr. Data is played by receivers latency after it is written.
+func New(addr string, latency time.Duration) *Sender {
+	return &Sender{
+		addr:    addr,
+		latency: latency,
+		rate:    44100,
+		chans:   2,
+		sfmt:    format.SampleFormatS16,
+		peers:   map[*peer]struct{}{},
+	}
+}
+
+// Open starts listening for receivers if it is not started yet.
+// Listener is kept running when the output is closed, so receivers
+// stay connected while playback is stopped.
+func (s *Sender) Open() error {
+	if s.ln == nil {
+		ln, err := net.Listen("tcp", s.addr)
+		if err != nil {
+			return err
+		}
+		s.ln = ln
+		go s.serve(ln)
+	}
+	s.open = true
+	s.written = 0
+	s.updateFormat()
+
+	return nil
+}
+
+func (s *Sender) SetSampleRate(rate int) error {
+	if rate <= 0 {
+		return errors.New("invalid sample rate")
+	}
+	s.rate = rate
+	s.updateFormat()
+
+	return nil
+}
+
+func (s *Sender) SetChannels(chans int) error {
+	if chans <= 0 || chans > 0xff {
+		return errors.New("invalid number of channels")
+	}
+	s.chans = chans
+	s.updateFormat()
+
+	return nil
+}
+
+func (s *Sender) SetSampleFormat(f format.SampleFormat) error {
+	s.sfmt = f
+	s.updateFormat()
+
+	return nil
+}
+
+// Write sends data to all connected receivers. Write blocks for
+// the time needed to play the data, so receivers get the stream
+// at real-time pace.
+func (s *Sender) Write(buf []byte) (int, error) {
+	if !s.open {
+		return 0, errors.New("output is closed")
+	}
+
+	fsz := s.chans * s.sfmt.Size()
+	frames := len(buf) / fsz
+	if frames == 0 {
+		return 0, nil
+	}
+	if s.written == 0 {
+		s.start = time.Now()
+	}
+	ts := s.start.Add(s.written + s.latency).UnixNano()
+	msg := encodeMsg(msgData, putTime(ts), buf[:frames*fsz])
+	swapSamples(msg[5+8:], s.sfmt)
+	s.broadcast(msg)
+
+	s.written += time.Duration(frames) * time.Second / time.Duration(s.rate)
+	time.Sleep(time.Until(s.start.Add(s.written)))
+
+	return frames * fsz, nil
+}
+
+// Delay returns time left till written data is played by receivers.
+func (s *Sender) Delay() (time.Duration, error) {
+	if s.written == 0 {
+		return 0, nil
+	}
+
+	return max(0, time.Until(s.start.Add(s.written+s.latency))), nil
+}
+
+// Flush makes receivers drop data not played yet.
+func (s *Sender) Flush() error {
+	s.written = 0
+	s.broadcast(encodeMsg(msgClear))
+
+	return nil
+}
+
+// Pause restarts stream timing. Receivers play data they have
+// received before the pause.
+func (s *Sender) Pause() error {
+	s.written = 0
+
+	return nil
+}
+
+// Close ends the stream. Receivers play data they have received
+// and close their outputs.
+func (s *Sender) Close() error {
+	s.open = false
+	s.written = 0
+	s.broadcast(encodeMsg(msgStop))
+
+	return nil
+}
+
+// Volume returns volume level. Volume level does not affect
+// the stream.
+func (s *Sender) Volume() (int, error) {
+	return s.volume, nil
+}
+
+func (s *Sender) SetVolume(vol int) error {
+	s.volume = vol
+
+	return nil
+}
+
+// updateFormat sends the current stream format to the receivers.
+func (s *Sender) updateFormat() {
+	b := binary.BigEndian.AppendUint32(nil, uint32(s.rate))
+	b = append(b, byte(s.chans), byte(s.sfmt))
+	msg := encodeMsg(msgFormat, b)
+
+	s.mu.Lock()
+	s.format = msg
+	s.mu.Unlock()
+	s.broadcast(msg)
+}
+
+// broadcast queues message for sending to all the receivers.
+func (s *Sender) broadcast(msg []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for p := range s.peers {
+		select {
+		case p.data <- msg:
+		default:
+			logger.Error("network receiver %s is too slow",
+				p.conn.RemoteAddr())
+			s.remove(p)
+		}
+	}
+}
+
+func (s *Sender) serve(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			logger.Error("network output: %s", err)
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle serves single receiver connection. Data is sent by separate
+// goroutine, while this one answers clock sync requests.
+func (s *Sender) handle(conn net.Conn) {
+	conn.SetReadDeadline(time.Now().Add(helloTimeout))
+	typ, payload, err := readMsg(conn)
+	if err != nil || typ != msgHello || len(payload) != 1 ||
+		payload[0] != protoVersion {
+		conn.Close()
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	p := &peer{conn: conn, data: make(chan []byte, peerQueueLen)}
+	err = p.write(encodeMsg(msgHello, []byte{protoVersion}))
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	s.mu.Lock()
+	if s.format != nil {
+		p.data <- s.format
+	}
+	s.peers[p] = struct{}{}
+	s.mu.Unlock()
+
+	go func() {
+		for msg := range p.data {
+			if p.write(msg) != nil {
+				conn.Close()
+				return
+			}
+		}
+	}()
+
+	for {
+		typ, payload, err := readMsg(conn)
+		if err != nil {
+			break
+		}
+		if typ == msgTime && len(payload) == 8 {
+			now := putTime(time.Now().UnixNano())
+			if p.write(encodeMsg(msgTime, payload, now)) != nil {
+				break
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.remove(p)
+	s.mu.Unlock()
+}
+
+// remove disconnects the receiver. Must be called with mu locked.
+func (s *Sender) remove(p *peer) {
+	if _, ok := s.peers[p]; ok {
+		delete(s.peers, p)
+		close(p.data)
+		p.conn.Close()
+	}
+}
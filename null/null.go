@@ -0,0 +1,146 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+// Null output driver. It discards all the data written, so player
+// can be run on machines without sound card (e.g. for testing).
+package null
+
+import (
+	"errors"
+	"time"
+
+	"github.com/vchimishuk/chub/format"
+)
+
+type Null struct {
+	// Consume data at real-time pace if true,
+	// as fast as possible otherwise.
+	realtime bool
+	open     bool
+	paused   bool
+	rate     int
+	chans    int
+	sfmt     format.SampleFormat
+	volume   int
+	// Time when first frame after open, resume or parameters change
+	// has been written.
+	start time.Time
+	// Number of frames written since start.
+	frames int64
+}
+
+// New returns new null output driver. If realtime is true Write blocks
+// for the time needed to play written data by real device.
+func New(realtime bool) *Null {
+	return &Null{
+		realtime: realtime,
+		rate:     44100,
+		chans:    2,
+		sfmt:     format.SampleFormatS16,
+	}
+}
+
+func (n *Null) Open() error {
+	n.open = true
+	n.paused = false
+	n.reset()
+
+	return nil
+}
+
+func (n *Null) SetSampleRate(rate int) error {
+	if rate <= 0 {
+		return errors.New("invalid sample rate")
+	}
+	n.rate = rate
+	n.reset()
+
+	return nil
+}
+
+func (n *Null) SetChannels(chans int) error {
+	if chans <= 0 {
+		return errors.New("invalid number of channels")
+	}
+	n.chans = chans
+	n.reset()
+
+	return nil
+}
+
+func (n *Null) SetSampleFormat(f format.SampleFormat) error {
+	n.sfmt = f
+	n.reset()
+
+	return nil
+}
+
+func (n *Null) Write(buf []byte) (int, error) {
+	if !n.open {
+		return 0, errors.New("output is closed")
+	}
+
+	fsz := n.chans * n.sfmt.Size()
+	frames := len(buf) / fsz
+	if frames == 0 {
+		return 0, nil
+	}
+	if n.realtime {
+		if n.frames == 0 {
+			n.start = time.Now()
+		}
+		n.frames += int64(frames)
+		d := time.Duration(n.frames) * time.Second / time.Duration(n.rate)
+		time.Sleep(time.Until(n.start.Add(d)))
+	}
+
+	return frames * fsz, nil
+}
+
+func (n *Null) Flush() error {
+	n.reset()
+
+	return nil
+}
+
+func (n *Null) Pause() error {
+	n.paused = !n.paused
+	n.reset()
+
+	return nil
+}
+
+func (n *Null) Close() error {
+	n.open = false
+
+	return nil
+}
+
+func (n *Null) Volume() (int, error) {
+	return n.volume, nil
+}
+
+func (n *Null) SetVolume(vol int) error {
+	n.volume = vol
+
+	return nil
+}
+
+// reset restarts real-time clock.
+func (n *Null) reset() {
+	n.frames = 0
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package null
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vchimishuk/chub/assert"
+)
+
+func TestRealtime(t *testing.T) {
+	n := New(true)
+	assert.Nil(t, n.Open())
+	assert.Nil(t, n.SetSampleRate(8000))
+	defer n.Close()
+
+	// 0.2 seconds of S16 stereo data.
+	buf := make([]byte, 1600*4)
+	start := time.Now()
+	w, err := n.Write(buf)
+	assert.Nil(t, err)
+	assert.True(t, w == len(buf))
+	w, err = n.Write(buf)
+	assert.Nil(t, err)
+	assert.True(t, w == len(buf))
+	assert.True(t, time.Since(start) >= 400*time.Millisecond)
+}
+
+func TestFast(t *testing.T) {
+	n := New(false)
+	assert.Nil(t, n.Open())
+	defer n.Close()
+
+	buf := make([]byte, 44100*4)
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		w, err := n.Write(buf)
+		assert.Nil(t, err)
+		assert.True(t, w == len(buf))
+	}
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestClosed(t *testing.T) {
+	n := New(false)
+	_, err := n.Write(make([]byte, 4))
+	assert.Error(t, err, "output is closed")
+}
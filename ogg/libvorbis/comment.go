@@ -15,6 +15,8 @@
 // You should have received a copy of the GNU General Public License
 // along with Chub. If not, see <http://www.gnu.org/licenses/>.
 
+//go:build ogg
+
 package libvorbis
 
 import "C"
@@ -22,9 +24,17 @@ import "C"
 // Popular user comment names.
 const (
 	CommentArtist      = "ARTIST"
+	CommentAlbumArtist = "ALBUMARTIST"
 	CommentAlbum       = "ALBUM"
 	CommentTitle       = "TITLE"
+	CommentDate        = "DATE"
 	CommentTrackNumber = "TRACKNUMBER"
+	CommentTrackTotal  = "TRACKTOTAL"
+	CommentDiscNumber  = "DISCNUMBER"
+	CommentDiscTotal   = "DISCTOTAL"
+	CommentGenre       = "GENRE"
+	CommentComposer    = "COMPOSER"
+	CommentComment     = "COMMENT"
 )
 
 // The Comment structure defines an Ogg Vorbis comment.
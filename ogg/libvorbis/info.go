@@ -15,6 +15,8 @@
 // You should have received a copy of the GNU General Public License
 // along with Chub. If not, see <http://www.gnu.org/licenses/>.
 
+//go:build ogg
+
 package libvorbis
 
 import "C"
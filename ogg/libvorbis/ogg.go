@@ -15,6 +15,8 @@
 // You should have received a copy of the GNU General Public License
 // along with Chub. If not, see <http://www.gnu.org/licenses/>.
 
+//go:build ogg
+
 // libvorbis is a libvorbis Go wrapper.
 package libvorbis
 
@@ -30,6 +32,7 @@ package libvorbis
 import "C"
 
 import (
+	"encoding/binary"
 	"errors"
 	"unsafe"
 )
@@ -51,6 +54,9 @@ type File struct {
 	Signed bool
 }
 
+// ErrNotVorbis is returned when file does not contain Vorbis data.
+var ErrNotVorbis = errors.New("not a Vorbis stream")
+
 // New is the simplest function used to open and initialize an File structure.
 // It sets up all the related decoding structure.
 func New(filename string) (file *File, err error) {
@@ -59,13 +65,17 @@ func New(filename string) (file *File, err error) {
 
 	file = new(File)
 	// Set default values.
-	file.Endianness = LittleEndian
+	file.Endianness = nativeEndianness()
 	file.WordSize = 2
 	file.Signed = true
 
-	r := C.ov_fopen(cFilename, &(file.cOggFile))
+	r, errno := C.ov_fopen(cFilename, &(file.cOggFile))
+	if r == -1 && errno != nil {
+		// File could not be opened.
+		return nil, errno
+	}
 	if r != 0 {
-		return nil, errors.New("Failed to open file")
+		return nil, newError(int(r))
 	}
 
 	return file, nil
@@ -78,7 +88,7 @@ func (file *File) Comment() *Comment {
 	comment := new(Comment)
 	comment.UserComments = make([]string, cComment.comments)
 	for i := 0; i < int(cComment.comments); i++ {
-		cUc := C.comment_hlp_get_user_comment(cComment, _Ctype_int(i))
+		cUc := C.comment_hlp_get_user_comment(cComment, C.int(i))
 		comment.UserComments[i] = C.GoString(cUc)
 	}
 	comment.Vendor = C.GoString(cComment.vendor)
@@ -107,19 +117,41 @@ func (file *File) TimeTotal() float64 {
 	return float64(C.ov_time_total(&(file.cOggFile), -1))
 }
 
+// Bitrate returns average bitrate of the whole stream in bits per second.
+func (file *File) Bitrate() int {
+	return int(C.ov_bitrate(&(file.cOggFile), -1))
+}
+
 // TimeTell returns the current decoding offset in seconds.
 func (file *File) TimeTell() float64 {
 	return float64(C.ov_time_tell(&(file.cOggFile)))
 }
 
+// PcmTotal returns the total number of samples in the physical bitstream.
+func (file *File) PcmTotal() int64 {
+	return int64(C.ov_pcm_total(&(file.cOggFile), -1))
+}
+
+// PcmTell returns the number of the next sample to be decoded.
+func (file *File) PcmTell() int64 {
+	return int64(C.ov_pcm_tell(&(file.cOggFile)))
+}
+
+// PcmSeek seeks to the given sample.
+func (file *File) PcmSeek(pos int64) error {
+	r := C.ov_pcm_seek(&(file.cOggFile), C.ogg_int64_t(pos))
+	if r != 0 {
+		return newError(int(r))
+	}
+
+	return nil
+}
+
 // Read returns up to the specified number of bytes of decoded PCM audio.
-// Return number of read 16-bit words.
-//
-// TODO: "Return number of read 16-bit words." must be in bytes and the
-//       same for all decoders.
-func (file *File) Read(buf []byte) int {
+// Returns number of read bytes, 0 means end of the stream.
+func (file *File) Read(buf []byte) (int, error) {
 	if len(buf) == 0 {
-		return 0
+		return 0, nil
 	}
 
 	var signed int
@@ -130,16 +162,77 @@ func (file *File) Read(buf []byte) int {
 	}
 
 	bufLen := (C.size_t)(len(buf))
-	bp := (*_Ctype_char)(unsafe.Pointer(&buf[0]))
+	bp := (*C.char)(unsafe.Pointer(&buf[0]))
 	read := C.ogg_hlp_read(&(file.cOggFile), bp, bufLen,
-		_Ctype_int(file.Endianness),
-		_Ctype_int(file.WordSize),
-		_Ctype_int(signed))
+		C.int(file.Endianness),
+		C.int(file.WordSize),
+		C.int(signed))
+	if read < 0 {
+		return 0, newError(int(read))
+	}
+
+	return int(read), nil
+}
+
+// ReadFloat decodes interleaved float samples of the given number
+// of channels into buf. Returns number of read frames, 0 means
+// end of the stream.
+func (file *File) ReadFloat(buf []float32, channels int) (int, error) {
+	frames := len(buf) / channels
+	if frames == 0 {
+		return 0, nil
+	}
+
+	bp := (*C.float)(unsafe.Pointer(&buf[0]))
+	read := C.ogg_hlp_read_float(&(file.cOggFile), bp, C.int(frames),
+		C.int(channels))
+	if read < 0 {
+		return 0, newError(int(read))
+	}
 
-	return int(read)
+	return int(read), nil
 }
 
 // Close release file related resources.
 func (file *File) Close() {
 	C.ov_clear(&(file.cOggFile))
 }
+
+func nativeEndianness() int {
+	var b [2]byte
+	binary.NativeEndian.PutUint16(b[:], 1)
+	if b[0] == 1 {
+		return LittleEndian
+	}
+
+	return BigEndian
+}
+
+func newError(r int) error {
+	switch r {
+	case C.OV_ENOTVORBIS:
+		return ErrNotVorbis
+	case C.OV_EREAD:
+		return errors.New("read error")
+	case C.OV_EFAULT:
+		return errors.New("internal decoder error")
+	case C.OV_EIMPL:
+		return errors.New("feature not implemented")
+	case C.OV_EINVAL:
+		return errors.New("invalid argument")
+	case C.OV_EBADHEADER:
+		return errors.New("invalid Vorbis header")
+	case C.OV_EVERSION:
+		return errors.New("Vorbis version mismatch")
+	case C.OV_ENOTAUDIO:
+		return errors.New("packet is not audio")
+	case C.OV_EBADPACKET:
+		return errors.New("invalid packet")
+	case C.OV_EBADLINK:
+		return errors.New("invalid stream section")
+	case C.OV_ENOSEEK:
+		return errors.New("stream is not seekable")
+	default:
+		return errors.New("unknown error")
+	}
+}
@@ -17,15 +17,25 @@
 
 #include "ogg_hlp.h"
 
-size_t ogg_hlp_read(OggVorbis_File *vf, char *buf, size_t size,
+#define MIN(a, b) ((a) < (b) ? (a) : (b))
+
+long ogg_hlp_read(OggVorbis_File *vf, char *buf, size_t size,
     int bigendianp, int word, int sgned)
 {
-    int read = 0; /* Already read bytes. */
+    size_t read = 0; /* Already read bytes. */
+    int section;
 
     while (read < size) {
         long rr = ov_read(vf, buf + read, size - read,
-            bigendianp, word, sgned, NULL);
-        if (rr <= 0) {
+            bigendianp, word, sgned, &section);
+        if (rr == OV_HOLE) {
+            /* Interruption in the data, e.g. right after seek. */
+            continue;
+        }
+        if (rr < 0) {
+            return rr;
+        }
+        if (rr == 0) {
             break;
         }
 
@@ -34,3 +44,35 @@ size_t ogg_hlp_read(OggVorbis_File *vf, char *buf, size_t size,
 
     return read;
 }
+
+long ogg_hlp_read_float(OggVorbis_File *vf, float *buf, int frames,
+    int channels)
+{
+    int read = 0; /* Already read frames. */
+    int section;
+    float **pcm;
+
+    while (read < frames) {
+        long rr = ov_read_float(vf, &pcm, frames - read, &section);
+        if (rr == OV_HOLE) {
+            continue;
+        }
+        if (rr < 0) {
+            return rr;
+        }
+        if (rr == 0) {
+            break;
+        }
+
+        /* Chained stream can change number of channels. */
+        int chans = ov_info(vf, -1)->channels;
+        for (long i = 0; i < rr; i++) {
+            for (int c = 0; c < channels; c++) {
+                buf[(read + i) * channels + c] = pcm[MIN(c, chans - 1)][i];
+            }
+        }
+        read += rr;
+    }
+
+    return read;
+}
@@ -16,7 +16,7 @@
 // along with Chub.  If not, see <http://www.gnu.org/licenses/>.
 
 #ifndef OGG_HLP_H
-#defne OGG_HLP_H
+#define OGG_HLP_H
 
 #include <stdlib.h>
 #include <vorbis/codec.h>
@@ -24,9 +24,18 @@
 
 /*
  * Read size bytes from the stream into a buffer.
- * Returns actual number of read bytes.
+ * Returns actual number of read bytes, 0 at the end
+ * of the stream or negative error code.
  */
-size_t ogg_hlp_read(OggVorbis_File *vf, char *buf, size_t size,
+long ogg_hlp_read(OggVorbis_File *vf, char *buf, size_t size,
     int bigendianp, int word, int sgned);
 
+/*
+ * Read up to frames frames of interleaved float samples of the given
+ * number of channels into a buffer. Returns number of read frames,
+ * 0 at the end of the stream or negative error code.
+ */
+long ogg_hlp_read_float(OggVorbis_File *vf, float *buf, int frames,
+    int channels);
+
 #endif // OGG_HLP_H
@@ -0,0 +1,190 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build ogg
+
+// Ogg Vorbis audio format implemented with libvorbisfile.
+package ogg
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/format/vorbis"
+	"github.com/vchimishuk/chub/ogg/libvorbis"
+)
+
+type decoder struct {
+	file  *libvorbis.File
+	rate  int
+	chans int
+	sfmt  format.SampleFormat
+	// Buffer for decoded float samples.
+	buf []float32
+}
+
+func newDecoder(path string) (*decoder, error) {
+	f, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	info := f.Info()
+
+	return &decoder{
+		file:  f,
+		rate:  int(info.Rate),
+		chans: info.Channels,
+		sfmt:  format.SampleFormatF32,
+	}, nil
+}
+
+func (d *decoder) Read(buf []byte) (int, error) {
+	ssize := d.sfmt.Size()
+	n := len(buf) / ssize / d.chans * d.chans
+	if len(d.buf) < n {
+		d.buf = make([]float32, n)
+	}
+	frames, err := d.file.ReadFloat(d.buf[:n], d.chans)
+	if err != nil {
+		return 0, err
+	}
+	for i, s := range d.buf[:frames*d.chans] {
+		putSample(buf[i*ssize:], s, d.sfmt)
+	}
+
+	return frames * d.chans * ssize, nil
+}
+
+// Seek sets new position in milliseconds.
+func (d *decoder) Seek(pos int) error {
+	if pos < 0 {
+		return errors.New("invalid seek position")
+	}
+	p := min(int64(pos)*int64(d.rate)/1000, d.file.PcmTotal())
+
+	return d.file.PcmSeek(p)
+}
+
+// Time returns current position in milliseconds.
+func (d *decoder) Time() int {
+	return int(d.file.PcmTell() * 1000 / int64(d.rate))
+}
+
+func (d *decoder) SampleRate() int {
+	return d.rate
+}
+
+func (d *decoder) Channels() int {
+	return d.chans
+}
+
+func (d *decoder) SampleFormat() format.SampleFormat {
+	return d.sfmt
+}
+
+func (d *decoder) SetSampleFormat(f format.SampleFormat) error {
+	switch f {
+	case format.SampleFormatS16, format.SampleFormatS24,
+		format.SampleFormatS32, format.SampleFormatF32:
+		d.sfmt = f
+		return nil
+	default:
+		return errors.New("unsupported sample format")
+	}
+}
+
+func (d *decoder) Close() error {
+	d.file.Close()
+
+	return nil
+}
+
+type ogg struct {
+}
+
+func NewFormat() format.Format {
+	return &ogg{}
+}
+
+func (f ogg) Extensions() []string {
+	return []string{"oga", "ogg"}
+}
+
+func (f ogg) Metadata(path string) (format.Metadata, error) {
+	file, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info := file.Info()
+	md := &format.Tags{}
+	md.SetInfo(format.StreamInfo{
+		Length:     int(file.TimeTotal() * 1000),
+		Codec:      "vorbis",
+		Bitrate:    max(0, file.Bitrate()),
+		SampleRate: int(info.Rate),
+		Channels:   info.Channels,
+	})
+	vorbis.ParseComments(file.Comment().UserComments, md)
+
+	return md, nil
+}
+
+func (f ogg) Lyrics(path string) (string, error) {
+	md, err := f.Metadata(path)
+	if err != nil {
+		return "", err
+	}
+
+	return md.(*format.Tags).Lyrics(), nil
+}
+
+func (f ogg) Decoder(path string) (format.Decoder, error) {
+	return newDecoder(path)
+}
+
+func open(path string) (*libvorbis.File, error) {
+	f, err := libvorbis.New(path)
+	if err == libvorbis.ErrNotVorbis {
+		return nil, format.ErrNotSupported
+	}
+
+	return f, err
+}
+
+// putSample writes float sample into buf in the given format.
+func putSample(buf []byte, s float32, f format.SampleFormat) {
+	s = max(-1, min(s, 1))
+	switch f {
+	case format.SampleFormatS16:
+		v := int16(max(math.MinInt16, min(s*(1<<15), math.MaxInt16)))
+		binary.NativeEndian.PutUint16(buf, uint16(v))
+	case format.SampleFormatS24:
+		v := int32(max(-(1 << 23), min(s*(1<<23), 1<<23-1)))
+		binary.NativeEndian.PutUint32(buf, uint32(v))
+	case format.SampleFormatS32:
+		// float32 can not represent MaxInt32.
+		v := int32(max(math.MinInt32, min(float64(s)*(1<<31),
+			math.MaxInt32)))
+		binary.NativeEndian.PutUint32(buf, uint32(v))
+	case format.SampleFormatF32:
+		binary.NativeEndian.PutUint32(buf, math.Float32bits(s))
+	}
+}
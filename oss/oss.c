@@ -15,6 +15,7 @@
 // You should have received a copy of the GNU General Public License
 // along with Chub. If not, see <http://www.gnu.org/licenses/>.
 
+#include <stdlib.h>
 #include <fcntl.h>
 #include <errno.h>
 #include <unistd.h>
@@ -35,21 +36,53 @@ int oss_sample_rate(int fd, int rate)
 {
     int r = rate;
 
-    return ioctl(fd, SNDCTL_DSP_SPEED, &r);
+    int e = ioctl(fd, SNDCTL_DSP_SPEED, &r);
+    if (e == -1) {
+        return -1;
+    }
+    // Driver returns the closest rate it supports.
+    // Treat rates differ more than 1% as not supported.
+    if (abs(r - rate) > rate / 100) {
+        errno = EINVAL;
+        return -1;
+    }
+
+    return e;
 }
 
 int oss_channels(int fd, int channels)
 {
     int c = channels;
 
-    return ioctl(fd, SNDCTL_DSP_CHANNELS, &c);
+    int e = ioctl(fd, SNDCTL_DSP_CHANNELS, &c);
+    if (e == -1) {
+        return -1;
+    }
+    // Driver returns the closest number of channels it supports.
+    if (c != channels) {
+        errno = EINVAL;
+        return -1;
+    }
+
+    return e;
 }
 
 int oss_format(int fd, int fmt)
 {
     int f = fmt;
 
-    return ioctl(fd, SNDCTL_DSP_SETFMT, &f);
+    int e = ioctl(fd, SNDCTL_DSP_SETFMT, &f);
+    if (e == -1) {
+        return -1;
+    }
+    // Driver falls back to some other format if requested one
+    // is not supported.
+    if (f != fmt) {
+        errno = EINVAL;
+        return -1;
+    }
+
+    return e;
 }
 
 int oss_write(int fd, const void *buf, int bufsz)
@@ -27,12 +27,15 @@ import (
 	"fmt"
 	"os"
 	"unsafe"
+
+	"github.com/vchimishuk/chub/format"
 )
 
 type Oss struct {
 	fd     int
 	rate   int
 	chans  int
+	sfmt   format.SampleFormat
 	paused bool
 	volume int
 }
@@ -41,6 +44,7 @@ func New() *Oss {
 	return &Oss{
 		rate:  41000,
 		chans: 2,
+		sfmt:  format.SampleFormatS16,
 	}
 }
 
@@ -58,12 +62,10 @@ func (o *Oss) Open() error {
 	}
 	o.fd = int(fd)
 
-	// TODO: Try native order here, ALSA and FFMpeg.
-	e, err := C.oss_format(C.int(o.fd), C.AFMT_S16_NE)
-	if e == -1 {
+	err = o.SetSampleFormat(o.sfmt)
+	if err != nil {
 		return err
 	}
-
 	o.SetSampleRate(o.rate)
 	o.SetChannels(o.chans)
 	o.paused = false
@@ -91,6 +93,28 @@ func (o *Oss) SetChannels(chans int) error {
 	return nil
 }
 
+func (o *Oss) SetSampleFormat(f format.SampleFormat) error {
+	var af C.int
+	switch f {
+	case format.SampleFormatS16:
+		af = C.AFMT_S16_NE
+	case format.SampleFormatS24:
+		af = C.AFMT_S24_NE
+	case format.SampleFormatS32:
+		af = C.AFMT_S32_NE
+	default:
+		return errors.New("unsupported sample format")
+	}
+
+	e, err := C.oss_format(C.int(o.fd), af)
+	if e == -1 {
+		return err
+	}
+	o.sfmt = f
+
+	return nil
+}
+
 func (o *Oss) Write(buf []byte) (written int, err error) {
 	if len(buf) == 0 {
 		return 0, nil
@@ -0,0 +1,31 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package oss
+
+import (
+	"github.com/vchimishuk/chub/output"
+	"github.com/vchimishuk/chub/player"
+	"github.com/vchimishuk/config"
+)
+
+func init() {
+	output.Register("oss", nil,
+		func(c *config.Config) (player.Output, error) {
+			return New(), nil
+		})
+}
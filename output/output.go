@@ -0,0 +1,80 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+// Package output is a registry of output drivers. Driver packages
+// register themselves in init() function, so the driver is available
+// once its package is imported.
+package output
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/vchimishuk/chub/player"
+	"github.com/vchimishuk/config"
+)
+
+// Factory creates output driver configured by the config.
+type Factory func(cfg *config.Config) (player.Output, error)
+
+type driver struct {
+	props   []*config.PropertySpec
+	factory Factory
+}
+
+var drivers = map[string]*driver{}
+
+// Register makes output driver available by name. props describes
+// driver specific configuration properties. It is recommended
+// to prefix property names with the driver name.
+func Register(name string, props []*config.PropertySpec, f Factory) {
+	if _, ok := drivers[name]; ok {
+		panic("output driver already registered: " + name)
+	}
+	drivers[name] = &driver{props: props, factory: f}
+}
+
+// Names returns sorted names of all registered drivers.
+func Names() []string {
+	names := make([]string, 0, len(drivers))
+	for n := range drivers {
+		names = append(names, n)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Properties returns configuration properties of all registered drivers.
+func Properties() []*config.PropertySpec {
+	var props []*config.PropertySpec
+	for _, n := range Names() {
+		props = append(props, drivers[n].props...)
+	}
+
+	return props
+}
+
+// New creates output driver by its name.
+func New(name string, cfg *config.Config) (player.Output, error) {
+	d, ok := drivers[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported output: %s", name)
+	}
+
+	return d.factory(cfg)
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package output
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+	"github.com/vchimishuk/chub/player"
+	"github.com/vchimishuk/config"
+)
+
+func TestRegister(t *testing.T) {
+	var got string
+	Register("test-b", nil, nil)
+	Register("test-a", []*config.PropertySpec{
+		&config.PropertySpec{Type: config.TypeString, Name: "test-a-prop"},
+	}, func(c *config.Config) (player.Output, error) {
+		got = c.StringOr("test-a-prop", "")
+		return nil, nil
+	})
+
+	assert.True(t, slices.Equal(Names(), []string{"test-a", "test-b"}))
+	props := Properties()
+	assert.True(t, len(props) == 1 && props[0].Name == "test-a-prop")
+
+	spec := &config.Spec{Properties: props}
+	c, err := config.Parse(spec, `test-a-prop = "value"`)
+	assert.Nil(t, err)
+	_, err = New("test-a", c)
+	assert.Nil(t, err)
+	assert.True(t, got == "value")
+
+	_, err = New("test-c", c)
+	assert.Error(t, err, "unsupported output: test-c")
+}
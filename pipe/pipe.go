@@ -0,0 +1,278 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+// Pipe output driver. It writes raw PCM data into a named pipe (FIFO)
+// or into standard input of an external command, so the stream can be
+// consumed by visualizers or other programs.
+package pipe
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/vchimishuk/chub/format"
+)
+
+const (
+	// Maximum time Write can be blocked by a reader which
+	// does not consume data.
+	writeTimeout = 2 * time.Second
+	// Interval between attempts to reconnect to the reader.
+	retryInterval = time.Second
+)
+
+type Pipe struct {
+	// Path to the FIFO to write into.
+	path string
+	// Command to write into standard input of. If set path is ignored.
+	command string
+	// Sample formats accepted. Any format is accepted if empty.
+	formats []format.SampleFormat
+	open    bool
+	rate    int
+	chans   int
+	sfmt    format.SampleFormat
+	volume  int
+	// Write end of the pipe. nil if there is no reader connected.
+	w *os.File
+	// Time of the next connection attempt.
+	retry time.Time
+	// Time when data started to be discarded.
+	start time.Time
+	// Number of frames discarded since start.
+	frames int64
+}
+
+// New returns new pipe output driver. If command is not empty data is
+// written into its standard input, otherwise into FIFO at path.
+// FIFO is created if it does not exist. If formats is not empty
+// only the listed sample formats are accepted.
+func New(path string, command string,
+	formats []format.SampleFormat) *Pipe {
+
+	sfmt := format.SampleFormatS16
+	if len(formats) > 0 {
+		sfmt = formats[0]
+	}
+
+	return &Pipe{
+		path:    path,
+		command: command,
+		formats: formats,
+		rate:    44100,
+		chans:   2,
+		sfmt:    sfmt,
+	}
+}
+
+func (p *Pipe) Open() error {
+	if p.command == "" {
+		err := syscall.Mkfifo(p.path, 0644)
+		if err != nil && !os.IsExist(err) {
+			return err
+		}
+	}
+	p.open = true
+	p.retry = time.Time{}
+	p.frames = 0
+
+	return nil
+}
+
+func (p *Pipe) SetSampleRate(rate int) error {
+	if rate <= 0 {
+		return errors.New("invalid sample rate")
+	}
+	if rate != p.rate {
+		p.rate = rate
+		p.reconnect()
+	}
+
+	return nil
+}
+
+func (p *Pipe) SetChannels(chans int) error {
+	if chans <= 0 {
+		return errors.New("invalid number of channels")
+	}
+	if chans != p.chans {
+		p.chans = chans
+		p.reconnect()
+	}
+
+	return nil
+}
+
+func (p *Pipe) SetSampleFormat(f format.SampleFormat) error {
+	if len(p.formats) > 0 && !slices.Contains(p.formats, f) {
+		return errors.New("unsupported sample format")
+	}
+	if f != p.sfmt {
+		p.sfmt = f
+		p.reconnect()
+	}
+
+	return nil
+}
+
+// Write writes data to the reader. If there is no reader, it went away
+// or does not consume data for too long, data is discarded at real-time
+// pace and reconnection is tried later.
+func (p *Pipe) Write(buf []byte) (int, error) {
+	if !p.open {
+		return 0, errors.New("output is closed")
+	}
+
+	fsz := p.chans * p.sfmt.Size()
+	n := len(buf) / fsz * fsz
+	if n == 0 {
+		return 0, nil
+	}
+
+	if p.w == nil && !time.Now().Before(p.retry) {
+		err := p.connect()
+		if err != nil {
+			p.retry = time.Now().Add(retryInterval)
+		}
+	}
+	if p.w != nil {
+		p.w.SetWriteDeadline(time.Now().Add(writeTimeout))
+		_, err := p.w.Write(buf[:n])
+		if err == nil {
+			return n, nil
+		}
+		// Reader has gone or stuck. Part of the frame could be
+		// written already, so the reader has to reconnect to get
+		// aligned stream.
+		p.disconnect()
+		p.retry = time.Now().Add(retryInterval)
+	}
+
+	p.discard(n / fsz)
+
+	return n, nil
+}
+
+func (p *Pipe) Flush() error {
+	p.frames = 0
+
+	return nil
+}
+
+func (p *Pipe) Pause() error {
+	p.frames = 0
+
+	return nil
+}
+
+func (p *Pipe) Close() error {
+	p.open = false
+
+	return p.disconnect()
+}
+
+// Volume returns volume level. Volume level does not affect
+// written data.
+func (p *Pipe) Volume() (int, error) {
+	return p.volume, nil
+}
+
+func (p *Pipe) SetVolume(vol int) error {
+	p.volume = vol
+
+	return nil
+}
+
+// connect opens FIFO for writing or starts the command.
+func (p *Pipe) connect() error {
+	if p.command != "" {
+		return p.startCommand()
+	}
+
+	// Non-blocking open fails immediately if there is no reader.
+	f, err := os.OpenFile(p.path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return err
+	}
+	p.w = f
+	p.frames = 0
+
+	return nil
+}
+
+// startCommand runs command with its standard input connected to
+// the pipe. Stream format is passed to the command in environment
+// variables.
+func (p *Pipe) startCommand() error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("/bin/sh", "-c", p.command)
+	cmd.Stdin = r
+	cmd.Env = append(os.Environ(),
+		"CHUB_SAMPLE_RATE="+strconv.Itoa(p.rate),
+		"CHUB_CHANNELS="+strconv.Itoa(p.chans),
+		"CHUB_SAMPLE_FORMAT="+p.sfmt.String())
+	err = cmd.Start()
+	r.Close()
+	if err != nil {
+		w.Close()
+		return err
+	}
+	// Command exits after its input is closed.
+	go cmd.Wait()
+	p.w = w
+	p.frames = 0
+
+	return nil
+}
+
+func (p *Pipe) disconnect() error {
+	if p.w == nil {
+		return nil
+	}
+	err := p.w.Close()
+	p.w = nil
+
+	return err
+}
+
+// reconnect makes command to be restarted, so it gets new stream
+// format. FIFO reader is kept connected.
+func (p *Pipe) reconnect() {
+	if p.command != "" {
+		p.disconnect()
+		p.retry = time.Time{}
+	}
+	p.frames = 0
+}
+
+// discard blocks for the time needed to play given number of frames.
+func (p *Pipe) discard(frames int) {
+	if p.frames == 0 {
+		p.start = time.Now()
+	}
+	p.frames += int64(frames)
+	d := time.Duration(p.frames) * time.Second / time.Duration(p.rate)
+	time.Sleep(time.Until(p.start.Add(d)))
+}
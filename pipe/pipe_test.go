@@ -0,0 +1,114 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package pipe
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/vchimishuk/chub/assert"
+	"github.com/vchimishuk/chub/format"
+)
+
+func TestNoReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fifo")
+	p := New(path, "", nil)
+	assert.Nil(t, p.Open())
+	defer p.Close()
+	assert.Nil(t, p.SetSampleRate(8000))
+
+	// 0.1 seconds of S16 stereo data is discarded at real-time pace.
+	buf := make([]byte, 800*4)
+	start := time.Now()
+	n, err := p.Write(buf)
+	assert.Nil(t, err)
+	assert.True(t, n == len(buf))
+	assert.True(t, time.Since(start) >= 100*time.Millisecond)
+	assert.True(t, time.Since(start) < writeTimeout)
+}
+
+func TestFifo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fifo")
+	p := New(path, "", nil)
+	assert.Nil(t, p.Open())
+	defer p.Close()
+
+	r, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	assert.Nil(t, err)
+	n, err := p.Write([]byte{1, 2, 3, 4})
+	assert.Nil(t, err)
+	assert.True(t, n == 4)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(r, buf)
+	assert.Nil(t, err)
+	assert.True(t, buf[0] == 1 && buf[3] == 4)
+
+	// Reader goes away.
+	r.Close()
+	n, err = p.Write(make([]byte, 4))
+	assert.Nil(t, err)
+	assert.True(t, n == 4)
+	assert.True(t, p.w == nil)
+}
+
+func TestCommand(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	env := filepath.Join(dir, "env")
+	p := New("", "echo $CHUB_SAMPLE_RATE $CHUB_CHANNELS "+
+		"$CHUB_SAMPLE_FORMAT > "+env+"; cat > "+out, nil)
+	assert.Nil(t, p.Open())
+	assert.Nil(t, p.SetSampleRate(48000))
+	n, err := p.Write([]byte{1, 2, 3, 4})
+	assert.Nil(t, err)
+	assert.True(t, n == 4)
+	assert.Nil(t, p.Close())
+
+	var data []byte
+	for i := 0; i < 100 && len(data) < 4; i++ {
+		time.Sleep(10 * time.Millisecond)
+		data, _ = os.ReadFile(out)
+	}
+	assert.True(t, len(data) == 4)
+	e, err := os.ReadFile(env)
+	assert.Nil(t, err)
+	assert.True(t, string(e) == "48000 2 s16\n")
+}
+
+func TestClosed(t *testing.T) {
+	p := New(filepath.Join(t.TempDir(), "fifo"), "", nil)
+	_, err := p.Write(make([]byte, 4))
+	assert.Error(t, err, "output is closed")
+}
+
+func TestFixedFormat(t *testing.T) {
+	p := New(filepath.Join(t.TempDir(), "fifo"), "",
+		[]format.SampleFormat{format.SampleFormatS24})
+	assert.True(t, p.sfmt == format.SampleFormatS24)
+	assert.Error(t, p.SetSampleFormat(format.SampleFormatS16),
+		"unsupported sample format")
+	assert.Nil(t, p.SetSampleFormat(format.SampleFormatS24))
+
+	p = New(filepath.Join(t.TempDir(), "fifo"), "", nil)
+	assert.Nil(t, p.SetSampleFormat(format.SampleFormatF32))
+	assert.True(t, p.sfmt == format.SampleFormatF32)
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+package pipe
+
+import (
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/output"
+	"github.com/vchimishuk/chub/player"
+	"github.com/vchimishuk/config"
+)
+
+func init() {
+	output.Register("pipe", []*config.PropertySpec{
+		&config.PropertySpec{
+			Type: config.TypeString,
+			Name: "pipe-path",
+		},
+		&config.PropertySpec{
+			Type: config.TypeString,
+			Name: "pipe-command",
+		},
+		&config.PropertySpec{
+			Type:   config.TypeString,
+			Name:   "pipe-format",
+			Parser: parseFormat,
+		},
+	}, func(c *config.Config) (player.Output, error) {
+		var fmts []format.SampleFormat
+		if s := c.StringOr("pipe-format", ""); s != "" {
+			f, err := format.ParseSampleFormat(s)
+			if err != nil {
+				return nil, err
+			}
+			fmts = append(fmts, f)
+		}
+		return New(c.StringOr("pipe-path", "/tmp/chub.fifo"),
+			c.StringOr("pipe-command", ""), fmts), nil
+	})
+}
+
+// parseFormat validates sample format name. Value is kept as a string.
+func parseFormat(v any) (any, error) {
+	_, err := format.ParseSampleFormat(v.(string))
+
+	return v, err
+}
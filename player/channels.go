@@ -0,0 +1,367 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package player
+
+import (
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/vchimishuk/chub/format"
+)
+
+// -3 dB gain.
+const sqrt1_2 = 1 / math.Sqrt2
+
+// Channel roles in the standard (WAV, FLAC, FFmpeg) order.
+const (
+	chFrontLeft = iota
+	chFrontRight
+	chFrontCenter
+	chLFE
+	chBackLeft
+	chBackRight
+	chBackCenter
+	chSideLeft
+	chSideRight
+)
+
+// Channel roles for the most common layouts by number of channels.
+var channelLayouts = map[int][]int{
+	3: {chFrontLeft, chFrontRight, chFrontCenter},
+	4: {chFrontLeft, chFrontRight, chBackLeft, chBackRight},
+	5: {chFrontLeft, chFrontRight, chFrontCenter, chBackLeft, chBackRight},
+	6: {chFrontLeft, chFrontRight, chFrontCenter, chLFE,
+		chBackLeft, chBackRight},
+	7: {chFrontLeft, chFrontRight, chFrontCenter, chLFE,
+		chBackCenter, chSideLeft, chSideRight},
+	8: {chFrontLeft, chFrontRight, chFrontCenter, chLFE,
+		chBackLeft, chBackRight, chSideLeft, chSideRight},
+}
+
+// Downmixer converts multichannel PCM stream read from the underlying
+// reader to the stream with less (or more) channels.
+type Downmixer struct {
+	src  io.Reader
+	in   int
+	out  int
+	sfmt format.SampleFormat
+	// matrix[o][i] is a weight of i-th input channel in o-th output one.
+	matrix [][]float64
+	raw    []byte
+	rawLen int
+}
+
+// NewDownmixer returns Downmixer which converts `in` channels stream
+// into `out` channels one. Any stream can be downmixed to mono or stereo.
+// Mono and stereo streams can be upmixed to multichannel one by filling
+// front channels only. Multichannel streams are converted to other
+// multichannel layouts by matching channel roles.
+func NewDownmixer(src io.Reader, in int, out int,
+	sfmt format.SampleFormat) (*Downmixer, error) {
+
+	m, err := mixMatrix(in, out)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Downmixer{
+		src:    src,
+		in:     in,
+		out:    out,
+		sfmt:   sfmt,
+		matrix: m,
+	}, nil
+}
+
+func (d *Downmixer) Read(buf []byte) (int, error) {
+	ifsz := d.in * d.sfmt.Size()
+	ofsz := d.out * d.sfmt.Size()
+	frames := len(buf) / ofsz
+	if frames == 0 {
+		return 0, nil
+	}
+	if cap(d.raw) < frames*ifsz {
+		raw := make([]byte, frames*ifsz)
+		copy(raw, d.raw[:d.rawLen])
+		d.raw = raw
+	}
+	d.raw = d.raw[:frames*ifsz]
+
+	for d.rawLen < ifsz {
+		n, err := d.src.Read(d.raw[d.rawLen:])
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if n == 0 {
+			break
+		}
+		d.rawLen += n
+	}
+
+	frames = d.rawLen / ifsz
+	for f := 0; f < frames; f++ {
+		for o := 0; o < d.out; o++ {
+			var v float64
+			for i, w := range d.matrix[o] {
+				if w != 0 {
+					v += w * sampleAt(d.raw, d.sfmt, f*d.in+i)
+				}
+			}
+			setSampleAt(buf, d.sfmt, f*d.out+o, v)
+		}
+	}
+	// Keep incomplete frame for the next time.
+	d.rawLen = copy(d.raw, d.raw[frames*ifsz:d.rawLen])
+
+	return frames * ofsz, nil
+}
+
+// mixMatrix returns downmix coefficients to convert `in` channels
+// into `out` ones.
+func mixMatrix(in int, out int) ([][]float64, error) {
+	if in < 1 || in > 8 {
+		return nil, errors.New("unsupported number of input channels")
+	}
+	if out < 1 || out > 8 {
+		return nil, errors.New("unsupported number of output channels")
+	}
+
+	m := make([][]float64, out)
+	for o := range m {
+		m[o] = make([]float64, in)
+	}
+
+	if out > 2 && in > 2 {
+		surroundMatrix(m, channelLayouts[in], channelLayouts[out])
+		return m, nil
+	}
+	if out > 2 {
+		// Upmix to front left and right channels.
+		m[chFrontLeft][0] = 1
+		m[chFrontRight][in-1] = 1
+		return m, nil
+	}
+	if in == 1 {
+		for o := range m {
+			m[o][0] = 1
+		}
+		return m, nil
+	}
+
+	var stereo [2][]float64
+	if in == 2 {
+		stereo = [2][]float64{{1, 0}, {0, 1}}
+	} else {
+		// ITU-R BS.775 like downmix: center and surround
+		// channels are mixed in at -3 dB, LFE is dropped.
+		l := make([]float64, in)
+		r := make([]float64, in)
+		for i, ch := range channelLayouts[in] {
+			switch ch {
+			case chFrontLeft:
+				l[i] = 1
+			case chFrontRight:
+				r[i] = 1
+			case chFrontCenter, chBackCenter:
+				l[i] = sqrt1_2
+				r[i] = sqrt1_2
+			case chBackLeft, chSideLeft:
+				l[i] = sqrt1_2
+			case chBackRight, chSideRight:
+				r[i] = sqrt1_2
+			}
+		}
+		// Normalize to avoid clipping.
+		var sum float64
+		for _, w := range l {
+			sum += w
+		}
+		for i := range l {
+			l[i] /= sum
+			r[i] /= sum
+		}
+		stereo = [2][]float64{l, r}
+	}
+
+	if out == 2 {
+		m[0], m[1] = stereo[0], stereo[1]
+	} else {
+		for i := 0; i < in; i++ {
+			m[0][i] = (stereo[0][i] + stereo[1][i]) / 2
+		}
+	}
+
+	return m, nil
+}
+
+// Channels to mix a channel missing in the output layout into,
+// in order of preference.
+var channelSubstitutes = map[int][][]int{
+	chFrontCenter: {{chFrontLeft, chFrontRight}},
+	chBackLeft:    {{chSideLeft}, {chFrontLeft}},
+	chBackRight:   {{chSideRight}, {chFrontRight}},
+	chBackCenter: {{chBackLeft, chBackRight}, {chSideLeft, chSideRight},
+		{chFrontLeft, chFrontRight}},
+	chSideLeft:  {{chBackLeft}, {chFrontLeft}},
+	chSideRight: {{chBackRight}, {chFrontRight}},
+}
+
+// surroundMatrix fills matrix m to convert one multichannel layout
+// into another. Channels present in both layouts are passed as is,
+// the others are mixed into the nearest ones at -3 dB. LFE is dropped
+// if output has no one.
+func surroundMatrix(m [][]float64, in []int, out []int) {
+	for i, ch := range in {
+		if o := slices.Index(out, ch); o >= 0 {
+			m[o][i] = 1
+			continue
+		}
+	substitutes:
+		for _, subs := range channelSubstitutes[ch] {
+			for _, sub := range subs {
+				if !slices.Contains(out, sub) {
+					continue substitutes
+				}
+			}
+			for _, sub := range subs {
+				m[slices.Index(out, sub)][i] = sqrt1_2
+			}
+			break
+		}
+	}
+}
+
+// ChannelMixer applies user controlled stereo settings: balance,
+// mono and channels swap, to the interleaved PCM data in place.
+// ChannelMixer is thread safe.
+type ChannelMixer struct {
+	mu       sync.Mutex
+	balance  int
+	mono     bool
+	swap     bool
+	channels int
+	sfmt     format.SampleFormat
+}
+
+// NewChannelMixer returns ChannelMixer which leaves data untouched.
+func NewChannelMixer() *ChannelMixer {
+	return &ChannelMixer{
+		channels: 2,
+		sfmt:     format.SampleFormatS16,
+	}
+}
+
+// Balance returns current balance value -100..100.
+func (m *ChannelMixer) Balance() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.balance
+}
+
+// SetBalance sets balance. -100 means left channel only,
+// 100 -- right channel only.
+func (m *ChannelMixer) SetBalance(b int) error {
+	if b < -100 || b > 100 {
+		return errors.New("balance out of range")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.balance = b
+
+	return nil
+}
+
+func (m *ChannelMixer) Mono() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.mono
+}
+
+// SetMono enables mixing left and right channels into both ones.
+func (m *ChannelMixer) SetMono(mono bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.mono = mono
+}
+
+func (m *ChannelMixer) Swap() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.swap
+}
+
+// SetSwap enables left and right channels swapping.
+func (m *ChannelMixer) SetSwap(swap bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.swap = swap
+}
+
+// SetFormat configures mixer for the new stream parameters.
+func (m *ChannelMixer) SetFormat(channels int, sfmt format.SampleFormat) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.channels = channels
+	m.sfmt = sfmt
+}
+
+// Process applies mixer settings to the data. Multichannel streams
+// have the settings applied to the front left and right channels,
+// mono streams are not affected.
+func (m *ChannelMixer) Process(buf []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.channels < 2 || (m.balance == 0 && !m.mono && !m.swap) {
+		return
+	}
+
+	lg, rg := 1.0, 1.0
+	if m.balance > 0 {
+		lg = 1 - float64(m.balance)/100
+	} else if m.balance < 0 {
+		rg = 1 + float64(m.balance)/100
+	}
+
+	n := len(buf) / m.sfmt.Size() / m.channels
+	for i := 0; i < n; i++ {
+		li := i * m.channels
+		l := sampleAt(buf, m.sfmt, li)
+		r := sampleAt(buf, m.sfmt, li+1)
+		if m.mono {
+			l = (l + r) / 2
+			r = l
+		}
+		if m.swap {
+			l, r = r, l
+		}
+		setSampleAt(buf, m.sfmt, li, l*lg)
+		setSampleAt(buf, m.sfmt, li+1, r*rg)
+	}
+}
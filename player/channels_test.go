@@ -0,0 +1,145 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package player
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+	"github.com/vchimishuk/chub/format"
+)
+
+func pcm(f format.SampleFormat, vals ...float64) []byte {
+	buf := make([]byte, len(vals)*f.Size())
+	for i, v := range vals {
+		setSampleAt(buf, f, i, v)
+	}
+
+	return buf
+}
+
+func near(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-3
+}
+
+func TestDownmix51(t *testing.T) {
+	f := format.SampleFormatS32
+	// FL FR FC LFE BL BR
+	in := pcm(f, 0.5, 0, 0.5, 1, 0, 0.5)
+	d, err := NewDownmixer(bytes.NewReader(in), 6, 2, f)
+	assert.Nil(t, err)
+
+	out := make([]byte, 64)
+	n, err := d.Read(out)
+	assert.Nil(t, err)
+	assert.True(t, n == 2*f.Size())
+
+	sum := 1 + 2*sqrt1_2
+	l := sampleAt(out, f, 0)
+	r := sampleAt(out, f, 1)
+	assert.True(t, near(l, (0.5+0.5*sqrt1_2)/sum))
+	assert.True(t, near(r, (0.5*sqrt1_2+0.5*sqrt1_2)/sum))
+}
+
+func TestDownmixMono(t *testing.T) {
+	f := format.SampleFormatS16
+	d, err := NewDownmixer(bytes.NewReader(pcm(f, 0.5, -0.25)), 2, 1, f)
+	assert.Nil(t, err)
+
+	out := make([]byte, 64)
+	n, err := d.Read(out)
+	assert.Nil(t, err)
+	assert.True(t, n == f.Size())
+	assert.True(t, near(sampleAt(out, f, 0), 0.125))
+}
+
+func TestDownmixSurround(t *testing.T) {
+	f := format.SampleFormatS32
+	// FL FR FC LFE BL BR
+	in := pcm(f, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6)
+	d, err := NewDownmixer(bytes.NewReader(in), 6, 8, f)
+	assert.Nil(t, err)
+	out := make([]byte, 64)
+	n, err := d.Read(out)
+	assert.Nil(t, err)
+	assert.True(t, n == 8*f.Size())
+	for i, v := range []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0, 0} {
+		assert.True(t, near(sampleAt(out, f, i), v))
+	}
+
+	// FL FR FC LFE BC SL SR
+	in = pcm(f, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7)
+	d, err = NewDownmixer(bytes.NewReader(in), 7, 4, f)
+	assert.Nil(t, err)
+	n, err = d.Read(out)
+	assert.Nil(t, err)
+	assert.True(t, n == 4*f.Size())
+	// FL FR BL BR
+	for i, v := range []float64{
+		0.1 + 0.3*sqrt1_2,
+		0.2 + 0.3*sqrt1_2,
+		(0.5 + 0.6) * sqrt1_2,
+		(0.5 + 0.7) * sqrt1_2,
+	} {
+		assert.True(t, near(sampleAt(out, f, i), v))
+	}
+}
+
+func TestDownmixUnsupported(t *testing.T) {
+	_, err := NewDownmixer(nil, 6, 9, format.SampleFormatS16)
+	assert.Error(t, err, "unsupported number of output channels")
+}
+
+func TestChannelMixer(t *testing.T) {
+	f := format.SampleFormatS16
+	m := NewChannelMixer()
+
+	buf := pcm(f, 0.5, -0.5)
+	assert.Nil(t, m.SetBalance(50))
+	m.Process(buf)
+	assert.True(t, near(sampleAt(buf, f, 0), 0.25))
+	assert.True(t, near(sampleAt(buf, f, 1), -0.5))
+
+	buf = pcm(f, 0.5, -0.5)
+	assert.Nil(t, m.SetBalance(0))
+	m.SetSwap(true)
+	m.Process(buf)
+	assert.True(t, near(sampleAt(buf, f, 0), -0.5))
+	assert.True(t, near(sampleAt(buf, f, 1), 0.5))
+
+	buf = pcm(f, 0.5, -0.5)
+	m.SetSwap(false)
+	m.SetMono(true)
+	m.Process(buf)
+	assert.True(t, near(sampleAt(buf, f, 0), 0))
+	assert.True(t, near(sampleAt(buf, f, 1), 0))
+
+	assert.Error(t, m.SetBalance(101), "balance out of range")
+
+	// Front channels of multichannel stream.
+	m.SetMono(false)
+	m.SetSwap(true)
+	m.SetFormat(3, f)
+	buf = pcm(f, 0.5, -0.5, 0.25, 0.5, -0.5, 0.25)
+	m.Process(buf)
+	for i, v := range []float64{-0.5, 0.5, 0.25, -0.5, 0.5, 0.25} {
+		assert.True(t, near(sampleAt(buf, f, i), v))
+	}
+}
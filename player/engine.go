@@ -18,15 +18,17 @@
 package player
 
 import (
+	"errors"
 	"fmt"
 	"io"
-	"strings"
 	"sync"
+	"time"
 
 	"github.com/vchimishuk/chub/csync"
 	"github.com/vchimishuk/chub/csync/job"
 	"github.com/vchimishuk/chub/format"
 	"github.com/vchimishuk/chub/logger"
+	"github.com/vchimishuk/chub/vfs"
 )
 
 type State int
@@ -55,6 +57,24 @@ type Status struct {
 	Plist    *Playlist
 	PlistPos int
 	Pos      int
+	// Format of the stream passed to the output.
+	// Valid if playback is not stopped.
+	SampleRate   int
+	Channels     int
+	SampleFormat format.SampleFormat
+}
+
+// Sample rates to fall back to when output does not support decoder's
+// native one in order of preference.
+var sampleRatesPref = []int{48000, 44100}
+
+// Sample formats to fall back to when output does not support decoder's
+// native one in order of preference.
+var sampleFormatsPref = []format.SampleFormat{
+	format.SampleFormatS32,
+	format.SampleFormatS24,
+	format.SampleFormatF32,
+	format.SampleFormatS16,
 }
 
 type command int
@@ -62,6 +82,7 @@ type command int
 const (
 	cmdClose command = iota
 	cmdNext
+	cmdOutputRate
 	cmdPause
 	cmdPlay
 	cmdPrev
@@ -79,14 +100,27 @@ type message struct {
 // Engine is a core of playing process. It manages decoding source file
 // and pass decoded samples to the output driver.
 type Engine struct {
-	// Formats for every supported file extension.
-	fmts map[string]format.Format
 	// Active output.
 	output Output
+	// Fixed output sample rate. Zero means to use decoder's one.
+	outputRate int
+	// Sample rate output is configured for.
+	outRate int
+	// Sample format output is configured for.
+	outFmt format.SampleFormat
+	// Number of channels output is configured for.
+	outChannels int
 	// Output volume level.
 	outputVol int
+	// Equalizer applied to the data before it is passed to the output.
+	eq *Equalizer
+	// Balance, mono and channels swap control.
+	mixer *ChannelMixer
 	// Active decoder.
 	decoder format.Decoder
+	// Source of PCM data for decoding loop. It is either decoder itself
+	// or a conversion stage on top of it.
+	source io.Reader
 	// Active playlist.
 	plist *Playlist
 	// Current track number in the active playlist.
@@ -109,19 +143,16 @@ type Engine struct {
 	stTrackPos int
 	// Callback to notify Player about playback changes.
 	statusHandler func(*Status)
+	// Callback to notify Player about track position advance.
+	// Called from the output loop, so it must not block.
+	positionHandler func(track *vfs.Track, pos int)
 }
 
-func NewEngine(fmts []format.Format, output Output) *Engine {
-	fm := map[string]format.Format{}
-	for _, f := range fmts {
-		for _, e := range f.Extensions() {
-			fm[strings.ToLower(e)] = f
-		}
-	}
-
+func NewEngine(output Output) *Engine {
 	return &Engine{
-		fmts:   fm,
 		output: output,
+		eq:     NewEqualizer(),
+		mixer:  NewChannelMixer(),
 		ring:   NewBufferRing(4096, 256),
 		state:  StateStopped,
 		msgs:   csync.NewNotify(),
@@ -169,10 +200,32 @@ func (e *Engine) Volume(vol int) error {
 	return e.cmd(cmdVolume, []any{vol})
 }
 
+// SetOutputRate sets fixed sample rate for the output. All decoded data
+// will be resampled to the given rate. Zero value disables resampling
+// when output supports decoder's sample rate. New value takes effect
+// from the next played track.
+func (e *Engine) SetOutputRate(rate int) error {
+	return e.cmd(cmdOutputRate, []any{rate})
+}
+
+func (e *Engine) Equalizer() *Equalizer {
+	return e.eq
+}
+
+func (e *Engine) ChannelMixer() *ChannelMixer {
+	return e.mixer
+}
+
 func (e *Engine) SetStatusHandler(h func(*Status)) {
 	e.statusHandler = h
 }
 
+// SetPositionHandler sets callback which is called every time
+// the position of the track being heard advances.
+func (e *Engine) SetPositionHandler(h func(track *vfs.Track, pos int)) {
+	e.positionHandler = h
+}
+
 func (e *Engine) cmd(c command, args []any) error {
 	r := <-e.msgs.Send(&message{cmd: c, args: args})
 	if r == nil {
@@ -202,19 +255,21 @@ func (e *Engine) run() {
 			switch msg.cmd {
 			case cmdPlay:
 				if e.state != StateStopped {
-					e.stop()
+					e.stop(true)
 				}
 				m.Result <- e.play(msg.args[0].(*Playlist),
 					msg.args[1].(int), 0)
 				e.emitStatus()
 			case cmdClose:
-				m.Result <- e.stop()
+				m.Result <- e.stop(true)
+				e.stopOutput()
 				quit = true
 				e.emitStatus()
 			case cmdStop:
 				var err error
 				if e.state != StateStopped {
-					err = e.stop()
+					err = e.stop(true)
+					e.stopOutput()
 					e.emitStatus()
 				}
 				m.Result <- err
@@ -234,6 +289,9 @@ func (e *Engine) run() {
 				m.Result <- e.status()
 			case cmdVolume:
 				m.Result <- e.volume(msg.args[0].(int))
+			case cmdOutputRate:
+				e.outputRate = msg.args[0].(int)
+				m.Result <- nil
 			default:
 				panic("unsupported command")
 			}
@@ -249,11 +307,12 @@ func (e *Engine) run() {
 		case err := <-outputDone:
 			e.outputJob = nil
 			if err == nil {
-				e.stop()
+				e.stop(false)
 			} else {
 				logger.Error("output failed: %s", err)
-				e.stop()
+				e.stop(true)
 			}
+			e.stopOutput()
 			e.emitStatus()
 		}
 	}
@@ -272,10 +331,13 @@ func (e *Engine) status() *Status {
 	defer e.stMutex.Unlock()
 
 	return &Status{
-		State:    e.state,
-		Plist:    e.plist,
-		PlistPos: e.stPlistPos,
-		Pos:      e.stTrackPos,
+		State:        e.state,
+		Plist:        e.plist,
+		PlistPos:     e.stPlistPos,
+		Pos:          e.stTrackPos,
+		SampleRate:   e.outRate,
+		Channels:     e.outChannels,
+		SampleFormat: e.outFmt,
 	}
 }
 
@@ -284,7 +346,7 @@ func (e *Engine) status() *Status {
 // in the playlist automatically.
 func (e *Engine) play(plist *Playlist, plistPos int, trackPos int) error {
 	if e.state != StateStopped {
-		err := e.stop()
+		err := e.stop(true)
 		if err != nil {
 			return err
 		}
@@ -324,7 +386,9 @@ func (e *Engine) play(plist *Playlist, plistPos int, trackPos int) error {
 }
 
 // Stop playback by shutting down running decode and output goroutines.
-func (e *Engine) stop() error {
+// If flush is true data buffered by the output and not played yet
+// is dropped, otherwise the output plays it before it is closed.
+func (e *Engine) stop(flush bool) error {
 	var derr error
 	var oerr error
 
@@ -341,8 +405,15 @@ func (e *Engine) stop() error {
 	if e.decoder != nil {
 		derr = e.decoder.Close()
 		e.decoder = nil
+		e.source = nil
 	}
 	if e.state != StateStopped {
+		if flush {
+			err := e.output.Flush()
+			if err != nil {
+				logger.Error("output flushing failed: %s", err)
+			}
+		}
 		oerr = e.output.Close()
 	}
 
@@ -357,6 +428,16 @@ func (e *Engine) stop() error {
 	return nil
 }
 
+// stopOutput notifies output that playback is stopped.
+func (e *Engine) stopOutput() {
+	if so, ok := e.output.(StopOutput); ok {
+		err := so.Stop()
+		if err != nil {
+			logger.Error("output stopping failed: %s", err)
+		}
+	}
+}
+
 func (e *Engine) pause() error {
 	switch e.state {
 	case StatePlaying:
@@ -410,9 +491,12 @@ func (e *Engine) next(auto bool) error {
 				logger.Error("decoder closing faile: %s", err)
 			}
 			err = e.openDecoder()
+			if err == nil {
+				err = e.adaptDecoder()
+			}
 			if err != nil {
 				// Call stop() to try cleanup.
-				e.stop()
+				e.stop(true)
 
 				return err
 			}
@@ -430,7 +514,7 @@ func (e *Engine) next(auto bool) error {
 			return nil
 		}
 
-		err := e.stop()
+		err := e.stop(true)
 		if err != nil {
 			return err
 		}
@@ -458,7 +542,7 @@ func (e *Engine) prev() error {
 		return nil
 	}
 
-	err := e.stop()
+	err := e.stop(true)
 	if err != nil {
 		return err
 	}
@@ -481,7 +565,7 @@ func (e *Engine) seek(pos int, rel bool) error {
 		return nil
 	}
 
-	err := e.stop()
+	err := e.stop(true)
 	if err != nil {
 		return err
 	}
@@ -532,23 +616,141 @@ func (e *Engine) openDecoderOutput() error {
 		return err
 	}
 
-	e.output.SetSampleRate(e.decoder.SampleRate())
-	e.output.SetChannels(e.decoder.Channels())
+	err = e.negotiateSampleFormat()
+	if err == nil {
+		err = e.negotiateSampleRate()
+	}
+	if err == nil {
+		err = e.negotiateChannels()
+	}
+	if err == nil {
+		err = e.setupSource()
+	}
+	if err != nil {
+		e.output.Close()
+		e.decoder.Close()
+		e.decoder = nil
+		return err
+	}
 	e.output.SetVolume(e.outputVol)
+	e.eq.SetFormat(e.outRate, e.outChannels, e.outFmt)
+	e.mixer.SetFormat(e.outChannels, e.outFmt)
+
+	return nil
+}
+
+// adaptDecoder configures newly opened decoder to produce data
+// in the format output is already configured for. So the next track
+// can be played without output reconfiguration.
+func (e *Engine) adaptDecoder() error {
+	if e.decoder.SampleFormat() != e.outFmt {
+		err := e.decoder.SetSampleFormat(e.outFmt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return e.setupSource()
+}
+
+// setupSource builds conversion stages chain between decoder and
+// decoding loop.
+func (e *Engine) setupSource() error {
+	e.source = e.decoder
+	if e.decoder.Channels() != e.outChannels {
+		d, err := NewDownmixer(e.source, e.decoder.Channels(),
+			e.outChannels, e.outFmt)
+		if err != nil {
+			return err
+		}
+		e.source = d
+	}
+	if e.decoder.SampleRate() != e.outRate {
+		e.source = NewResampler(e.source, e.decoder.SampleRate(),
+			e.outRate, e.outChannels, e.outFmt)
+	}
 
 	return nil
 }
 
+// negotiateChannels configures number of output channels. If output
+// does not support decoder's number of channels the stream is
+// downmixed to stereo or mono.
+func (e *Engine) negotiateChannels() error {
+	chans := []int{e.decoder.Channels()}
+	for _, c := range []int{2, 1} {
+		if c != chans[0] {
+			chans = append(chans, c)
+		}
+	}
+
+	var err error
+	for _, c := range chans {
+		err = e.output.SetChannels(c)
+		if err == nil {
+			e.outChannels = c
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no supported number of channels: %w", err)
+}
+
+// negotiateSampleRate configures output sample rate. Fixed output rate
+// is used if configured, decoder's native one otherwise. If output
+// does not support native rate one of the common rates is used and
+// the stream is resampled.
+func (e *Engine) negotiateSampleRate() error {
+	var rates []int
+	if e.outputRate != 0 {
+		rates = []int{e.outputRate}
+	} else {
+		rates = append([]int{e.decoder.SampleRate()}, sampleRatesPref...)
+	}
+
+	var err error
+	for _, r := range rates {
+		err = e.output.SetSampleRate(r)
+		if err == nil {
+			e.outRate = r
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no supported sample rate: %w", err)
+}
+
+// negotiateSampleFormat picks sample format supported by both decoder
+// and output. Decoder's native format is preferred, so the stream is
+// passed to the device bit-perfect if possible.
+func (e *Engine) negotiateSampleFormat() error {
+	native := e.decoder.SampleFormat()
+	fmts := []format.SampleFormat{native}
+	for _, f := range sampleFormatsPref {
+		if f != native {
+			fmts = append(fmts, f)
+		}
+	}
+
+	for _, f := range fmts {
+		if e.output.SetSampleFormat(f) != nil {
+			continue
+		}
+		if f != native && e.decoder.SetSampleFormat(f) != nil {
+			continue
+		}
+		e.outFmt = f
+
+		return nil
+	}
+
+	return errors.New("no supported sample format")
+}
+
 // Open decoder for the current playlist and track.
 func (e *Engine) openDecoder() error {
 	t := e.plist.Get(e.plistPos)
-	ext := strings.ToLower(t.Path.Ext())
-	f := e.fmts[ext]
-	if f == nil {
-		return fmt.Errorf("unsupported format: %s", ext)
-	}
-
-	d, err := f.Decoder(t.Path.File())
+	d, err := format.GetDecoder(t.Path.File())
 	if err != nil {
 		return err
 	}
@@ -602,7 +804,10 @@ loop:
 
 		buf.plistPos = e.plistPos
 		buf.trackPos = time - t.Start
-		n, err = e.decoder.Read(buf.data[0:cap(buf.data)])
+		// Keep buffers frame-aligned, so every buffer can be
+		// processed and written to the output independently.
+		fsz := e.outFmt.Size() * e.outChannels
+		n, err = e.source.Read(buf.data[0 : cap(buf.data)-cap(buf.data)%fsz])
 		if err != nil {
 			// Decoding error -- return the error.
 			break
@@ -618,11 +823,30 @@ loop:
 	return err
 }
 
+// playMark describes position of the buffer written to the output.
+type playMark struct {
+	plistPos int
+	trackPos int
+	// Offset of the buffer beginning in the written stream.
+	start time.Duration
+	// Buffer duration.
+	dur time.Duration
+}
+
 // outputLoop runs a blocking IO loop that transfers data from buffers cache
 // to the output driver.
 func (e *Engine) outputLoop(close <-chan any) error {
 	var curTrack int = -1
 	var err error
+	// Buffers written to the output but possibly not heard yet.
+	var marks []playMark
+	// Total duration of data written by this loop.
+	var written time.Duration
+	bps := e.outRate * e.outChannels * e.outFmt.Size()
+
+	e.stMutex.Lock()
+	heardTrack := e.stPlistPos
+	e.stMutex.Unlock()
 
 loop:
 	for {
@@ -639,27 +863,83 @@ loop:
 			break
 		}
 
-		e.stMutex.Lock()
-		e.stPlistPos = buf.plistPos
-		e.stTrackPos = buf.trackPos
-		e.stMutex.Unlock()
-
-		if curTrack != -1 && curTrack != buf.plistPos {
-			// Emit status on automatic track change.
-			e.emitStatus()
+		if curTrack != buf.plistPos {
+			if to, ok := e.output.(TrackOutput); ok {
+				err = to.SetTrack(e.plist.Get(buf.plistPos))
+				if err != nil {
+					break
+				}
+			}
 		}
 		curTrack = buf.plistPos
 
+		e.eq.Process(buf.data)
+		e.mixer.Process(buf.data)
 		err = writeAll(e.output, buf.data)
 		if err != nil {
 			break
 		}
+		dur := time.Duration(len(buf.data)) * time.Second /
+			time.Duration(bps)
+		marks = append(marks, playMark{
+			plistPos: buf.plistPos,
+			trackPos: buf.trackPos,
+			start:    written,
+			dur:      dur,
+		})
+		written += dur
 		e.ring.OfferFree(buf)
+
+		marks = e.updatePos(marks, written)
+		e.stMutex.Lock()
+		plistPos := e.stPlistPos
+		trackPos := e.stTrackPos
+		e.stMutex.Unlock()
+		if e.positionHandler != nil {
+			e.positionHandler(e.plist.Get(plistPos), trackPos)
+		}
+		if plistPos != heardTrack {
+			// Emit status on automatic track change.
+			heardTrack = plistPos
+			e.emitStatus()
+		}
 	}
 
 	return err
 }
 
+// updatePos sets status position to the one being heard right now,
+// which is behind the written one by the output's delay. Returns marks
+// which are not played completely yet.
+func (e *Engine) updatePos(marks []playMark, written time.Duration) []playMark {
+	var delay time.Duration
+	if do, ok := e.output.(DelayOutput); ok {
+		d, err := do.Delay()
+		if err == nil {
+			delay = d
+		}
+	}
+	heard := written - delay
+
+	for len(marks) > 1 && marks[0].start+marks[0].dur <= heard {
+		marks = marks[1:]
+	}
+	m := marks[0]
+	if heard < m.start {
+		// Data written before this loop started (e.g. before pause)
+		// is still being played, keep the previous position.
+		return marks
+	}
+	off := min(heard-m.start, m.dur)
+
+	e.stMutex.Lock()
+	e.stPlistPos = m.plistPos
+	e.stTrackPos = m.trackPos + int(off/time.Millisecond)
+	e.stMutex.Unlock()
+
+	return marks
+}
+
 // writeAll writes all bytes in the given buffer into the writer
 // performing multiple Write() calls if needed.
 func writeAll(w io.Writer, buf []byte) error {
@@ -0,0 +1,162 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+package player
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vchimishuk/chub/assert"
+	"github.com/vchimishuk/chub/format"
+)
+
+// testDecoder produces silence in the configured format.
+type testDecoder struct {
+	rate  int
+	chans int
+	fmt   format.SampleFormat
+	// Formats decoder is able to convert to.
+	fmts []format.SampleFormat
+}
+
+func (d *testDecoder) Read(buf []byte) (int, error) {
+	clear(buf)
+	return len(buf), nil
+}
+
+func (d *testDecoder) Seek(pos int) error {
+	return nil
+}
+
+func (d *testDecoder) Time() int {
+	return 0
+}
+
+func (d *testDecoder) SampleRate() int {
+	return d.rate
+}
+
+func (d *testDecoder) Channels() int {
+	return d.chans
+}
+
+func (d *testDecoder) SampleFormat() format.SampleFormat {
+	return d.fmt
+}
+
+func (d *testDecoder) SetSampleFormat(f format.SampleFormat) error {
+	for _, ff := range d.fmts {
+		if ff == f {
+			d.fmt = f
+			return nil
+		}
+	}
+	return errors.New("unsupported sample format")
+}
+
+func (d *testDecoder) Close() error {
+	return nil
+}
+
+func TestEngineUpdatePos(t *testing.T) {
+	o := &delayOutput{}
+	e := NewEngine(o)
+	marks := []playMark{
+		{plistPos: 0, trackPos: 9000, start: 0, dur: time.Second},
+		{plistPos: 1, trackPos: 0, start: time.Second, dur: time.Second},
+	}
+
+	// No delay -- the end of written data is heard.
+	marks = e.updatePos(marks, 2*time.Second)
+	assert.True(t, len(marks) == 1)
+	assert.True(t, e.stPlistPos == 1 && e.stTrackPos == 1000)
+
+	// The previous track is still being played.
+	marks = []playMark{
+		{plistPos: 0, trackPos: 9000, start: 0, dur: time.Second},
+		{plistPos: 1, trackPos: 0, start: time.Second, dur: time.Second},
+	}
+	o.delay = 1250 * time.Millisecond
+	marks = e.updatePos(marks, 2*time.Second)
+	assert.True(t, len(marks) == 2)
+	assert.True(t, e.stPlistPos == 0 && e.stTrackPos == 9750)
+
+	// Data written before the loop started is being played.
+	o.delay = 3 * time.Second
+	e.updatePos(marks, 2*time.Second)
+	assert.True(t, e.stPlistPos == 0 && e.stTrackPos == 9750)
+}
+
+func TestEngineAdaptDecoder(t *testing.T) {
+	e := NewEngine(&testOutput{})
+	e.outRate = 44100
+	e.outChannels = 2
+	e.outFmt = format.SampleFormatS16
+
+	// The next track is 24-bit, it must be converted to the format
+	// output is configured for on gapless transition.
+	d := &testDecoder{rate: 44100, chans: 2,
+		fmt:  format.SampleFormatS24,
+		fmts: []format.SampleFormat{format.SampleFormatS16}}
+	e.decoder = d
+	assert.Nil(t, e.adaptDecoder())
+	assert.True(t, d.fmt == format.SampleFormatS16)
+	assert.True(t, e.source == d)
+
+	d = &testDecoder{rate: 44100, chans: 2,
+		fmt: format.SampleFormatS24}
+	e.decoder = d
+	assert.Error(t, e.adaptDecoder(), "unsupported sample format")
+}
+
+func TestEngineStopFlush(t *testing.T) {
+	o := &testOutput{open: true}
+	e := NewEngine(o)
+
+	// Stop, seek and track switch drop data not played yet.
+	e.state = StatePlaying
+	assert.Nil(t, e.stop(true))
+	assert.True(t, o.flushes == 1 && !o.open)
+	assert.True(t, e.state == StateStopped)
+
+	// Output plays all the data at the end of the playlist.
+	o.open = true
+	e.state = StatePlaying
+	assert.Nil(t, e.stop(false))
+	assert.True(t, o.flushes == 1 && !o.open)
+}
+
+func TestEngineNegotiateSampleFormat(t *testing.T) {
+	o := &testOutput{unsupported: []format.SampleFormat{
+		format.SampleFormatS24,
+	}}
+	e := NewEngine(o)
+
+	// Formats rejected by the decoder are skipped too.
+	d := &testDecoder{fmt: format.SampleFormatS24,
+		fmts: []format.SampleFormat{format.SampleFormatS16}}
+	e.decoder = d
+	assert.Nil(t, e.negotiateSampleFormat())
+	assert.True(t, e.outFmt == format.SampleFormatS16)
+	assert.True(t, o.sfmt == format.SampleFormatS16)
+	assert.True(t, d.fmt == format.SampleFormatS16)
+
+	e.decoder = &testDecoder{fmt: format.SampleFormatS24}
+	assert.Error(t, e.negotiateSampleFormat(),
+		"no supported sample format")
+}
@@ -0,0 +1,222 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package player
+
+import (
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/vchimishuk/chub/format"
+)
+
+const (
+	// Maximum absolute band gain value in dB.
+	EqMaxGain = 12
+	// Bandwidth of every band filter.
+	eqQ = math.Sqrt2
+)
+
+// Center frequencies of equalizer bands in Hz.
+var EqBands = []float64{
+	31, 62, 125, 250, 500, 1000, 2000, 4000, 8000, 16000,
+}
+
+// biquad is a single second order peaking filter with its own
+// per-channel history.
+type biquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+	// Direct form I history: x[n-1], x[n-2], y[n-1], y[n-2]
+	// for every channel.
+	hist [][4]float64
+}
+
+func (f *biquad) process(ch int, x float64) float64 {
+	h := &f.hist[ch]
+	y := f.b0*x + f.b1*h[0] + f.b2*h[1] - f.a1*h[2] - f.a2*h[3]
+	h[1], h[0] = h[0], x
+	h[3], h[2] = h[2], y
+
+	return y
+}
+
+// Equalizer is a multi-band graphic equalizer built on top of peaking
+// biquad filters. Equalizer processes interleaved PCM data in place.
+// Equalizer is thread safe, so its settings can be changed while output
+// goroutine processes the data.
+type Equalizer struct {
+	mu       sync.Mutex
+	enabled  bool
+	gains    []int
+	rate     int
+	channels int
+	sfmt     format.SampleFormat
+	// Filters for bands with non-zero gain only.
+	filters []*biquad
+}
+
+// NewEqualizer returns disabled equalizer with all gains set to zero.
+func NewEqualizer() *Equalizer {
+	return &Equalizer{
+		gains:    make([]int, len(EqBands)),
+		rate:     44100,
+		channels: 2,
+		sfmt:     format.SampleFormatS16,
+	}
+}
+
+// Enabled returns true if equalizer is turned on.
+func (e *Equalizer) Enabled() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.enabled
+}
+
+// SetEnabled turns equalizer on or off.
+func (e *Equalizer) SetEnabled(enabled bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.enabled = enabled
+	e.update()
+}
+
+// Gains returns copy of all bands gains in dB.
+func (e *Equalizer) Gains() []int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	g := make([]int, len(e.gains))
+	copy(g, e.gains)
+
+	return g
+}
+
+// SetGains sets all bands gains at once.
+func (e *Equalizer) SetGains(gains []int) error {
+	if len(gains) != len(EqBands) {
+		return errors.New("invalid number of bands")
+	}
+	for _, g := range gains {
+		if g < -EqMaxGain || g > EqMaxGain {
+			return errors.New("gain out of range")
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	copy(e.gains, gains)
+	e.update()
+
+	return nil
+}
+
+// SetGain sets gain for the single band.
+func (e *Equalizer) SetGain(band int, gain int) error {
+	if band < 0 || band >= len(EqBands) {
+		return errors.New("invalid band")
+	}
+	if gain < -EqMaxGain || gain > EqMaxGain {
+		return errors.New("gain out of range")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.gains[band] = gain
+	e.update()
+
+	return nil
+}
+
+// SetFormat configures equalizer for the new stream parameters.
+// Filters history is reset.
+func (e *Equalizer) SetFormat(rate int, channels int,
+	sfmt format.SampleFormat) {
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rate = rate
+	e.channels = channels
+	e.sfmt = sfmt
+	e.update()
+}
+
+// Process applies equalizer to the interleaved PCM data in place.
+func (e *Equalizer) Process(buf []byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.filters) == 0 {
+		return
+	}
+
+	n := len(buf) / e.sfmt.Size()
+	for i := 0; i < n; i++ {
+		ch := i % e.channels
+		x := sampleAt(buf, e.sfmt, i)
+		for _, f := range e.filters {
+			x = f.process(ch, x)
+		}
+		setSampleAt(buf, e.sfmt, i, x)
+	}
+}
+
+// update rebuilds filters for the current settings.
+// Must be called with mutex held.
+func (e *Equalizer) update() {
+	e.filters = nil
+	if !e.enabled {
+		return
+	}
+
+	for i, g := range e.gains {
+		f := EqBands[i]
+		if g == 0 || f >= float64(e.rate)/2 {
+			continue
+		}
+		e.filters = append(e.filters,
+			newPeakingFilter(f, float64(g), e.rate, e.channels))
+	}
+}
+
+// newPeakingFilter returns peaking EQ biquad filter as described
+// in the RBJ's "Audio EQ Cookbook".
+func newPeakingFilter(freq float64, gain float64, rate int,
+	channels int) *biquad {
+
+	a := math.Pow(10, gain/40)
+	w0 := 2 * math.Pi * freq / float64(rate)
+	alpha := math.Sin(w0) / (2 * eqQ)
+	cos := math.Cos(w0)
+
+	a0 := 1 + alpha/a
+
+	return &biquad{
+		b0:   (1 + alpha*a) / a0,
+		b1:   (-2 * cos) / a0,
+		b2:   (1 - alpha*a) / a0,
+		a1:   (-2 * cos) / a0,
+		a2:   (1 - alpha/a) / a0,
+		hist: make([][4]float64, channels),
+	}
+}
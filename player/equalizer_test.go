@@ -0,0 +1,87 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package player
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+	"github.com/vchimishuk/chub/format"
+)
+
+// sine returns stereo S16 PCM sine wave of the given frequency.
+func sine(freq float64, rate int, frames int) []byte {
+	buf := make([]byte, 0, frames*4)
+	for i := 0; i < frames; i++ {
+		s := int16(8000 * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)))
+		buf = append(buf, byte(s), byte(s>>8), byte(s), byte(s>>8))
+	}
+
+	return buf
+}
+
+// peak returns maximum absolute sample value in the second half
+// of the buffer, so filters have time to settle.
+func peak(buf []byte) int {
+	p := 0
+	for i := len(buf) / 2; i < len(buf); i += 2 {
+		s := int(int16(uint16(buf[i]) | uint16(buf[i+1])<<8))
+		p = max(p, s, -s)
+	}
+
+	return p
+}
+
+func TestEqualizerDisabled(t *testing.T) {
+	eq := NewEqualizer()
+	assert.Nil(t, eq.SetGain(5, 6))
+
+	buf := sine(1000, 44100, 4410)
+	orig := bytes.Clone(buf)
+	eq.Process(buf)
+	assert.True(t, bytes.Equal(buf, orig))
+}
+
+func TestEqualizerGain(t *testing.T) {
+	eq := NewEqualizer()
+	eq.SetFormat(44100, 2, format.SampleFormatS16)
+	eq.SetEnabled(true)
+	assert.Nil(t, eq.SetGain(5, 6))
+
+	buf := sine(1000, 44100, 4410)
+	p := peak(buf)
+	eq.Process(buf)
+	// +6 dB is roughly twice as loud.
+	r := float64(peak(buf)) / float64(p)
+	assert.True(t, r > 1.9 && r < 2.1)
+
+	assert.Nil(t, eq.SetGain(5, -6))
+	buf = sine(1000, 44100, 4410)
+	eq.Process(buf)
+	r = float64(peak(buf)) / float64(p)
+	assert.True(t, r > 0.45 && r < 0.55)
+}
+
+func TestEqualizerInvalid(t *testing.T) {
+	eq := NewEqualizer()
+	assert.Error(t, eq.SetGain(len(EqBands), 0), "invalid band")
+	assert.Error(t, eq.SetGain(0, EqMaxGain+1), "gain out of range")
+	assert.Error(t, eq.SetGains([]int{1, 2}), "invalid number of bands")
+}
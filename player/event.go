@@ -50,18 +50,52 @@ func (e *StatusEvent) Serialize() []serialize.Serializable {
 		st["playlist-name"] = e.Plist.Name()
 		st["playlist-position"] = e.PlistPos
 		st["track-album"] = e.Track.Tag.Album
+		st["track-album-artist"] = e.Track.Tag.AlbumArtist
 		st["track-artist"] = e.Track.Tag.Artist
+		st["track-bit-depth"] = e.Track.BitDepth
+		st["track-bitrate"] = e.Track.Bitrate
+		st["track-channels"] = e.Track.Channels
+		st["track-codec"] = e.Track.Codec
+		st["track-comment"] = e.Track.Tag.Comment
+		st["track-composer"] = e.Track.Tag.Composer
+		st["track-date"] = e.Track.Tag.Date
+		st["track-disc"] = e.Track.Tag.Disc
+		st["track-discs"] = e.Track.Tag.Discs
+		st["track-genre"] = e.Track.Tag.Genre
 		st["track-length"] = e.Track.Length
 		st["track-number"] = e.Track.Tag.Number
 		st["track-path"] = e.Track.Path.String()
 		st["track-position"] = e.TrackPos
+		st["track-sample-rate"] = e.Track.SampleRate
 		st["track-title"] = e.Track.Tag.Title
+		st["track-tracks"] = e.Track.Tag.Tracks
 		st["track-year"] = e.Track.Tag.Year
 	}
 
 	return []serialize.Serializable{serialize.Wrap(st)}
 }
 
+// LyricsEvent is emitted when the next line
+// of synchronized lyrics is reached.
+type LyricsEvent struct {
+	Track *vfs.Track
+	// Time of the line in milliseconds.
+	Time int
+	Text string
+}
+
+func (e *LyricsEvent) Name() string {
+	return "lyrics"
+}
+
+func (e *LyricsEvent) Serialize() []serialize.Serializable {
+	return []serialize.Serializable{serialize.Wrap(map[string]any{
+		"text":       e.Text,
+		"time":       e.Time,
+		"track-path": e.Track.Path.String(),
+	})}
+}
+
 type PlistCreateEvent struct {
 	Plist string
 }
@@ -0,0 +1,85 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package player
+
+import (
+	"github.com/vchimishuk/chub/logger"
+	"github.com/vchimishuk/chub/lyrics"
+	"github.com/vchimishuk/chub/vfs"
+)
+
+// position of the track being heard.
+type position struct {
+	track *vfs.Track
+	pos   int
+}
+
+// notifyPosition passes position to the lyrics goroutine. Engine's
+// output loop must not be blocked, so the position is dropped if
+// the previous one is not handled yet.
+func (p *Player) notifyPosition(track *vfs.Track, pos int) {
+	select {
+	case p.positions <- position{track, pos}:
+	default:
+	}
+}
+
+// lyricsLoop follows playback position and emits LyricsEvent every
+// time a new line of synchronized lyrics is reached.
+func (p *Player) lyricsLoop() {
+	var track *vfs.Track
+	var lrc *lyrics.Lyrics
+	line := -1
+
+	for ps := range p.positions {
+		if ps.track != track {
+			track = ps.track
+			lrc = syncedLyrics(track)
+			line = -1
+		}
+		if lrc == nil {
+			continue
+		}
+		n := lrc.LineAt(ps.pos)
+		if n != line {
+			line = n
+			if n >= 0 {
+				p.notify(&LyricsEvent{
+					Track: track,
+					Time:  lrc.Lines[n].Time,
+					Text:  lrc.Lines[n].Text,
+				})
+			}
+		}
+	}
+}
+
+// syncedLyrics returns synchronized lyrics of the track
+// or nil if it has no one.
+func syncedLyrics(track *vfs.Track) *lyrics.Lyrics {
+	l, err := track.Path.Lyrics()
+	if err != nil {
+		logger.Error("failed to load lyrics of %s: %s", track.Path, err)
+		return nil
+	}
+	if l == nil || !l.Synced {
+		return nil
+	}
+
+	return l
+}
@@ -17,16 +17,26 @@
 
 package player
 
+import (
+	"time"
+
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/vfs"
+)
+
 // Output interface represents audio autput driver (ALSA, OSS, ...).
 type Output interface {
 	// Open opens output audio device.
 	Open() error
-	// Set new value for sample rate parameter.
-	// TODO: Handle error by client.
+	// Set new value for sample rate parameter. Returns error if
+	// the rate is not supported by the device.
 	SetSampleRate(rate int) error
-	// Set number of channels.
-	// TODO: Handle error by client.
+	// Set number of channels. Returns error if the number
+	// of channels is not supported by the device.
 	SetChannels(channels int) error
+	// Set sample format. Returns error if the format
+	// is not supported by the device.
+	SetSampleFormat(f format.SampleFormat) error
 	// Write new portion of data into buffer.
 	Write(buf []byte) (written int, err error)
 	// Reset empties ouput buffer.
@@ -40,3 +50,30 @@ type Output interface {
 	// Set output volume level.
 	SetVolume(vol int) error
 }
+
+// TrackOutput is an optional interface implemented by output drivers
+// which need to know what track is being played (e.g. to split recorded
+// data by tracks or to pass track metadata to the sound server).
+type TrackOutput interface {
+	// SetTrack is called before the first portion of the track's data
+	// is written.
+	SetTrack(t *vfs.Track) error
+}
+
+// DelayOutput is an optional interface implemented by output drivers
+// which can tell how much written data is buffered and not heard yet.
+// It lets engine report the position which is actually being played.
+type DelayOutput interface {
+	// Delay returns time needed to play data written to the output
+	// but not played yet.
+	Delay() (time.Duration, error)
+}
+
+// StopOutput is an optional interface implemented by output drivers
+// which keep some state across Close and Open calls. Engine closes
+// output on every seek and manual track switch, while Stop is called
+// only when playback is stopped.
+type StopOutput interface {
+	// Stop is called after output is closed when playback stops.
+	Stop() error
+}
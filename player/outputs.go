@@ -0,0 +1,567 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package player
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/logger"
+	"github.com/vchimishuk/chub/serialize"
+	"github.com/vchimishuk/chub/vfs"
+)
+
+// OutputInfo describes output registered in Outputs.
+type OutputInfo struct {
+	ID      string
+	Enabled bool
+}
+
+func (i *OutputInfo) Serialize() string {
+	return serialize.Map(map[string]any{
+		"id":      i.ID,
+		"enabled": i.Enabled,
+	})
+}
+
+// Time to wait for an output to accept data when there are other
+// active outputs. Outputs which fall behind are deactivated, so they
+// do not stall the playback.
+const outputWriteTimeout = 5 * time.Second
+
+type outputEntry struct {
+	id     string
+	output Output
+	// Output is turned on by the user.
+	enabled bool
+	// Output is opened and receives data.
+	active bool
+	// Deactivated output is closed when its pending write returns.
+	closing bool
+	// Held while data is written to the output.
+	wmu sync.Mutex
+}
+
+// Outputs is an Output which passes the data to several outputs at once.
+// Every output can be enabled or disabled at runtime without stopping
+// the playback. Outputs which fail or fall behind are deactivated till
+// the next Open, so playback goes on while at least one output works.
+type Outputs struct {
+	// Mutex guards the fields below. It is not held while data
+	// is written, so slow outputs do not block control calls.
+	mu      sync.Mutex
+	outputs []*outputEntry
+	open    bool
+	paused  bool
+	rate    int
+	chans   int
+	sfmt    format.SampleFormat
+	volume  int
+	track   *vfs.Track
+	// Wakes up Write waiting for outputs when one is deactivated.
+	deactivated chan struct{}
+}
+
+func NewOutputs() *Outputs {
+	return &Outputs{
+		rate:        44100,
+		chans:       2,
+		sfmt:        format.SampleFormatS16,
+		deactivated: make(chan struct{}, 1),
+	}
+}
+
+// Add registers new enabled output with the given id.
+func (o *Outputs) Add(id string, output Output) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.find(id) != nil {
+		return errors.New("duplicate output")
+	}
+	o.outputs = append(o.outputs, &outputEntry{
+		id:      id,
+		output:  output,
+		enabled: true,
+	})
+
+	return nil
+}
+
+// List returns all registered outputs in order they were added.
+func (o *Outputs) List() []*OutputInfo {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	l := make([]*OutputInfo, 0, len(o.outputs))
+	for _, e := range o.outputs {
+		l = append(l, &OutputInfo{ID: e.id, Enabled: e.enabled})
+	}
+
+	return l
+}
+
+// Enable turns output on. If playback is in progress the output is
+// opened and configured for the current stream immediately.
+func (o *Outputs) Enable(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	e := o.find(id)
+	if e == nil {
+		return errors.New("no such output")
+	}
+	if e.enabled {
+		return nil
+	}
+	if e.closing {
+		return errors.New("output is busy")
+	}
+	if o.open {
+		err := o.activate(e)
+		if err != nil {
+			return err
+		}
+	}
+	e.enabled = true
+
+	return nil
+}
+
+// Disable turns output off closing it if playback is in progress.
+// The last enabled output cannot be disabled.
+func (o *Outputs) Disable(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	e := o.find(id)
+	if e == nil {
+		return errors.New("no such output")
+	}
+	if !e.enabled {
+		return nil
+	}
+	n := 0
+	for _, e := range o.outputs {
+		if e.enabled {
+			n++
+		}
+	}
+	if n == 1 {
+		return errors.New("cannot disable the last output")
+	}
+	e.enabled = false
+	if e.active {
+		return o.deactivate(e)
+	}
+
+	return nil
+}
+
+func (o *Outputs) Open() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var err error
+	n := 0
+	for _, e := range o.outputs {
+		if !e.enabled {
+			continue
+		}
+		if e.closing {
+			logger.Error("%s output is busy", e.id)
+			continue
+		}
+		err = e.output.Open()
+		if err != nil {
+			logger.Error("failed to open %s output: %s", e.id, err)
+			continue
+		}
+		e.active = true
+		n++
+	}
+	if n == 0 {
+		if err == nil {
+			err = errors.New("no enabled outputs")
+		}
+		return err
+	}
+	o.open = true
+	o.paused = false
+
+	return nil
+}
+
+func (o *Outputs) SetSampleRate(rate int) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	err := o.each(func(out Output) error {
+		return out.SetSampleRate(rate)
+	})
+	if err != nil {
+		return err
+	}
+	o.rate = rate
+
+	return nil
+}
+
+func (o *Outputs) SetChannels(chans int) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	err := o.each(func(out Output) error {
+		return out.SetChannels(chans)
+	})
+	if err != nil {
+		return err
+	}
+	o.chans = chans
+
+	return nil
+}
+
+func (o *Outputs) SetSampleFormat(f format.SampleFormat) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	err := o.each(func(out Output) error {
+		return out.SetSampleFormat(f)
+	})
+	if err != nil {
+		return err
+	}
+	o.sfmt = f
+
+	return nil
+}
+
+// SetTrack passes track to all outputs which implement TrackOutput.
+func (o *Outputs) SetTrack(t *vfs.Track) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.track = t
+
+	return o.each(func(out Output) error {
+		if to, ok := out.(TrackOutput); ok {
+			return to.SetTrack(t)
+		}
+		return nil
+	})
+}
+
+// Write passes data to all active outputs concurrently, so the slowest
+// output defines the pace. Failed outputs and outputs which do not
+// accept data in time are deactivated.
+func (o *Outputs) Write(buf []byte) (int, error) {
+	o.mu.Lock()
+	var active []*outputEntry
+	for _, e := range o.outputs {
+		if e.active {
+			active = append(active, e)
+		}
+	}
+	o.mu.Unlock()
+	if len(active) == 0 {
+		return 0, errors.New("no active outputs")
+	}
+
+	// Output which is left behind may still be writing
+	// after buf is reused by the caller.
+	data := slices.Clone(buf)
+	errs := make([]error, len(active))
+	done := make(chan int, len(active))
+	for i, e := range active {
+		go func(i int, e *outputEntry) {
+			e.wmu.Lock()
+			errs[i] = writeAll(e.output, data)
+			e.wmu.Unlock()
+			done <- i
+		}(i, e)
+	}
+
+	// Single output is waited for as long as it takes.
+	var timeout <-chan time.Time
+	if len(active) > 1 {
+		t := time.NewTimer(outputWriteTimeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+	finished := make([]bool, len(active))
+	pending := len(active)
+wait:
+	for pending > 0 {
+		select {
+		case i := <-done:
+			finished[i] = true
+			pending--
+		case <-o.deactivated:
+			// Do not wait for disabled outputs.
+			o.mu.Lock()
+			pending = 0
+			for i, e := range active {
+				if !finished[i] && e.active {
+					pending++
+				}
+			}
+			o.mu.Unlock()
+		case <-timeout:
+			break wait
+		}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var err error
+	n := 0
+	for i, e := range active {
+		if !finished[i] {
+			if e.active {
+				logger.Error("%s output is not responding", e.id)
+				o.deactivate(e)
+			}
+			continue
+		}
+		if errs[i] == nil {
+			n++
+			continue
+		}
+		logger.Error("%s output failed: %s", e.id, errs[i])
+		if err == nil {
+			err = errs[i]
+		}
+		if e.active {
+			o.deactivate(e)
+		}
+	}
+	if n == 0 {
+		if err == nil {
+			err = errors.New("no active outputs")
+		}
+		return 0, err
+	}
+
+	return len(buf), nil
+}
+
+func (o *Outputs) Flush() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.each(Output.Flush)
+}
+
+func (o *Outputs) Pause() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.paused = !o.paused
+
+	return o.each(Output.Pause)
+}
+
+func (o *Outputs) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	err := o.each(Output.Close)
+	for _, e := range o.outputs {
+		e.active = false
+	}
+	o.open = false
+	o.paused = false
+	o.track = nil
+
+	return err
+}
+
+// Stop passes playback stop notification to all the outputs
+// which implement StopOutput.
+func (o *Outputs) Stop() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var err error
+	for _, e := range o.outputs {
+		if so, ok := e.output.(StopOutput); ok {
+			if serr := so.Stop(); serr != nil && err == nil {
+				err = serr
+			}
+		}
+	}
+
+	return err
+}
+
+// Delay returns the largest delay reported by active outputs.
+// Outputs which do not implement DelayOutput are considered to have
+// no delay.
+func (o *Outputs) Delay() (time.Duration, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var delay time.Duration
+	for _, e := range o.outputs {
+		if !e.active {
+			continue
+		}
+		if do, ok := e.output.(DelayOutput); ok {
+			d, err := do.Delay()
+			if err != nil {
+				return 0, err
+			}
+			delay = max(delay, d)
+		}
+	}
+
+	return delay, nil
+}
+
+// Volume returns volume of the first active output.
+func (o *Outputs) Volume() (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, e := range o.outputs {
+		if e.active {
+			return e.output.Volume()
+		}
+	}
+
+	return o.volume, nil
+}
+
+func (o *Outputs) SetVolume(vol int) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.volume = vol
+
+	return o.each(func(out Output) error {
+		return out.SetVolume(vol)
+	})
+}
+
+// activate opens output and configures it for the current stream.
+// Must be called with mutex held.
+func (o *Outputs) activate(e *outputEntry) error {
+	out := e.output
+	err := out.Open()
+	if err != nil {
+		return err
+	}
+	err = out.SetSampleFormat(o.sfmt)
+	if err == nil {
+		err = out.SetSampleRate(o.rate)
+	}
+	if err == nil {
+		err = out.SetChannels(o.chans)
+	}
+	if err == nil {
+		err = out.SetVolume(o.volume)
+	}
+	if to, ok := out.(TrackOutput); ok && err == nil && o.track != nil {
+		err = to.SetTrack(o.track)
+	}
+	if err == nil && o.paused {
+		err = out.Pause()
+	}
+	if err != nil {
+		closeOutput(out)
+		return err
+	}
+	e.active = true
+
+	return nil
+}
+
+// deactivate stops passing data to the output and closes it.
+// Output which is still busy writing is closed when the write
+// returns. Must be called with mutex held.
+func (o *Outputs) deactivate(e *outputEntry) error {
+	e.active = false
+	select {
+	case o.deactivated <- struct{}{}:
+	default:
+	}
+
+	if e.wmu.TryLock() {
+		defer e.wmu.Unlock()
+		return closeOutput(e.output)
+	}
+	e.closing = true
+	go func() {
+		e.wmu.Lock()
+		err := closeOutput(e.output)
+		e.wmu.Unlock()
+		if err != nil {
+			logger.Error("failed to close %s output: %s", e.id, err)
+		}
+
+		o.mu.Lock()
+		e.closing = false
+		o.mu.Unlock()
+	}()
+
+	return nil
+}
+
+// each calls f for every active output. Returns the first error.
+// Must be called with mutex held.
+func (o *Outputs) each(f func(Output) error) error {
+	var err error
+	for _, e := range o.outputs {
+		if !e.active {
+			continue
+		}
+		if ferr := f(e.output); ferr != nil && err == nil {
+			err = ferr
+		}
+	}
+
+	return err
+}
+
+func (o *Outputs) find(id string) *outputEntry {
+	for _, e := range o.outputs {
+		if e.id == id {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// closeOutput closes output which is not going to be reopened
+// by the current playback.
+func closeOutput(out Output) error {
+	err := out.Close()
+	if so, ok := out.(StopOutput); ok {
+		if serr := so.Stop(); serr != nil && err == nil {
+			err = serr
+		}
+	}
+
+	return err
+}
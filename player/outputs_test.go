@@ -0,0 +1,251 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package player
+
+import (
+	"errors"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/vchimishuk/chub/assert"
+	"github.com/vchimishuk/chub/format"
+)
+
+// testOutput records data written and calls made.
+type testOutput struct {
+	open    bool
+	rate    int
+	data    []byte
+	failing bool
+	paused  bool
+	flushes int
+	sfmt    format.SampleFormat
+	// Sample formats output rejects.
+	unsupported []format.SampleFormat
+}
+
+// blockingOutput is a testOutput which blocks writes till unblocked.
+type blockingOutput struct {
+	testOutput
+	unblock chan struct{}
+}
+
+func (o *blockingOutput) Write(buf []byte) (int, error) {
+	<-o.unblock
+	return o.testOutput.Write(buf)
+}
+
+// delayOutput is a testOutput which reports fixed delay.
+type delayOutput struct {
+	testOutput
+	delay time.Duration
+}
+
+func (o *delayOutput) Delay() (time.Duration, error) {
+	return o.delay, nil
+}
+
+func (o *testOutput) Open() error {
+	o.open = true
+	return nil
+}
+
+func (o *testOutput) SetSampleRate(rate int) error {
+	o.rate = rate
+	return nil
+}
+
+func (o *testOutput) SetChannels(chans int) error {
+	return nil
+}
+
+func (o *testOutput) SetSampleFormat(f format.SampleFormat) error {
+	if slices.Contains(o.unsupported, f) {
+		return errors.New("unsupported sample format")
+	}
+	o.sfmt = f
+	return nil
+}
+
+func (o *testOutput) Write(buf []byte) (int, error) {
+	if o.failing {
+		return 0, errors.New("write failed")
+	}
+	o.data = append(o.data, buf...)
+	return len(buf), nil
+}
+
+func (o *testOutput) Flush() error {
+	o.flushes++
+	return nil
+}
+
+func (o *testOutput) Pause() error {
+	o.paused = !o.paused
+	return nil
+}
+
+func (o *testOutput) Close() error {
+	o.open = false
+	return nil
+}
+
+func (o *testOutput) Volume() (int, error) {
+	return 0, nil
+}
+
+func (o *testOutput) SetVolume(vol int) error {
+	return nil
+}
+
+func TestOutputsWrite(t *testing.T) {
+	a := &testOutput{}
+	b := &testOutput{}
+	o := NewOutputs()
+	assert.Nil(t, o.Add("a", a))
+	assert.Nil(t, o.Add("b", b))
+	assert.Error(t, o.Add("a", a), "duplicate output")
+
+	assert.Nil(t, o.Open())
+	assert.Nil(t, o.SetSampleRate(48000))
+	assert.True(t, a.rate == 48000 && b.rate == 48000)
+	n, err := o.Write([]byte{1, 2, 3, 4})
+	assert.Nil(t, err)
+	assert.True(t, n == 4)
+	assert.True(t, len(a.data) == 4 && len(b.data) == 4)
+
+	// Failed output is deactivated, playback goes on.
+	b.failing = true
+	_, err = o.Write([]byte{1, 2, 3, 4})
+	assert.Nil(t, err)
+	assert.True(t, len(a.data) == 8)
+	assert.True(t, !b.open)
+	a.failing = true
+	_, err = o.Write([]byte{1, 2, 3, 4})
+	assert.Error(t, err, "write failed")
+}
+
+func TestOutputsEnable(t *testing.T) {
+	a := &testOutput{}
+	b := &testOutput{}
+	o := NewOutputs()
+	assert.Nil(t, o.Add("a", a))
+	assert.Nil(t, o.Add("b", b))
+
+	assert.Nil(t, o.Disable("b"))
+	assert.Error(t, o.Disable("a"), "cannot disable the last output")
+	assert.Error(t, o.Disable("c"), "no such output")
+	l := o.List()
+	assert.True(t, len(l) == 2)
+	assert.True(t, l[0].ID == "a" && l[0].Enabled)
+	assert.True(t, l[1].ID == "b" && !l[1].Enabled)
+
+	assert.Nil(t, o.Open())
+	assert.Nil(t, o.SetSampleRate(96000))
+	assert.True(t, !b.open)
+	_, err := o.Write([]byte{1, 2})
+	assert.Nil(t, err)
+
+	// Enabled output is configured for the current stream.
+	assert.Nil(t, o.Enable("b"))
+	assert.True(t, b.open)
+	assert.True(t, b.rate == 96000)
+	_, err = o.Write([]byte{3, 4})
+	assert.Nil(t, err)
+	assert.True(t, len(a.data) == 4 && len(b.data) == 2)
+
+	assert.Nil(t, o.Disable("a"))
+	assert.True(t, !a.open)
+	assert.Nil(t, o.Close())
+	assert.True(t, !b.open)
+}
+
+func TestOutputsDisableBlocked(t *testing.T) {
+	a := &testOutput{}
+	b := &blockingOutput{unblock: make(chan struct{})}
+	o := NewOutputs()
+	assert.Nil(t, o.Add("a", a))
+	assert.Nil(t, o.Add("b", b))
+	assert.Nil(t, o.Open())
+
+	done := make(chan error)
+	go func() {
+		_, err := o.Write([]byte{1, 2})
+		done <- err
+	}()
+	// Control calls are not blocked by the stuck output
+	// and disabling it lets the playback go on.
+	for o.outputs[1].wmu.TryLock() {
+		o.outputs[1].wmu.Unlock()
+		runtime.Gosched()
+	}
+	assert.True(t, len(o.List()) == 2)
+	assert.Nil(t, o.Disable("b"))
+	assert.Nil(t, <-done)
+	assert.True(t, b.open)
+	assert.Error(t, o.Enable("b"), "output is busy")
+
+	close(b.unblock)
+	for {
+		o.mu.Lock()
+		closing := o.outputs[1].closing
+		o.mu.Unlock()
+		if !closing {
+			break
+		}
+		runtime.Gosched()
+	}
+	assert.True(t, !b.open)
+}
+
+func TestOutputsEnablePaused(t *testing.T) {
+	a := &testOutput{}
+	b := &testOutput{}
+	o := NewOutputs()
+	assert.Nil(t, o.Add("a", a))
+	assert.Nil(t, o.Add("b", b))
+	assert.Nil(t, o.Disable("b"))
+	assert.Nil(t, o.Open())
+
+	assert.Nil(t, o.Pause())
+	assert.Nil(t, o.Enable("b"))
+	assert.True(t, a.paused && b.paused)
+	assert.Nil(t, o.Pause())
+	assert.True(t, !a.paused && !b.paused)
+}
+
+func TestOutputsDelay(t *testing.T) {
+	a := &testOutput{}
+	b := &delayOutput{delay: 100 * time.Millisecond}
+	c := &delayOutput{delay: 200 * time.Millisecond}
+	o := NewOutputs()
+	assert.Nil(t, o.Add("a", a))
+	assert.Nil(t, o.Add("b", b))
+	assert.Nil(t, o.Add("c", c))
+	assert.Nil(t, o.Open())
+
+	d, err := o.Delay()
+	assert.Nil(t, err)
+	assert.True(t, d == 200*time.Millisecond)
+	assert.Nil(t, o.Disable("c"))
+	d, err = o.Delay()
+	assert.Nil(t, err)
+	assert.True(t, d == 100*time.Millisecond)
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package player
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+
+	"github.com/vchimishuk/chub/format"
+)
+
+const (
+	maxS24 = 1<<23 - 1
+	minS24 = -1 << 23
+)
+
+// sampleAt returns i-th sample from PCM buffer normalized
+// to -1.0..1.0 range.
+func sampleAt(buf []byte, f format.SampleFormat, i int) float64 {
+	switch f {
+	case format.SampleFormatS16:
+		s := int16(binary.NativeEndian.Uint16(buf[2*i:]))
+		return float64(s) / -math.MinInt16
+	case format.SampleFormatS24:
+		// Sign-extend low three bytes.
+		s := int32(binary.NativeEndian.Uint32(buf[4*i:])<<8) >> 8
+		return float64(s) / -minS24
+	case format.SampleFormatS32:
+		s := int32(binary.NativeEndian.Uint32(buf[4*i:]))
+		return float64(s) / -math.MinInt32
+	case format.SampleFormatF32:
+		return float64(math.Float32frombits(
+			binary.NativeEndian.Uint32(buf[4*i:])))
+	default:
+		panic("unsupported sample format")
+	}
+}
+
+// setSampleAt stores normalized sample value into i-th position
+// of PCM buffer clipping it if needed.
+func setSampleAt(buf []byte, f format.SampleFormat, i int, v float64) {
+	switch f {
+	case format.SampleFormatS16:
+		s := clip(math.Round(v*-math.MinInt16), math.MinInt16,
+			math.MaxInt16)
+		binary.NativeEndian.PutUint16(buf[2*i:], uint16(int16(s)))
+	case format.SampleFormatS24:
+		s := clip(math.Round(v*-minS24), minS24, maxS24)
+		binary.NativeEndian.PutUint32(buf[4*i:], uint32(int32(s)))
+	case format.SampleFormatS32:
+		s := clip(math.Round(v*-math.MinInt32), math.MinInt32,
+			math.MaxInt32)
+		binary.NativeEndian.PutUint32(buf[4*i:], uint32(int32(s)))
+	case format.SampleFormatF32:
+		s := clip(v, -1, 1)
+		binary.NativeEndian.PutUint32(buf[4*i:],
+			math.Float32bits(float32(s)))
+	default:
+		panic("unsupported sample format")
+	}
+}
+
+// ConvertSamples converts PCM data from one sample format to another
+// appending the result to dst.
+func ConvertSamples(dst []byte, src []byte, from format.SampleFormat,
+	to format.SampleFormat) []byte {
+
+	n := len(src) / from.Size()
+	off := len(dst)
+	dst = slices.Grow(dst, n*to.Size())[:off+n*to.Size()]
+	for i := 0; i < n; i++ {
+		setSampleAt(dst[off:], to, i, sampleAt(src, from, i))
+	}
+
+	return dst
+}
+
+func clip(v float64, lo float64, hi float64) float64 {
+	return max(lo, min(hi, v))
+}
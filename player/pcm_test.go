@@ -0,0 +1,64 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package player
+
+import (
+	"math"
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+	"github.com/vchimishuk/chub/format"
+)
+
+func TestSampleRoundTrip(t *testing.T) {
+	fmts := []format.SampleFormat{
+		format.SampleFormatS16,
+		format.SampleFormatS24,
+		format.SampleFormatS32,
+		format.SampleFormatF32,
+	}
+	vals := []float64{0, 0.5, -0.5, -1}
+
+	for _, f := range fmts {
+		buf := make([]byte, len(vals)*f.Size())
+		for i, v := range vals {
+			setSampleAt(buf, f, i, v)
+		}
+		for i, v := range vals {
+			assert.True(t, math.Abs(sampleAt(buf, f, i)-v) < 1e-4)
+		}
+	}
+}
+
+func TestSampleClip(t *testing.T) {
+	buf := make([]byte, 4)
+	setSampleAt(buf, format.SampleFormatS24, 0, 2)
+	assert.True(t, sampleAt(buf, format.SampleFormatS24, 0) < 1)
+	setSampleAt(buf, format.SampleFormatS24, 0, -2)
+	assert.True(t, sampleAt(buf, format.SampleFormatS24, 0) == -1)
+}
+
+func TestConvertSamples(t *testing.T) {
+	src := pcm(format.SampleFormatS16, 0.5, -0.25)
+	dst := ConvertSamples([]byte{1}, src, format.SampleFormatS16,
+		format.SampleFormatF32)
+	assert.True(t, len(dst) == 1+2*format.SampleFormatF32.Size())
+	assert.True(t, dst[0] == 1)
+	assert.True(t, sampleAt(dst[1:], format.SampleFormatF32, 0) == 0.5)
+	assert.True(t, sampleAt(dst[1:], format.SampleFormatF32, 1) == -0.25)
+}
@@ -19,9 +19,9 @@ package player
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 
-	"github.com/vchimishuk/chub/format"
 	"github.com/vchimishuk/chub/vfs"
 )
 
@@ -39,27 +39,35 @@ type Player struct {
 	plistsMu sync.RWMutex
 	plists   map[string]*Playlist
 	curPlist *Playlist
-	// Used output driver.
-	output Output
+	// Used output drivers.
+	outputs *Outputs
 	// Output volume level. 0..100
 	outputVol int
+	// Equalizer presets available by name.
+	eqPresets map[string][]int
 	// Playback engine.
 	engine *Engine
 	// Channel to notify client that player state has been changed.
 	events chan Event
+	// Positions of the track being heard for lyrics goroutine.
+	positions chan position
 }
 
-func New(fmts []format.Format, output Output) *Player {
+func New(outputs *Outputs) *Player {
 	p := &Player{
 		plists:    make(map[string]*Playlist),
 		curPlist:  NewPlaylist(vfsPlistName),
-		output:    output,
+		outputs:   outputs,
 		outputVol: 50,
-		engine:    NewEngine(fmts, output),
+		eqPresets: make(map[string][]int),
+		engine:    NewEngine(outputs),
 		events:    make(chan Event, eventsChSize),
+		positions: make(chan position, 1),
 	}
 	p.engine.Start()
 	p.engine.SetStatusHandler(p.notifyStatus)
+	p.engine.SetPositionHandler(p.notifyPosition)
+	go p.lyricsLoop()
 
 	return p
 }
@@ -69,7 +77,12 @@ func (p *Player) Events() <-chan Event {
 }
 
 func (p *Player) Close() error {
-	return p.engine.Close()
+	err := p.engine.Close()
+	// Engine does not report positions after it is closed,
+	// so lyrics goroutine can be stopped.
+	close(p.positions)
+
+	return err
 }
 
 func (p *Player) Play(path *vfs.Path) error {
@@ -136,6 +149,8 @@ func (p *Player) Prev() error {
 	return p.engine.Prev()
 }
 
+// Seek sets position of the current track in milliseconds. If rel
+// is true pos is an offset from the current position.
 func (p *Player) Seek(pos int, rel bool) error {
 	return p.engine.Seek(pos, rel)
 }
@@ -158,6 +173,120 @@ func (p *Player) SetVolume(vol int, rel bool) error {
 	return nil
 }
 
+// SetOutputRate sets fixed output sample rate. See Engine.SetOutputRate.
+func (p *Player) SetOutputRate(rate int) error {
+	return p.engine.SetOutputRate(rate)
+}
+
+// Outputs returns all configured outputs.
+func (p *Player) Outputs() []*OutputInfo {
+	return p.outputs.List()
+}
+
+// EnableOutput turns output with the given id on.
+func (p *Player) EnableOutput(id string) error {
+	return p.outputs.Enable(id)
+}
+
+// DisableOutput turns output with the given id off.
+func (p *Player) DisableOutput(id string) error {
+	return p.outputs.Disable(id)
+}
+
+func (p *Player) EqEnabled() bool {
+	return p.engine.Equalizer().Enabled()
+}
+
+func (p *Player) EqGains() []int {
+	return p.engine.Equalizer().Gains()
+}
+
+// SetEq restores equalizer settings, e.g. from saved state.
+func (p *Player) SetEq(enabled bool, gains []int) error {
+	eq := p.engine.Equalizer()
+	if gains != nil {
+		err := eq.SetGains(gains)
+		if err != nil {
+			return err
+		}
+	}
+	eq.SetEnabled(enabled)
+
+	return nil
+}
+
+// SetEqEnabled turns equalizer on or off keeping bands gains untouched.
+func (p *Player) SetEqEnabled(enabled bool) error {
+	p.engine.Equalizer().SetEnabled(enabled)
+
+	return nil
+}
+
+// SetEqGain changes single band gain and turns equalizer on.
+func (p *Player) SetEqGain(band int, gain int) error {
+	eq := p.engine.Equalizer()
+	err := eq.SetGain(band, gain)
+	if err != nil {
+		return err
+	}
+	eq.SetEnabled(true)
+
+	return nil
+}
+
+// SetEqPreset applies named preset and turns equalizer on.
+func (p *Player) SetEqPreset(name string) error {
+	gains, ok := p.eqPresets[name]
+	if !ok {
+		return errors.New("invalid preset")
+	}
+
+	return p.SetEq(true, gains)
+}
+
+// SetEqPresets sets available equalizer presets.
+func (p *Player) SetEqPresets(presets map[string][]int) error {
+	for name, gains := range presets {
+		if len(gains) != len(EqBands) {
+			return fmt.Errorf("%s: invalid number of bands", name)
+		}
+	}
+	p.eqPresets = presets
+
+	return nil
+}
+
+func (p *Player) Balance() int {
+	return p.engine.ChannelMixer().Balance()
+}
+
+// SetBalance sets stereo balance -100..100.
+func (p *Player) SetBalance(b int) error {
+	return p.engine.ChannelMixer().SetBalance(b)
+}
+
+func (p *Player) Mono() bool {
+	return p.engine.ChannelMixer().Mono()
+}
+
+// SetMono turns mono downmix of stereo output on or off.
+func (p *Player) SetMono(mono bool) error {
+	p.engine.ChannelMixer().SetMono(mono)
+
+	return nil
+}
+
+func (p *Player) SwapChannels() bool {
+	return p.engine.ChannelMixer().Swap()
+}
+
+// SetSwapChannels turns left and right channels swapping on or off.
+func (p *Player) SetSwapChannels(swap bool) error {
+	p.engine.ChannelMixer().SetSwap(swap)
+
+	return nil
+}
+
 func (p *Player) Append(name string, path *vfs.Path) error {
 	p.plistsMu.Lock()
 	defer p.plistsMu.Unlock()
@@ -315,9 +444,12 @@ func (p *Player) replace(name string, pl *Playlist) {
 	}
 }
 
+// notify sends event to the events channel. Event is dropped
+// if the channel is full.
 func (p *Player) notify(e Event) {
-	if len(p.events) < eventsChSize {
-		p.events <- e
+	select {
+	case p.events <- e:
+	default:
 	}
 }
 
@@ -0,0 +1,227 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package player
+
+import (
+	"io"
+	"math"
+
+	"github.com/vchimishuk/chub/format"
+)
+
+const (
+	// Number of filter zero crossings on every side of the sinc kernel.
+	resamplerTaps = 16
+	// Number of precomputed kernel values per one input sample.
+	resamplerPhases = 256
+	// Number of frames read from the source at once.
+	resamplerChunk = 1024
+)
+
+// Resampler converts sample rate of PCM stream read from the underlying
+// reader. Resampler uses windowed sinc interpolation, so it is suitable
+// for both upsampling and downsampling.
+type Resampler struct {
+	src      io.Reader
+	channels int
+	sfmt     format.SampleFormat
+	// Input frames per one output frame.
+	step float64
+	// Kernel half width in input frames.
+	width int
+	// Precomputed kernel values for distances 0..width with
+	// 1/resamplerPhases step.
+	kernel []float64
+	// Buffered input samples, one slice per channel.
+	in [][]float64
+	// Current output frame position relative to the first frame
+	// in the input buffer.
+	pos float64
+	// Source has been drained.
+	eof bool
+	// Number of silent frames appended after source end,
+	// so the last input frames are interpolated properly.
+	tail int
+	// Raw data read from the source.
+	raw []byte
+	// Number of bytes in raw not processed yet.
+	rawLen int
+}
+
+// NewResampler returns resampler which reads PCM data at inRate from src
+// and produces data at outRate. Sample format and number of channels
+// are kept untouched.
+func NewResampler(src io.Reader, inRate int, outRate int, channels int,
+	sfmt format.SampleFormat) *Resampler {
+
+	step := float64(inRate) / float64(outRate)
+	// Lowpass cutoff relative to the input Nyquist frequency.
+	// Kernel has to be stretched when downsampling to avoid aliasing.
+	cutoff := min(1, 1/step)
+	width := int(math.Ceil(resamplerTaps / cutoff))
+
+	kernel := make([]float64, width*resamplerPhases+1)
+	for i := range kernel {
+		x := float64(i) / resamplerPhases
+		kernel[i] = cutoff * sinc(cutoff*x) *
+			blackman(x/float64(width))
+	}
+
+	in := make([][]float64, channels)
+	for ch := range in {
+		// Start with silence, so the first frame is placed
+		// in the middle of the kernel.
+		in[ch] = make([]float64, width)
+	}
+
+	return &Resampler{
+		src:      src,
+		channels: channels,
+		sfmt:     sfmt,
+		step:     step,
+		width:    width,
+		kernel:   kernel,
+		in:       in,
+		pos:      float64(width),
+		raw:      make([]byte, resamplerChunk*channels*sfmt.Size()),
+	}
+}
+
+// Read fills buf with resampled data. Returns 0 at the end of the stream.
+func (r *Resampler) Read(buf []byte) (int, error) {
+	fsz := r.channels * r.sfmt.Size()
+	frames := len(buf) / fsz
+	n := 0
+
+	for n < frames {
+		// Make sure we have enough input frames to compute
+		// the next output frame.
+		need := int(r.pos) + r.width + 1
+		for len(r.in[0]) < need {
+			if r.eof {
+				if r.tail >= r.width {
+					break
+				}
+				r.appendSilence(need - len(r.in[0]))
+			} else {
+				err := r.fill()
+				if err != nil {
+					return 0, err
+				}
+			}
+		}
+		if len(r.in[0]) < need {
+			// Source end and tail drained.
+			break
+		}
+
+		for ch := 0; ch < r.channels; ch++ {
+			setSampleAt(buf, r.sfmt, n*r.channels+ch,
+				r.interpolate(r.in[ch], r.pos))
+		}
+		n++
+		r.pos += r.step
+	}
+	r.compact()
+
+	return n * fsz, nil
+}
+
+// fill reads next chunk of data from the source.
+func (r *Resampler) fill() error {
+	fsz := r.channels * r.sfmt.Size()
+	n, err := r.src.Read(r.raw[r.rawLen:])
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if n == 0 {
+		r.eof = true
+		return nil
+	}
+	r.rawLen += n
+
+	frames := r.rawLen / fsz
+	for i := 0; i < frames; i++ {
+		for ch := 0; ch < r.channels; ch++ {
+			r.in[ch] = append(r.in[ch],
+				sampleAt(r.raw, r.sfmt, i*r.channels+ch))
+		}
+	}
+	// Keep incomplete frame for the next time.
+	r.rawLen = copy(r.raw, r.raw[frames*fsz:r.rawLen])
+
+	return nil
+}
+
+func (r *Resampler) appendSilence(n int) {
+	n = min(n, r.width-r.tail)
+	for ch := 0; ch < r.channels; ch++ {
+		r.in[ch] = append(r.in[ch], make([]float64, n)...)
+	}
+	r.tail += n
+}
+
+// compact drops input frames which are not needed any more.
+func (r *Resampler) compact() {
+	drop := int(r.pos) - r.width
+	if drop < resamplerChunk {
+		return
+	}
+	for ch := 0; ch < r.channels; ch++ {
+		r.in[ch] = append(r.in[ch][:0], r.in[ch][drop:]...)
+	}
+	r.pos -= float64(drop)
+}
+
+// interpolate computes sample value at fractional position pos.
+func (r *Resampler) interpolate(in []float64, pos float64) float64 {
+	var v float64
+
+	c := int(pos)
+	for i := c - r.width + 1; i <= c+r.width; i++ {
+		d := math.Abs(pos-float64(i)) * resamplerPhases
+		k := int(d)
+		if k >= len(r.kernel)-1 {
+			continue
+		}
+		// Linear interpolation between precomputed kernel values.
+		f := d - float64(k)
+		w := r.kernel[k]*(1-f) + r.kernel[k+1]*f
+		v += in[i] * w
+	}
+
+	return v
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	x *= math.Pi
+
+	return math.Sin(x) / x
+}
+
+// blackman returns Blackman window value for -1..1 range.
+func blackman(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+
+	return 0.42 + 0.5*math.Cos(math.Pi*x) + 0.08*math.Cos(2*math.Pi*x)
+}
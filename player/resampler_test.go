@@ -0,0 +1,63 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package player
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+	"github.com/vchimishuk/chub/format"
+)
+
+func readAll(r *Resampler) []byte {
+	var out []byte
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		if err != nil || n == 0 {
+			return out
+		}
+		out = append(out, buf[:n]...)
+	}
+}
+
+func TestResamplerLength(t *testing.T) {
+	in := sine(1000, 44100, 44100)
+	r := NewResampler(bytes.NewReader(in), 44100, 48000, 2,
+		format.SampleFormatS16)
+	out := readAll(r)
+	frames := len(out) / 4
+	assert.True(t, math.Abs(float64(frames-48000)) < 64)
+}
+
+func TestResamplerSignal(t *testing.T) {
+	in := sine(1000, 96000, 96000)
+	r := NewResampler(bytes.NewReader(in), 96000, 44100, 2,
+		format.SampleFormatS16)
+	out := readAll(r)
+	exp := sine(1000, 44100, 44100)
+
+	// Skip filter delay at the beginning and the end.
+	for i := 1000; i < 40000; i++ {
+		a := sampleAt(out, format.SampleFormatS16, 2*i)
+		b := sampleAt(exp, format.SampleFormatS16, 2*i)
+		assert.True(t, math.Abs(a-b) < 0.01)
+	}
+}
@@ -0,0 +1,457 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+#include <stdlib.h>
+#include <string.h>
+#include <pulse/pulseaudio.h>
+#include "pulse.h"
+
+// Target length of the server side buffer.
+#define PULSE_LATENCY_USEC 250000
+
+
+static void pulse_context_state_cb(pa_context *c, void *userdata)
+{
+    struct pulse *p = userdata;
+    pa_threaded_mainloop_signal(p->mainloop, 0);
+}
+
+static void pulse_stream_state_cb(pa_stream *s, void *userdata)
+{
+    struct pulse *p = userdata;
+    pa_threaded_mainloop_signal(p->mainloop, 0);
+}
+
+static void pulse_stream_request_cb(pa_stream *s, size_t nbytes,
+        void *userdata)
+{
+    struct pulse *p = userdata;
+    pa_threaded_mainloop_signal(p->mainloop, 0);
+}
+
+static void pulse_stream_success_cb(pa_stream *s, int success, void *userdata)
+{
+    struct pulse *p = userdata;
+    pa_threaded_mainloop_signal(p->mainloop, 0);
+}
+
+static void pulse_context_success_cb(pa_context *c, int success,
+        void *userdata)
+{
+    struct pulse *p = userdata;
+    pa_threaded_mainloop_signal(p->mainloop, 0);
+}
+
+// Return negative error code of the last failed context operation.
+static int pulse_error(struct pulse *p)
+{
+    int e = pa_context_errno(p->context);
+    if (e == 0) {
+        e = PA_ERR_UNKNOWN;
+    }
+
+    return -e;
+}
+
+// Wait for the operation to complete.
+// Must be called with mainloop locked.
+static int pulse_wait(struct pulse *p, pa_operation *o)
+{
+    if (!o) {
+        return pulse_error(p);
+    }
+    while (pa_operation_get_state(o) == PA_OPERATION_RUNNING) {
+        pa_threaded_mainloop_wait(p->mainloop);
+    }
+    pa_operation_unref(o);
+
+    return 0;
+}
+
+static pa_sample_format_t pulse_format(int fmt)
+{
+    switch (fmt) {
+    case PULSE_SAMPLE_FMT_S24:
+        return PA_SAMPLE_S24_32NE;
+    case PULSE_SAMPLE_FMT_S32:
+        return PA_SAMPLE_S32NE;
+    case PULSE_SAMPLE_FMT_F32:
+        return PA_SAMPLE_FLOAT32NE;
+    default:
+        return PA_SAMPLE_S16NE;
+    }
+}
+
+static void pulse_cvolume(pa_cvolume *cv, int channels, int vol)
+{
+    // Percents match the ones shown by the sound server mixers.
+    pa_cvolume_set(cv, channels, (pa_volume_t) PA_VOLUME_NORM * vol / 100);
+}
+
+// Return property list describing the current track.
+// Must be freed with pa_proplist_free().
+static pa_proplist *pulse_proplist(struct pulse *p)
+{
+    pa_proplist *pl = pa_proplist_new();
+
+    pa_proplist_sets(pl, PA_PROP_MEDIA_ROLE, "music");
+    if (p->title) {
+        pa_proplist_sets(pl, PA_PROP_MEDIA_TITLE, p->title);
+        pa_proplist_sets(pl, PA_PROP_MEDIA_NAME, p->title);
+    }
+    if (p->artist) {
+        pa_proplist_sets(pl, PA_PROP_MEDIA_ARTIST, p->artist);
+    }
+    if (p->album) {
+        pa_proplist_sets(pl, "media.album", p->album);
+    }
+    if (p->filename) {
+        pa_proplist_sets(pl, PA_PROP_MEDIA_FILENAME, p->filename);
+    }
+
+    return pl;
+}
+
+static char *pulse_strdup(const char *s)
+{
+    if (!s || !*s) {
+        return NULL;
+    }
+
+    return strdup(s);
+}
+
+// Allocate and initialize pulse structure.
+// pulse_free() must be called to free allocated memory.
+struct pulse *pulse_alloc()
+{
+    struct pulse *p = malloc(sizeof(struct pulse));
+    memset(p, 0, sizeof(struct pulse));
+
+    return p;
+}
+
+// Free structure allocated by pulse_alloc().
+void pulse_free(struct pulse *p)
+{
+    free(p->title);
+    free(p->artist);
+    free(p->album);
+    free(p->filename);
+    free(p);
+}
+
+// Connect to the sound server. NULL server means the default one.
+// Returns 0 if success or negative PulseAudio error code.
+int pulse_open(struct pulse *p, const char *server, const char *name)
+{
+    p->mainloop = pa_threaded_mainloop_new();
+    if (!p->mainloop) {
+        return -PA_ERR_INTERNAL;
+    }
+    p->context = pa_context_new(pa_threaded_mainloop_get_api(p->mainloop),
+            name);
+    if (!p->context) {
+        pulse_close(p);
+        return -PA_ERR_INTERNAL;
+    }
+    pa_context_set_state_callback(p->context, pulse_context_state_cb, p);
+    if (pa_threaded_mainloop_start(p->mainloop) < 0) {
+        pulse_close(p);
+        return -PA_ERR_INTERNAL;
+    }
+
+    pa_threaded_mainloop_lock(p->mainloop);
+    int err = 0;
+    if (pa_context_connect(p->context, server, PA_CONTEXT_NOFLAGS, NULL) < 0) {
+        err = pulse_error(p);
+    }
+    while (!err) {
+        pa_context_state_t st = pa_context_get_state(p->context);
+        if (st == PA_CONTEXT_READY) {
+            break;
+        }
+        if (!PA_CONTEXT_IS_GOOD(st)) {
+            err = pulse_error(p);
+            break;
+        }
+        pa_threaded_mainloop_wait(p->mainloop);
+    }
+    pa_threaded_mainloop_unlock(p->mainloop);
+
+    if (err) {
+        pulse_close(p);
+    }
+
+    return err;
+}
+
+// Close stream and disconnect from the server.
+void pulse_close(struct pulse *p)
+{
+    pulse_stream_close(p);
+    if (p->mainloop) {
+        pa_threaded_mainloop_stop(p->mainloop);
+    }
+    if (p->context) {
+        pa_context_disconnect(p->context);
+        pa_context_unref(p->context);
+        p->context = NULL;
+    }
+    if (p->mainloop) {
+        pa_threaded_mainloop_free(p->mainloop);
+        p->mainloop = NULL;
+    }
+}
+
+// Return non-zero if the server can play stream with given parameters.
+int pulse_spec_valid(int rate, int channels, int fmt)
+{
+    pa_sample_spec ss = {
+        .format = pulse_format(fmt),
+        .rate = rate,
+        .channels = channels,
+    };
+
+    return pa_sample_spec_valid(&ss);
+}
+
+// Create playback stream with given parameters. NULL sink means
+// the default one.
+int pulse_stream_open(struct pulse *p, const char *sink, int rate,
+        int channels, int fmt)
+{
+    pa_sample_spec ss = {
+        .format = pulse_format(fmt),
+        .rate = rate,
+        .channels = channels,
+    };
+    if (!pa_sample_spec_valid(&ss)) {
+        return -PA_ERR_INVALID;
+    }
+    // Channels are ordered as in WAV files.
+    pa_channel_map map;
+    if (!pa_channel_map_init_extend(&map, channels, PA_CHANNEL_MAP_WAVEEX)) {
+        return -PA_ERR_INVALID;
+    }
+
+    pa_threaded_mainloop_lock(p->mainloop);
+
+    pa_proplist *pl = pulse_proplist(p);
+    p->stream = pa_stream_new_with_proplist(p->context, "Playback", &ss,
+            &map, pl);
+    pa_proplist_free(pl);
+    if (!p->stream) {
+        int err = pulse_error(p);
+        pa_threaded_mainloop_unlock(p->mainloop);
+        return err;
+    }
+    pa_stream_set_state_callback(p->stream, pulse_stream_state_cb, p);
+    pa_stream_set_write_callback(p->stream, pulse_stream_request_cb, p);
+
+    pa_buffer_attr attr = {
+        .maxlength = (uint32_t) -1,
+        .tlength = pa_usec_to_bytes(PULSE_LATENCY_USEC, &ss),
+        .prebuf = (uint32_t) -1,
+        .minreq = (uint32_t) -1,
+        .fragsize = (uint32_t) -1,
+    };
+    pa_cvolume cv;
+    pulse_cvolume(&cv, channels, p->volume);
+    pa_stream_flags_t flags = PA_STREAM_ADJUST_LATENCY
+        | PA_STREAM_INTERPOLATE_TIMING | PA_STREAM_AUTO_TIMING_UPDATE;
+
+    int err = 0;
+    if (pa_stream_connect_playback(p->stream, sink, &attr, flags,
+            &cv, NULL) < 0) {
+        err = pulse_error(p);
+    }
+    while (!err) {
+        pa_stream_state_t st = pa_stream_get_state(p->stream);
+        if (st == PA_STREAM_READY) {
+            break;
+        }
+        if (!PA_STREAM_IS_GOOD(st)) {
+            err = pulse_error(p);
+            break;
+        }
+        pa_threaded_mainloop_wait(p->mainloop);
+    }
+
+    pa_threaded_mainloop_unlock(p->mainloop);
+
+    if (err) {
+        pulse_stream_close(p);
+    }
+
+    return err;
+}
+
+// Close playback stream dropping all the buffered data.
+void pulse_stream_close(struct pulse *p)
+{
+    if (!p->stream) {
+        return;
+    }
+    pa_threaded_mainloop_lock(p->mainloop);
+    pa_stream_disconnect(p->stream);
+    pa_stream_unref(p->stream);
+    p->stream = NULL;
+    pa_threaded_mainloop_unlock(p->mainloop);
+}
+
+// Write data to the stream blocking until the server is ready
+// to accept it. Returns number of bytes written or negative
+// error code.
+int pulse_write(struct pulse *p, const void *buf, int len)
+{
+    size_t frame = pa_frame_size(pa_stream_get_sample_spec(p->stream));
+    size_t n;
+    int err = 0;
+
+    pa_threaded_mainloop_lock(p->mainloop);
+    for (;;) {
+        if (!PA_STREAM_IS_GOOD(pa_stream_get_state(p->stream))) {
+            err = pulse_error(p);
+            break;
+        }
+        n = pa_stream_writable_size(p->stream);
+        if (n == (size_t) -1) {
+            err = pulse_error(p);
+            break;
+        }
+        n -= n % frame;
+        if (n > 0) {
+            break;
+        }
+        pa_threaded_mainloop_wait(p->mainloop);
+    }
+    if (!err) {
+        if (n > (size_t) len) {
+            n = len;
+        }
+        if (pa_stream_write(p->stream, buf, n, NULL, 0,
+                PA_SEEK_RELATIVE) < 0) {
+            err = pulse_error(p);
+        }
+    }
+    pa_threaded_mainloop_unlock(p->mainloop);
+
+    return err ? err : (int) n;
+}
+
+// Return number of microseconds needed to play data written to
+// the stream but not played yet or negative error code.
+long pulse_delay(struct pulse *p)
+{
+    if (!p->stream) {
+        return 0;
+    }
+
+    pa_threaded_mainloop_lock(p->mainloop);
+    pa_usec_t usec;
+    int neg;
+    long r;
+    int e = pa_stream_get_latency(p->stream, &usec, &neg);
+    if (e == -PA_ERR_NODATA) {
+        // No timing info received yet.
+        r = 0;
+    } else if (e < 0) {
+        r = e;
+    } else {
+        r = neg ? 0 : (long) usec;
+    }
+    pa_threaded_mainloop_unlock(p->mainloop);
+
+    return r;
+}
+
+// Pause or resume the stream.
+int pulse_cork(struct pulse *p, int cork)
+{
+    if (!p->stream) {
+        return 0;
+    }
+    pa_threaded_mainloop_lock(p->mainloop);
+    int err = pulse_wait(p, pa_stream_cork(p->stream, cork,
+                pulse_stream_success_cb, p));
+    pa_threaded_mainloop_unlock(p->mainloop);
+
+    return err;
+}
+
+// Drop all the data buffered by the server.
+int pulse_flush(struct pulse *p)
+{
+    if (!p->stream) {
+        return 0;
+    }
+    pa_threaded_mainloop_lock(p->mainloop);
+    int err = pulse_wait(p, pa_stream_flush(p->stream,
+                pulse_stream_success_cb, p));
+    pa_threaded_mainloop_unlock(p->mainloop);
+
+    return err;
+}
+
+// Set stream volume 0..100. Volume is applied to the stream
+// when it is opened if there is no stream yet.
+int pulse_set_volume(struct pulse *p, int vol)
+{
+    p->volume = vol;
+    if (!p->stream) {
+        return 0;
+    }
+
+    pa_threaded_mainloop_lock(p->mainloop);
+    pa_cvolume cv;
+    pulse_cvolume(&cv, pa_stream_get_sample_spec(p->stream)->channels, vol);
+    int err = pulse_wait(p, pa_context_set_sink_input_volume(p->context,
+                pa_stream_get_index(p->stream), &cv,
+                pulse_context_success_cb, p));
+    pa_threaded_mainloop_unlock(p->mainloop);
+
+    return err;
+}
+
+// Set properties of the track being played. Properties are passed
+// to the server with the stream, so mixers and notification daemons
+// can show them.
+int pulse_set_track(struct pulse *p, const char *title, const char *artist,
+        const char *album, const char *filename)
+{
+    free(p->title);
+    free(p->artist);
+    free(p->album);
+    free(p->filename);
+    p->title = pulse_strdup(title);
+    p->artist = pulse_strdup(artist);
+    p->album = pulse_strdup(album);
+    p->filename = pulse_strdup(filename);
+    if (!p->stream) {
+        return 0;
+    }
+
+    pa_threaded_mainloop_lock(p->mainloop);
+    pa_proplist *pl = pulse_proplist(p);
+    int err = pulse_wait(p, pa_stream_proplist_update(p->stream,
+                PA_UPDATE_REPLACE, pl, pulse_stream_success_cb, p));
+    pa_proplist_free(pl);
+    pa_threaded_mainloop_unlock(p->mainloop);
+
+    return err;
+}
@@ -0,0 +1,292 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build pulse
+
+// Package pulse implements output to PulseAudio compatible sound
+// servers, including PipeWire with pipewire-pulse.
+package pulse
+
+// #cgo pkg-config: libpulse
+// #include <stdlib.h>
+// #include "pulse.h"
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/vfs"
+)
+
+// Client name the server shows in its mixers.
+const clientName = "Chub"
+
+type Pulse struct {
+	server string
+	sink   string
+	p      *C.struct_pulse
+	// Stream is created lazily on first write, so format can be
+	// changed without reopening it multiple times.
+	stream bool
+	rate   int
+	chans  int
+	sfmt   format.SampleFormat
+	paused bool
+	volume int
+}
+
+// New returns output which plays to the sink of the server.
+// Empty server or sink means the default one.
+func New(server, sink string) *Pulse {
+	return &Pulse{
+		server: server,
+		sink:   sink,
+		rate:   44100,
+		chans:  2,
+		sfmt:   format.SampleFormatS16,
+		volume: 100,
+	}
+}
+
+func (p *Pulse) Open() error {
+	if p.p != nil {
+		return errors.New("output is already opened")
+	}
+
+	var csrv *C.char
+	if p.server != "" {
+		csrv = C.CString(p.server)
+		defer C.free(unsafe.Pointer(csrv))
+	}
+	cname := C.CString(clientName)
+	defer C.free(unsafe.Pointer(cname))
+
+	cp := C.pulse_alloc()
+	e := C.pulse_open(cp, csrv, cname)
+	if e < 0 {
+		C.pulse_free(cp)
+		return fmt.Errorf("failed to connect to PulseAudio server: %w",
+			pulseError(e))
+	}
+	C.pulse_set_volume(cp, C.int(p.volume))
+	p.p = cp
+	p.paused = false
+
+	return nil
+}
+
+func (p *Pulse) SetSampleRate(rate int) error {
+	if !specValid(rate, p.chans, p.sfmt) {
+		return errors.New("unsupported sample rate")
+	}
+	if rate != p.rate {
+		p.closeStream()
+		p.rate = rate
+	}
+
+	return nil
+}
+
+func (p *Pulse) SetChannels(chans int) error {
+	if !specValid(p.rate, chans, p.sfmt) {
+		return errors.New("unsupported channels number")
+	}
+	if chans != p.chans {
+		p.closeStream()
+		p.chans = chans
+	}
+
+	return nil
+}
+
+func (p *Pulse) SetSampleFormat(f format.SampleFormat) error {
+	if !specValid(p.rate, p.chans, f) {
+		return errors.New("unsupported sample format")
+	}
+	if f != p.sfmt {
+		p.closeStream()
+		p.sfmt = f
+	}
+
+	return nil
+}
+
+// SetTrack passes track metadata to the server as stream properties.
+func (p *Pulse) SetTrack(t *vfs.Track) error {
+	if p.p == nil {
+		return nil
+	}
+
+	var title, artist, album string
+	if t.Tag != nil {
+		title = t.Tag.Title
+		artist = t.Tag.Artist
+		album = t.Tag.Album
+	}
+	ctitle := C.CString(title)
+	defer C.free(unsafe.Pointer(ctitle))
+	cartist := C.CString(artist)
+	defer C.free(unsafe.Pointer(cartist))
+	calbum := C.CString(album)
+	defer C.free(unsafe.Pointer(calbum))
+	cfile := C.CString(t.Path.String())
+	defer C.free(unsafe.Pointer(cfile))
+
+	e := C.pulse_set_track(p.p, ctitle, cartist, calbum, cfile)
+	if e < 0 {
+		return pulseError(e)
+	}
+
+	return nil
+}
+
+func (p *Pulse) Write(buf []byte) (int, error) {
+	if p.p == nil {
+		return 0, errors.New("output is closed")
+	}
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	if !p.stream {
+		err := p.openStream()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n := C.pulse_write(p.p, unsafe.Pointer(&buf[0]), C.int(len(buf)))
+	if n < 0 {
+		return 0, pulseError(n)
+	}
+
+	return int(n), nil
+}
+
+// Delay returns time needed to play data written to the stream
+// but not played yet.
+func (p *Pulse) Delay() (time.Duration, error) {
+	if p.p == nil {
+		return 0, nil
+	}
+	d := C.pulse_delay(p.p)
+	if d < 0 {
+		return 0, pulseError(C.int(d))
+	}
+
+	return time.Duration(d) * time.Microsecond, nil
+}
+
+func (p *Pulse) Flush() error {
+	if p.p == nil {
+		return nil
+	}
+	e := C.pulse_flush(p.p)
+	if e < 0 {
+		return pulseError(e)
+	}
+
+	return nil
+}
+
+func (p *Pulse) Pause() error {
+	if p.p == nil {
+		return nil
+	}
+	p.paused = !p.paused
+	var cork C.int
+	if p.paused {
+		cork = 1
+	}
+	e := C.pulse_cork(p.p, cork)
+	if e < 0 {
+		return pulseError(e)
+	}
+
+	return nil
+}
+
+func (p *Pulse) Volume() (int, error) {
+	return p.volume, nil
+}
+
+func (p *Pulse) SetVolume(vol int) error {
+	if vol < 0 || vol > 100 {
+		return errors.New("invalid volume value")
+	}
+	p.volume = vol
+	if p.p == nil {
+		return nil
+	}
+	e := C.pulse_set_volume(p.p, C.int(vol))
+	if e < 0 {
+		return pulseError(e)
+	}
+
+	return nil
+}
+
+func (p *Pulse) Close() error {
+	if p.p == nil {
+		return nil
+	}
+	C.pulse_close(p.p)
+	C.pulse_free(p.p)
+	p.p = nil
+	p.stream = false
+
+	return nil
+}
+
+func (p *Pulse) openStream() error {
+	var csink *C.char
+	if p.sink != "" {
+		csink = C.CString(p.sink)
+		defer C.free(unsafe.Pointer(csink))
+	}
+
+	e := C.pulse_stream_open(p.p, csink, C.int(p.rate), C.int(p.chans),
+		C.int(p.sfmt))
+	if e < 0 {
+		return fmt.Errorf("failed to open PulseAudio stream: %w",
+			pulseError(e))
+	}
+	p.stream = true
+	if p.paused {
+		C.pulse_cork(p.p, 1)
+	}
+
+	return nil
+}
+
+func (p *Pulse) closeStream() {
+	if p.stream {
+		C.pulse_stream_close(p.p)
+		p.stream = false
+	}
+}
+
+func specValid(rate, chans int, f format.SampleFormat) bool {
+	return C.pulse_spec_valid(C.int(rate), C.int(chans), C.int(f)) != 0
+}
+
+func pulseError(e C.int) error {
+	return errors.New(C.GoString(C.pa_strerror(-e)))
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+#ifndef CHUB_PULSE_H
+#define CHUB_PULSE_H
+
+#include <pulse/pulseaudio.h>
+
+// Sample formats supported by Chub. Must be kept in sync
+// with format.SampleFormat constants.
+enum pulse_sample_format {
+    PULSE_SAMPLE_FMT_S16 = 0,
+    PULSE_SAMPLE_FMT_S24,
+    PULSE_SAMPLE_FMT_S32,
+    PULSE_SAMPLE_FMT_F32,
+};
+
+struct pulse {
+    pa_threaded_mainloop *mainloop;
+    pa_context *context;
+    // Playback stream. NULL if not opened yet.
+    pa_stream *stream;
+    // Stream volume 0..100.
+    int volume;
+    // Track properties passed to the sound server.
+    char *title;
+    char *artist;
+    char *album;
+    char *filename;
+};
+
+struct pulse *pulse_alloc();
+void pulse_free(struct pulse *p);
+int pulse_open(struct pulse *p, const char *server, const char *name);
+void pulse_close(struct pulse *p);
+int pulse_spec_valid(int rate, int channels, int fmt);
+int pulse_stream_open(struct pulse *p, const char *sink, int rate,
+        int channels, int fmt);
+void pulse_stream_close(struct pulse *p);
+int pulse_write(struct pulse *p, const void *buf, int len);
+long pulse_delay(struct pulse *p);
+int pulse_cork(struct pulse *p, int cork);
+int pulse_flush(struct pulse *p);
+int pulse_set_volume(struct pulse *p, int vol);
+int pulse_set_track(struct pulse *p, const char *title, const char *artist,
+        const char *album, const char *filename);
+
+#endif // CHUB_PULSE_H
@@ -0,0 +1,243 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+// Recorder output driver. It writes played PCM stream into WAV files.
+package recorder
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/vfs"
+)
+
+type Mode int
+
+const (
+	// Write every track into separate file.
+	ModeTrack Mode = iota
+	// Write all played data into single file. New file is started
+	// only when playback stops or stream parameters change.
+	ModeContinuous
+)
+
+type Recorder struct {
+	dir    string
+	mode   Mode
+	open   bool
+	rate   int
+	chans  int
+	sfmt   format.SampleFormat
+	volume int
+	// Currently written file. nil if new file has to be started
+	// on the next write.
+	file *wavFile
+	// Path of the currently recorded track.
+	track string
+	// Base name for the next file.
+	name string
+	// Session name, used as a prefix for all files written.
+	session string
+	// Number of files written in the current session.
+	seq int
+}
+
+// New returns recorder output driver which writes files into dir.
+func New(dir string, mode Mode) *Recorder {
+	return &Recorder{
+		dir:   dir,
+		mode:  mode,
+		rate:  44100,
+		chans: 2,
+		sfmt:  format.SampleFormatS16,
+	}
+}
+
+func (r *Recorder) Open() error {
+	err := os.MkdirAll(r.dir, 0755)
+	if err != nil {
+		return err
+	}
+	if r.session == "" {
+		r.session = time.Now().Format("20060102-150405")
+	}
+	r.open = true
+
+	return nil
+}
+
+func (r *Recorder) SetSampleRate(rate int) error {
+	if rate <= 0 {
+		return errors.New("invalid sample rate")
+	}
+	if rate != r.rate {
+		r.rate = rate
+		return r.finish()
+	}
+
+	return nil
+}
+
+func (r *Recorder) SetChannels(chans int) error {
+	if chans <= 0 {
+		return errors.New("invalid number of channels")
+	}
+	if chans != r.chans {
+		r.chans = chans
+		return r.finish()
+	}
+
+	return nil
+}
+
+func (r *Recorder) SetSampleFormat(f format.SampleFormat) error {
+	if f != r.sfmt {
+		r.sfmt = f
+		return r.finish()
+	}
+
+	return nil
+}
+
+func (r *Recorder) SetTrack(t *vfs.Track) error {
+	p := t.Path.String()
+	if p == r.track {
+		// Resumed after pause or seek.
+		return nil
+	}
+	r.track = p
+	r.name = trackName(t)
+	if r.mode == ModeTrack {
+		return r.finish()
+	}
+
+	return nil
+}
+
+func (r *Recorder) Write(buf []byte) (int, error) {
+	if !r.open {
+		return 0, errors.New("output is closed")
+	}
+
+	fsz := r.chans * r.sfmt.Size()
+	n := len(buf) / fsz * fsz
+	if n == 0 {
+		return 0, nil
+	}
+	if r.file != nil && !r.file.fits(n) {
+		err := r.finish()
+		if err != nil {
+			return 0, err
+		}
+	}
+	if r.file == nil {
+		r.seq++
+		name := fmt.Sprintf("%s-%04d", r.session, r.seq)
+		if r.mode == ModeTrack && r.name != "" {
+			name += "-" + r.name
+		}
+		f, err := createWav(filepath.Join(r.dir, name+".wav"),
+			r.rate, r.chans, r.sfmt)
+		if err != nil {
+			return 0, err
+		}
+		r.file = f
+	}
+
+	err := r.file.write(buf[:n])
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+func (r *Recorder) Flush() error {
+	return nil
+}
+
+func (r *Recorder) Pause() error {
+	return nil
+}
+
+// Close keeps currently written file open, because engine closes
+// output on every seek and track switch. So the recording goes on
+// in the same file. The file is completed by Stop.
+func (r *Recorder) Close() error {
+	r.open = false
+	if r.file == nil {
+		return nil
+	}
+
+	return r.file.flush()
+}
+
+// Stop completes currently written file.
+func (r *Recorder) Stop() error {
+	r.track = ""
+
+	return r.finish()
+}
+
+// Volume returns volume level. Volume level does not affect
+// recorded data.
+func (r *Recorder) Volume() (int, error) {
+	return r.volume, nil
+}
+
+func (r *Recorder) SetVolume(vol int) error {
+	r.volume = vol
+
+	return nil
+}
+
+// finish completes currently written file if any.
+func (r *Recorder) finish() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.close()
+	r.file = nil
+
+	return err
+}
+
+// trackName returns file name friendly track name.
+func trackName(t *vfs.Track) string {
+	var name string
+	if t.Tag != nil && t.Tag.Title != "" {
+		name = t.Tag.Title
+		if t.Tag.Artist != "" {
+			name = t.Tag.Artist + " - " + name
+		}
+	} else {
+		name = strings.TrimSuffix(t.Path.Base(),
+			filepath.Ext(t.Path.Base()))
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == 0 {
+			return '_'
+		}
+		return r
+	}, name)
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package recorder
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/output"
+	"github.com/vchimishuk/chub/vfs"
+	vconfig "github.com/vchimishuk/config"
+)
+
+func track(t *testing.T, p string) *vfs.Track {
+	path, err := vfs.NewPath(p)
+	assert.Nil(t, err)
+
+	return &vfs.Track{Path: path}
+}
+
+func files(t *testing.T, dir string) []string {
+	m, err := filepath.Glob(filepath.Join(dir, "*.wav"))
+	assert.Nil(t, err)
+
+	return m
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	r := New(dir, ModeTrack)
+	assert.Nil(t, r.Open())
+	assert.Nil(t, r.SetSampleRate(48000))
+	assert.Nil(t, r.SetTrack(track(t, "/music/song.flac")))
+
+	buf := make([]byte, 8)
+	binary.NativeEndian.PutUint16(buf, 0x1234)
+	n, err := r.Write(buf)
+	assert.Nil(t, err)
+	assert.True(t, n == len(buf))
+	assert.Nil(t, r.Close())
+	assert.Nil(t, r.Stop())
+
+	fs := files(t, dir)
+	assert.True(t, len(fs) == 1)
+	assert.True(t, strings.HasSuffix(fs[0], "-song.wav"))
+	data, err := os.ReadFile(fs[0])
+	assert.Nil(t, err)
+	assert.True(t, len(data) == wavHeaderSize+8)
+	assert.True(t, string(data[0:4]) == "RIFF")
+	assert.True(t, binary.LittleEndian.Uint32(data[4:]) == 36+8)
+	assert.True(t, binary.LittleEndian.Uint16(data[22:]) == 2)
+	assert.True(t, binary.LittleEndian.Uint32(data[24:]) == 48000)
+	assert.True(t, binary.LittleEndian.Uint16(data[34:]) == 16)
+	assert.True(t, binary.LittleEndian.Uint32(data[40:]) == 8)
+	assert.True(t, binary.LittleEndian.Uint16(data[44:]) == 0x1234)
+}
+
+func TestS24(t *testing.T) {
+	dir := t.TempDir()
+	r := New(dir, ModeContinuous)
+	assert.Nil(t, r.Open())
+	assert.Nil(t, r.SetSampleFormat(format.SampleFormatS24))
+	assert.Nil(t, r.SetChannels(1))
+
+	buf := make([]byte, 4)
+	binary.NativeEndian.PutUint32(buf, 0x123456)
+	_, err := r.Write(buf)
+	assert.Nil(t, err)
+	assert.Nil(t, r.Close())
+	assert.Nil(t, r.Stop())
+
+	data, err := os.ReadFile(files(t, dir)[0])
+	assert.Nil(t, err)
+	assert.True(t, binary.LittleEndian.Uint16(data[34:]) == 24)
+	assert.True(t, binary.LittleEndian.Uint32(data[40:]) == 3)
+	// Odd data chunk is padded.
+	assert.True(t, len(data) == wavHeaderSize+4)
+	assert.True(t, data[44] == 0x56 && data[45] == 0x34 && data[46] == 0x12)
+}
+
+func TestSplit(t *testing.T) {
+	dir := t.TempDir()
+	r := New(dir, ModeTrack)
+	assert.Nil(t, r.Open())
+	buf := make([]byte, 4)
+
+	assert.Nil(t, r.SetTrack(track(t, "/a.flac")))
+	_, err := r.Write(buf)
+	assert.Nil(t, err)
+	// The same track after pause or seek.
+	assert.Nil(t, r.SetTrack(track(t, "/a.flac")))
+	_, err = r.Write(buf)
+	assert.Nil(t, err)
+	assert.Nil(t, r.SetTrack(track(t, "/b.flac")))
+	_, err = r.Write(buf)
+	assert.Nil(t, err)
+	// Stream format change starts new file in any mode.
+	assert.Nil(t, r.SetChannels(1))
+	_, err = r.Write(buf)
+	assert.Nil(t, err)
+	assert.Nil(t, r.Close())
+	assert.Nil(t, r.Stop())
+
+	assert.True(t, len(files(t, dir)) == 3)
+}
+
+func TestContinuous(t *testing.T) {
+	dir := t.TempDir()
+	r := New(dir, ModeContinuous)
+	assert.Nil(t, r.Open())
+	buf := make([]byte, 4)
+
+	assert.Nil(t, r.SetTrack(track(t, "/a.flac")))
+	_, err := r.Write(buf)
+	assert.Nil(t, err)
+	assert.Nil(t, r.SetTrack(track(t, "/b.flac")))
+	_, err = r.Write(buf)
+	assert.Nil(t, err)
+	assert.Nil(t, r.Close())
+	assert.Nil(t, r.Stop())
+
+	assert.True(t, len(files(t, dir)) == 1)
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	buf := make([]byte, 4)
+
+	for _, mode := range []Mode{ModeTrack, ModeContinuous} {
+		r := New(dir, mode)
+		assert.Nil(t, r.Open())
+		assert.Nil(t, r.SetTrack(track(t, "/a.flac")))
+		_, err := r.Write(buf)
+		assert.Nil(t, err)
+		// Engine reopens output on seek.
+		assert.Nil(t, r.Close())
+		assert.Nil(t, r.Open())
+		assert.Nil(t, r.SetTrack(track(t, "/a.flac")))
+		_, err = r.Write(buf)
+		assert.Nil(t, err)
+		assert.Nil(t, r.Close())
+		assert.Nil(t, r.Stop())
+	}
+
+	fs := files(t, dir)
+	assert.True(t, len(fs) == 2)
+	for _, f := range fs {
+		data, err := os.ReadFile(f)
+		assert.Nil(t, err)
+		assert.True(t, binary.LittleEndian.Uint32(data[40:]) == 8)
+	}
+}
+
+func TestClosed(t *testing.T) {
+	r := New(t.TempDir(), ModeTrack)
+	_, err := r.Write(make([]byte, 4))
+	assert.Error(t, err, "output is closed")
+}
+
+func TestConfig(t *testing.T) {
+	spec := &vconfig.Spec{Properties: output.Properties()}
+	c, err := vconfig.Parse(spec, `recorder-dir = "/tmp/rec"
+recorder-mode = "continuous"`)
+	assert.Nil(t, err)
+	o, err := output.New("recorder", c)
+	assert.Nil(t, err)
+	r := o.(*Recorder)
+	assert.True(t, r.dir == "/tmp/rec" && r.mode == ModeContinuous)
+
+	_, err = vconfig.Parse(spec, `recorder-mode = "album"`)
+	assert.Error(t, err, "1: unsupported value")
+}
@@ -0,0 +1,45 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+package recorder
+
+import (
+	"github.com/vchimishuk/chub/config"
+	"github.com/vchimishuk/chub/output"
+	"github.com/vchimishuk/chub/player"
+	vconfig "github.com/vchimishuk/config"
+)
+
+func init() {
+	output.Register("recorder", []*vconfig.PropertySpec{
+		&vconfig.PropertySpec{
+			Type: vconfig.TypeString,
+			Name: "recorder-dir",
+		},
+		&vconfig.PropertySpec{
+			Type: vconfig.TypeString,
+			Name: "recorder-mode",
+			Parser: config.ParseEnum(
+				[]string{"continuous", "track"}),
+		},
+	}, func(c *vconfig.Config) (player.Output, error) {
+		mode := ModeTrack
+		if c.StringOr("recorder-mode", "track") == "continuous" {
+			mode = ModeContinuous
+		}
+		return New(c.StringOr("recorder-dir", "."), mode), nil
+	})
+}
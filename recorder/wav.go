@@ -0,0 +1,181 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package recorder
+
+import (
+	"bufio"
+	"encoding/binary"
+	"math"
+	"os"
+
+	"github.com/vchimishuk/chub/format"
+)
+
+const (
+	wavHeaderSize = 44
+	// WAV format tags.
+	wavFormatPCM   = 1
+	wavFormatFloat = 3
+)
+
+// wavFile is a WAV file being written. Header contains zero sizes
+// until the file is closed.
+type wavFile struct {
+	file  *os.File
+	w     *bufio.Writer
+	sfmt  format.SampleFormat
+	chans int
+	// Number of data bytes written.
+	size int64
+	// Buffer for samples converted to the WAV representation.
+	buf []byte
+}
+
+func createWav(path string, rate int, chans int,
+	sfmt format.SampleFormat) (*wavFile, error) {
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	wf := &wavFile{
+		file:  f,
+		w:     bufio.NewWriter(f),
+		sfmt:  sfmt,
+		chans: chans,
+	}
+	_, err = wf.w.Write(wavHeader(rate, chans, sfmt, 0))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return wf, nil
+}
+
+// fits returns true if n more bytes of PCM data can be stored
+// in the file without 4GB RIFF size overflow.
+func (f *wavFile) fits(n int) bool {
+	s := f.size + int64(n/f.sfmt.Size()*wavSampleSize(f.sfmt))
+
+	return s+wavHeaderSize-8 <= math.MaxUint32
+}
+
+// write converts native PCM data into little-endian WAV samples
+// and writes it to the file.
+func (f *wavFile) write(data []byte) error {
+	ssz := f.sfmt.Size()
+	wsz := wavSampleSize(f.sfmt)
+	n := len(data) / ssz
+	if cap(f.buf) < n*wsz {
+		f.buf = make([]byte, n*wsz)
+	}
+	buf := f.buf[:n*wsz]
+
+	for i := 0; i < n; i++ {
+		s := data[i*ssz : (i+1)*ssz]
+		d := buf[i*wsz : (i+1)*wsz]
+		switch f.sfmt {
+		case format.SampleFormatS16:
+			binary.LittleEndian.PutUint16(d,
+				binary.NativeEndian.Uint16(s))
+		case format.SampleFormatS24:
+			// Pack low three bytes of 32-bit word.
+			v := binary.NativeEndian.Uint32(s)
+			d[0], d[1], d[2] = byte(v), byte(v>>8), byte(v>>16)
+		default:
+			binary.LittleEndian.PutUint32(d,
+				binary.NativeEndian.Uint32(s))
+		}
+	}
+
+	_, err := f.w.Write(buf)
+	if err != nil {
+		return err
+	}
+	f.size += int64(len(buf))
+
+	return nil
+}
+
+// flush writes buffered data to the file.
+func (f *wavFile) flush() error {
+	return f.w.Flush()
+}
+
+// close updates header with actual sizes and closes the file.
+func (f *wavFile) close() error {
+	err := f.w.Flush()
+	if err == nil && f.size%2 == 1 {
+		// RIFF chunks are word aligned.
+		_, err = f.file.Write([]byte{0})
+	}
+	if err == nil {
+		b := make([]byte, 4)
+		binary.LittleEndian.PutUint32(b,
+			uint32(f.size+f.size%2+wavHeaderSize-8))
+		_, err = f.file.WriteAt(b, 4)
+		if err == nil {
+			binary.LittleEndian.PutUint32(b, uint32(f.size))
+			_, err = f.file.WriteAt(b, wavHeaderSize-4)
+		}
+	}
+	cerr := f.file.Close()
+	if err != nil {
+		return err
+	}
+
+	return cerr
+}
+
+// wavSampleSize returns size of a single sample stored in WAV file.
+func wavSampleSize(f format.SampleFormat) int {
+	if f == format.SampleFormatS24 {
+		return 3
+	}
+
+	return f.Size()
+}
+
+// wavHeader returns canonical 44-byte WAV header.
+func wavHeader(rate int, chans int, sfmt format.SampleFormat,
+	size uint32) []byte {
+
+	tag := wavFormatPCM
+	if sfmt == format.SampleFormatF32 {
+		tag = wavFormatFloat
+	}
+	ssz := wavSampleSize(sfmt)
+
+	h := make([]byte, 0, wavHeaderSize)
+	h = append(h, "RIFF"...)
+	h = binary.LittleEndian.AppendUint32(h, size+wavHeaderSize-8)
+	h = append(h, "WAVE"...)
+	h = append(h, "fmt "...)
+	h = binary.LittleEndian.AppendUint32(h, 16)
+	h = binary.LittleEndian.AppendUint16(h, uint16(tag))
+	h = binary.LittleEndian.AppendUint16(h, uint16(chans))
+	h = binary.LittleEndian.AppendUint32(h, uint32(rate))
+	h = binary.LittleEndian.AppendUint32(h, uint32(rate*chans*ssz))
+	h = binary.LittleEndian.AppendUint16(h, uint16(chans*ssz))
+	h = binary.LittleEndian.AppendUint16(h, uint16(ssz*8))
+	h = append(h, "data"...)
+	h = binary.LittleEndian.AppendUint32(h, size)
+
+	return h
+}
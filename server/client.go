@@ -18,6 +18,8 @@
 package server
 
 import (
+	"encoding/base64"
+	"encoding/binary"
 	"errors"
 	"net"
 	"os"
@@ -71,6 +73,18 @@ func (c *client) Serve() {
 
 		if err == nil {
 			switch cmd.Name {
+			case proto.Balance:
+				err = c.player.SetBalance(cmd.Args[0].(int))
+			case proto.Cover:
+				recs, err = c.cover(cmd.Args[0].(string),
+					cmd.Args[1].(int))
+			case proto.Eq:
+				err = c.player.SetEqEnabled(cmd.Args[0].(bool))
+			case proto.EqPreset:
+				err = c.player.SetEqPreset(cmd.Args[0].(string))
+			case proto.EqSet:
+				err = c.player.SetEqGain(cmd.Args[0].(int),
+					cmd.Args[1].(int))
 			case proto.Events:
 				c.events.Store(cmd.Args[0].(bool))
 			case proto.Kill:
@@ -79,8 +93,20 @@ func (c *client) Serve() {
 				kill = true
 			case proto.List:
 				recs, err = c.list(cmd.Args[0].(string))
+			case proto.Lyrics:
+				recs, err = c.lyrics(cmd.Args[0].(string))
+			case proto.Mono:
+				err = c.player.SetMono(cmd.Args[0].(bool))
 			case proto.Next:
 				err = c.player.Next()
+			case proto.OutputDisable:
+				err = c.player.DisableOutput(cmd.Args[0].(string))
+			case proto.OutputEnable:
+				err = c.player.EnableOutput(cmd.Args[0].(string))
+			case proto.OutputFormat:
+				recs = c.outputFormat()
+			case proto.Outputs:
+				recs = c.outputs()
 			case proto.Pause:
 				err = c.player.Pause()
 			case proto.Ping:
@@ -116,6 +142,8 @@ func (c *client) Serve() {
 					cmd.Args[1].(bool))
 			case proto.Status:
 				recs = c.status()
+			case proto.SwapChannels:
+				err = c.player.SetSwapChannels(cmd.Args[0].(bool))
 			case proto.Stop:
 				err = c.player.Stop()
 			case proto.Volume:
@@ -216,6 +244,70 @@ func (c *client) list(path string) ([]serialize.Serializable, error) {
 	return serializableSlice(es), err
 }
 
+// Maximum number of cover bytes sent in one line.
+const coverChunkSize = 3 << 10
+
+// cover returns cover image encoded in base64 and split into chunks
+// of lines. The first record describes the whole image.
+func (c *client) cover(path string, size int) ([]serialize.Serializable, error) {
+	p, err := vfs.NewPath(path)
+	if err != nil {
+		return nil, err
+	}
+	cv, err := p.Cover(size)
+	if err != nil {
+		return nil, err
+	}
+	if cv == nil {
+		return nil, errors.New("cover not found")
+	}
+
+	recs := []serialize.Serializable{serialize.Wrap(map[string]any{
+		"mime":   cv.MIME,
+		"size":   len(cv.Data),
+		"chunks": (len(cv.Data) + coverChunkSize - 1) / coverChunkSize,
+	})}
+	for d := cv.Data; len(d) > 0; {
+		n := min(len(d), coverChunkSize)
+		recs = append(recs, serialize.Wrap(map[string]any{
+			"data": base64.StdEncoding.EncodeToString(d[:n]),
+		}))
+		d = d[n:]
+	}
+
+	return recs, nil
+}
+
+// lyrics returns lyrics of the track one line per record.
+// The first record tells if lyrics are synchronized, in which case
+// every line is accompanied with its time.
+func (c *client) lyrics(path string) ([]serialize.Serializable, error) {
+	p, err := vfs.NewPath(path)
+	if err != nil {
+		return nil, err
+	}
+	l, err := p.Lyrics()
+	if err != nil {
+		return nil, err
+	}
+	if l == nil {
+		return nil, errors.New("lyrics not found")
+	}
+
+	recs := []serialize.Serializable{serialize.Wrap(map[string]any{
+		"synced": l.Synced,
+	})}
+	for _, ln := range l.Lines {
+		m := map[string]any{"text": ln.Text}
+		if l.Synced {
+			m["time"] = ln.Time
+		}
+		recs = append(recs, serialize.Wrap(m))
+	}
+
+	return recs, nil
+}
+
 func (c *client) playlist(name string) ([]serialize.Serializable, error) {
 	plist, err := c.player.Playlist(name)
 	if err != nil {
@@ -246,16 +338,57 @@ func (c *client) status() []serialize.Serializable {
 		stm["playlist-length"] = st.Plist.Len()
 		stm["track-path"] = track.Path.String()
 		stm["track-artist"] = track.Tag.Artist
+		stm["track-album-artist"] = track.Tag.AlbumArtist
 		stm["track-album"] = track.Tag.Album
 		stm["track-title"] = track.Tag.Title
 		stm["track-number"] = track.Tag.Number
+		stm["track-tracks"] = track.Tag.Tracks
+		stm["track-disc"] = track.Tag.Disc
+		stm["track-discs"] = track.Tag.Discs
 		stm["track-year"] = track.Tag.Year
+		stm["track-date"] = track.Tag.Date
+		stm["track-genre"] = track.Tag.Genre
+		stm["track-composer"] = track.Tag.Composer
+		stm["track-comment"] = track.Tag.Comment
 		stm["track-length"] = track.Length
+		stm["track-codec"] = track.Codec
+		stm["track-bitrate"] = track.Bitrate
+		stm["track-bit-depth"] = track.BitDepth
+		stm["track-sample-rate"] = track.SampleRate
+		stm["track-channels"] = track.Channels
 	}
 
 	return []serialize.Serializable{serialize.Wrap(stm)}
 }
 
+func (c *client) outputs() []serialize.Serializable {
+	return serializableSlice(c.player.Outputs())
+}
+
+// outputFormat returns description of the raw PCM stream passed
+// to the output, so external programs (e.g. reading pipe output)
+// can interpret it. Nothing is returned when playback is stopped.
+func (c *client) outputFormat() []serialize.Serializable {
+	st := c.player.Status()
+	if st.State == player.StateStopped {
+		return nil
+	}
+
+	order := "little-endian"
+	if binary.NativeEndian.Uint16([]byte{0, 1}) == 1 {
+		order = "big-endian"
+	}
+	m := map[string]any{}
+	m["sample-rate"] = st.SampleRate
+	m["channels"] = st.Channels
+	m["sample-format"] = st.SampleFormat.String()
+	m["sample-bits"] = st.SampleFormat.Bits()
+	m["sample-size"] = st.SampleFormat.Size()
+	m["byte-order"] = order
+
+	return []serialize.Serializable{serialize.Wrap(m)}
+}
+
 func serializableSlice[E serialize.Serializable](s []E) []serialize.Serializable {
 	t := make([]serialize.Serializable, 0, len(s))
 	for _, e := range s {
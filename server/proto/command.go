@@ -18,18 +18,40 @@
 package proto
 
 const (
+	// Set stereo balance.
+	Balance = "balance"
+	// Get album cover of the track or directory.
+	Cover = "cover"
 	// Create new playlist.
 	CreatePlaylist = "create-playlist"
 	// Delete existing playlist.
 	DeletePlaylist = "delete-playlist"
+	// Turn equalizer on or off.
+	Eq = "eq"
+	// Apply equalizer preset.
+	EqPreset = "eq-preset"
+	// Set equalizer band gain.
+	EqSet = "eq-set"
 	// Enable or disable events notification for the current connection.
 	Events = "events"
 	// Stop the server.
 	Kill = "kill"
 	// Show directory contents.
 	List = "list"
+	// Get lyrics of the track.
+	Lyrics = "lyrics"
+	// Turn mono output on or off.
+	Mono = "mono"
 	// Play next track in the current playing playlist.
 	Next = "next"
+	// Disable output.
+	OutputDisable = "output-disable"
+	// Enable output.
+	OutputEnable = "output-enable"
+	// Get format of the stream passed to the output.
+	OutputFormat = "output-format"
+	// List outputs.
+	Outputs = "outputs"
 	// Toggle paused state.
 	Pause = "pause"
 	// Do nothing, just returns "OK" response.
@@ -60,10 +82,13 @@ const (
 	Repeat = "repeat"
 	// Returns player's current state (playback status, volume, etc.).
 	Status = "status"
-	// Seek current playing track time to specified time offset.
+	// Seek current playing track time to specified time offset
+	// in seconds.
 	Seek = "seek"
 	// Stop playing if active.
 	Stop = "stop"
+	// Turn left and right channels swapping on or off.
+	SwapChannels = "swap-channels"
 	// Change volume level.
 	Volume = "volume"
 )
@@ -98,7 +123,8 @@ func ParseCommand(str string) (*Command, error) {
 				e = newError("negative time")
 			}
 		}
-		args = []interface{}{t, r}
+		// Player positions are in milliseconds.
+		args = []interface{}{t * 1000, r}
 		err = e
 	case Volume:
 		var vol int
@@ -121,15 +147,61 @@ func ParseCommand(str string) (*Command, error) {
 			}
 		}
 		args = []any{vol, mode}
+	case Balance:
+		var b int
+		b, err = s.NextInt()
+		if err != nil {
+			break
+		}
+		if b < -100 || b > 100 {
+			err = newError("balance out of range")
+			break
+		}
+		args = []any{b}
+	// One on/off argument commands.
+	case Eq, Mono, SwapChannels:
+		var m string
+		m, err = s.NextString()
+		if err != nil {
+			break
+		}
+		if m != "on" && m != "off" {
+			err = newError("invalid mode")
+			break
+		}
+		args = []any{m == "on"}
+	// Path and optional thumbnail size.
+	case Cover:
+		var p string
+		var size int
+		p, err = s.NextString()
+		if err != nil {
+			break
+		}
+		if s.HasNext() {
+			size, err = s.NextInt()
+			if err == nil && size < 0 {
+				err = newError("negative size")
+			}
+		}
+		args = []any{p, size}
+	case EqSet:
+		var band, gain int
+		band, err = s.NextInt()
+		if err != nil {
+			break
+		}
+		gain, err = s.NextInt()
+		args = []any{band, gain}
 	// One bool argument command
 	case Events:
 		b, e := s.NextBool()
 		args = []interface{}{b}
 		err = e
 	// One string argument commands.
-	case CreatePlaylist, DeletePlaylist, List, Play, PlaylistClear:
+	case CreatePlaylist, DeletePlaylist, EqPreset, List, Lyrics, Play, PlaylistClear:
 		fallthrough
-	case PlaylistDelete, PlaylistList:
+	case OutputDisable, OutputEnable, PlaylistDelete, PlaylistList:
 		p, e := s.NextString()
 		args = []interface{}{p}
 		err = e
@@ -143,7 +215,7 @@ func ParseCommand(str string) (*Command, error) {
 		args = []interface{}{a, b}
 		err = e
 	// Argumentless commands.
-	case Kill, Next, Pause, Ping, Playlists:
+	case Kill, Next, OutputFormat, Outputs, Pause, Ping, Playlists:
 		fallthrough
 	case Prev, Quit, Status, Stop:
 	default:
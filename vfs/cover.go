@@ -0,0 +1,287 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package vfs
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/vfs/db"
+)
+
+// Image files looked for in the directory, in order of preference.
+var coverNames = []string{"cover", "folder", "front", "album"}
+var coverExts = []string{"jpg", "jpeg", "png", "gif", "webp"}
+
+// Thumbnail cache record header.
+const (
+	coverMagic   = "chcv"
+	coverVersion = 2
+	// Keys of thumbnails in the DB. Metadata records are keyed
+	// by absolute file paths, so they never clash.
+	coverKeyPrefix = "cover:"
+)
+
+// Cover is an album cover image.
+type Cover struct {
+	// MIME type of the image, e. g. "image/jpeg".
+	MIME string
+	Data []byte
+}
+
+// Cover returns album cover for the track or directory. For a track
+// picture embedded into its file is preferred, then image file from
+// the track's directory (cover.jpg, folder.png, etc.) is used.
+// For a directory its image file is used or picture embedded into
+// the first track if there is no one. If size is positive the image is
+// scaled down to fit into size x size square and cached. Images of
+// formats which can not be decoded are returned as is.
+// Nil is returned if no cover is found.
+func (p *Path) Cover(size int) (*Cover, error) {
+	isDir, err := p.IsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := p
+	if !isDir {
+		c, err := embeddedCover(p.File(), size)
+		if err != nil || c != nil {
+			return c, err
+		}
+		dir, err = p.Parent()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := findCoverFile(dir.File())
+	if err != nil {
+		return nil, err
+	}
+	if file != "" {
+		return cachedCover(file, size, func() ([]byte, error) {
+			return os.ReadFile(file)
+		})
+	}
+
+	if isDir {
+		es, err := p.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range es {
+			if !e.IsDir() {
+				return embeddedCover(e.Track().Path.File(), size)
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func embeddedCover(file string, size int) (*Cover, error) {
+	return cachedCover(file, size, func() ([]byte, error) {
+		pic, err := format.GetPicture(file)
+		if errors.Is(err, format.ErrNotSupported) {
+			return nil, nil
+		}
+
+		return pic, err
+	})
+}
+
+// findCoverFile returns path to the cover image file in the directory
+// or empty string if there is no one.
+func findCoverFile(dir string) (string, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		return "", err
+	}
+	images := make(map[string]string)
+	for _, n := range names {
+		images[strings.ToLower(n)] = n
+	}
+	for _, n := range coverNames {
+		for _, e := range coverExts {
+			if name, ok := images[n+"."+e]; ok {
+				return filepath.Join(dir, name), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// cachedCover returns image loaded from the file with load function.
+// Thumbnails are kept in the DB along with file's modification
+// time, so they are refreshed when the file changes.
+func cachedCover(file string, size int,
+	load func() ([]byte, error)) (*Cover, error) {
+
+	if size <= 0 {
+		data, err := load()
+		if err != nil || data == nil {
+			return nil, err
+		}
+		return newCover(data), nil
+	}
+
+	fi, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	modtime := fi.ModTime().UnixNano()
+	key := coverKeyPrefix + strconv.Itoa(size) + ":" + file
+
+	b, err := db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if mod, data, ok := deserializeCover(b); ok && mod == modtime {
+		if len(data) == 0 {
+			return nil, nil
+		}
+		return newCover(data), nil
+	}
+
+	data, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		data = thumbnail(data, size)
+	}
+	// Missing covers are cached too to not look for them every time.
+	err = db.Put(key, serializeCover(modtime, data))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	return newCover(data), nil
+}
+
+func newCover(data []byte) *Cover {
+	return &Cover{MIME: http.DetectContentType(data), Data: data}
+}
+
+func serializeCover(modtime int64, data []byte) []byte {
+	var b []byte
+
+	b = append(b, []byte(coverMagic)...)
+	b = append(b, coverVersion)
+	b = append(b, int64ToBytes(modtime)...)
+	b = append(b, data...)
+
+	return b
+}
+
+func deserializeCover(b []byte) (int64, []byte, bool) {
+	n := len(coverMagic)
+	if len(b) < n+1+8 || string(b[:n]) != coverMagic ||
+		b[n] != coverVersion {
+		return 0, nil, false
+	}
+
+	return bytesToInt64(b[n+1 : n+9]), b[n+9:], true
+}
+
+// thumbnail scales the image down to fit into size x size square
+// and returns it in JPEG format. Small images and images which
+// can not be decoded are returned untouched.
+func thumbnail(data []byte, size int) []byte {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return data
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return data
+	}
+	if w > h {
+		w, h = size, max(1, h*size/w)
+	} else {
+		w, h = max(1, w*size/h), size
+	}
+
+	var buf bytes.Buffer
+	err = jpeg.Encode(&buf, scale(img, w, h), &jpeg.Options{Quality: 85})
+	if err != nil {
+		return data
+	}
+
+	return buf.Bytes()
+}
+
+// scale resizes the image averaging source pixels covered
+// by every destination one.
+func scale(src image.Image, w, h int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*sh/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*sh/h)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*sw/w
+			x1 := max(x0+1, b.Min.X+(x+1)*sw/w)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					bl += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
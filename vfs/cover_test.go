@@ -0,0 +1,80 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package vfs
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+)
+
+func testImage(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	assert.Nil(t, png.Encode(&buf, img))
+
+	return buf.Bytes()
+}
+
+func TestThumbnail(t *testing.T) {
+	small := testImage(t, 10, 10)
+	assert.True(t, bytes.Equal(thumbnail(small, 16), small))
+
+	th := thumbnail(testImage(t, 40, 20), 16)
+	img, err := jpeg.Decode(bytes.NewReader(th))
+	assert.Nil(t, err)
+	assert.True(t, img.Bounds().Dx() == 16)
+	assert.True(t, img.Bounds().Dy() == 8)
+	r, g, _, _ := img.At(8, 4).RGBA()
+	assert.True(t, r > 0xf000 && g < 0x1000)
+
+	junk := []byte("not an image")
+	assert.True(t, bytes.Equal(thumbnail(junk, 16), junk))
+}
+
+func TestCoverFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, SetRoot(dir))
+	data := testImage(t, 10, 10)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "Folder.PNG"), data, 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "back.jpg"), nil, 0644))
+
+	p, err := NewPath("/")
+	assert.Nil(t, err)
+	c, err := p.Cover(0)
+	assert.Nil(t, err)
+	assert.True(t, c.MIME == "image/png")
+	assert.True(t, bytes.Equal(c.Data, data))
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "cover.jpg"), data, 0644))
+	f, err := findCoverFile(dir)
+	assert.Nil(t, err)
+	assert.True(t, f == filepath.Join(dir, "cover.jpg"))
+}
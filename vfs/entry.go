@@ -59,14 +59,30 @@ func (d *Dir) Serialize() string {
 type Tag struct {
 	// Artist name.
 	Artist string
+	// Album artist name.
+	AlbumArtist string
 	// Album name.
 	Album string
 	// Album release year.
 	Year int
+	// Full release date string as found in the tag, e. g. 1999-12-31.
+	Date string
 	// Track's title.
 	Title string
 	// Track number.
 	Number int
+	// Total number of tracks.
+	Tracks int
+	// Disc number.
+	Disc int
+	// Total number of discs.
+	Discs int
+	// Genre name.
+	Genre string
+	// Composer name.
+	Composer string
+	// Free form comment.
+	Comment string
 }
 
 // Track is a filesystem entry structure representing track.
@@ -88,6 +104,17 @@ type Track struct {
 	Part bool
 	// Track number as in CUE file.
 	Number int
+	// Technical properties of the audio stream of the physical file.
+	// Codec name as reported by the format, e. g. "flac".
+	Codec string
+	// Average bitrate in bits per second.
+	Bitrate int
+	// Bits per sample, 0 for lossy codecs.
+	BitDepth int
+	// Sample rate in Hz.
+	SampleRate int
+	// Number of channels.
+	Channels int
 	// Track beginning in the physical file.
 	Start int
 	// Track end position in the physical file.
@@ -112,12 +139,27 @@ func (t *Track) Serialize() string {
 		"path":   t.Path.String(),
 		"length": t.Length,
 	}
+	if t.Codec != "" {
+		m["codec"] = t.Codec
+		m["bitrate"] = t.Bitrate
+		m["bit-depth"] = t.BitDepth
+		m["sample-rate"] = t.SampleRate
+		m["channels"] = t.Channels
+	}
 	if t.Tag != nil {
 		m["artist"] = t.Tag.Artist
+		m["album-artist"] = t.Tag.AlbumArtist
 		m["album"] = t.Tag.Album
 		m["title"] = t.Tag.Title
 		m["number"] = t.Tag.Number
+		m["tracks"] = t.Tag.Tracks
+		m["disc"] = t.Tag.Disc
+		m["discs"] = t.Tag.Discs
 		m["year"] = t.Tag.Year
+		m["date"] = t.Tag.Date
+		m["genre"] = t.Tag.Genre
+		m["composer"] = t.Tag.Composer
+		m["comment"] = t.Tag.Comment
 	}
 
 	return serialize.Map(m)
@@ -0,0 +1,83 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package vfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vchimishuk/chub/format"
+	"github.com/vchimishuk/chub/lyrics"
+)
+
+// Lyrics returns lyrics of the track. LRC file with the same name
+// as the audio file is preferred over lyrics embedded into it.
+// For tracks which are parts of an album image only synchronized
+// lyrics are supported. Nil is returned if there are no lyrics.
+func (p *Path) Lyrics() (*lyrics.Lyrics, error) {
+	d, err := p.IsDir()
+	if err != nil {
+		return nil, err
+	}
+	if d {
+		return nil, fmt.Errorf("'%s' is not track", p)
+	}
+
+	text, err := readLrc(p.File())
+	if err != nil {
+		return nil, err
+	}
+	if text == "" {
+		text, err = format.GetLyrics(p.File())
+		if err != nil {
+			return nil, err
+		}
+	}
+	l := lyrics.Parse(text)
+	if l == nil || !p.part {
+		return l, nil
+	}
+	if !l.Synced {
+		return nil, nil
+	}
+	t, err := p.Track()
+	if err != nil {
+		return nil, err
+	}
+
+	return l.Slice(t.Start, t.End), nil
+}
+
+// readLrc returns contents of the LRC file which accompanies
+// the audio file or empty string if there is no one.
+func readLrc(file string) (string, error) {
+	base := strings.TrimSuffix(file, filepath.Ext(file))
+	for _, ext := range []string{".lrc", ".LRC"} {
+		b, err := os.ReadFile(base + ext)
+		if err == nil {
+			return string(b), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package vfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vchimishuk/chub/assert"
+)
+
+func TestLyricsFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, SetRoot(dir))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "song.wav"), nil, 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "song.LRC"),
+		[]byte("[00:01.00]One\n[00:02.50]Two\n"), 0644))
+
+	p, err := NewPath("/song.wav")
+	assert.Nil(t, err)
+	l, err := p.Lyrics()
+	assert.Nil(t, err)
+	assert.True(t, l.Synced)
+	assert.True(t, len(l.Lines) == 2)
+	assert.True(t, l.Lines[1].Time == 2500 && l.Lines[1].Text == "Two")
+
+	d, err := NewPath("/")
+	assert.Nil(t, err)
+	_, err = d.Lyrics()
+	assert.Error(t, err, "'/' is not track")
+}
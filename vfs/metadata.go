@@ -7,15 +7,38 @@ import (
 	"github.com/vchimishuk/chub/vfs/db"
 )
 
+// Metadata record header. Records written by older versions have no
+// header and start with modification time directly, so they never
+// match it and are re-read from the file.
+const (
+	metadataMagic   = "chmd"
+	metadataVersion = 5
+)
+
 type metadata struct {
-	modified time.Time
-	Artist   string
-	Album    string
-	Year     int
-	Title    string
-	Number   int
+	modified    time.Time
+	Artist      string
+	AlbumArtist string
+	Album       string
+	Year        int
+	Date        string
+	Title       string
+	Number      int
+	Tracks      int
+	Disc        int
+	Discs       int
+	Genre       string
+	Composer    string
+	Comment     string
 	// TODO: Rename to Duration
-	Length int
+	Length     int
+	Codec      string
+	Bitrate    int
+	BitDepth   int
+	SampleRate int
+	Channels   int
+	// CUE sheet embedded into the file.
+	CueSheet string
 }
 
 func getMetadata(path *Path) (*metadata, error) {
@@ -33,8 +56,8 @@ func getMetadata(path *Path) (*metadata, error) {
 	modtime := fi.ModTime()
 
 	if len(b) != 0 {
-		md := deserializeMetadata(b)
-		if md.modified.Equal(modtime) {
+		md, ok := deserializeMetadata(b)
+		if ok && md.modified.Equal(modtime) {
 			return md, nil
 		}
 	}
@@ -45,13 +68,27 @@ func getMetadata(path *Path) (*metadata, error) {
 	}
 
 	md := &metadata{
-		modified: modtime,
-		Artist:   fmd.Artist(),
-		Album:    fmd.Album(),
-		Year:     fmd.Year(),
-		Title:    fmd.Title(),
-		Number:   fmd.Number(),
-		Length:   fmd.Length(),
+		modified:    modtime,
+		Artist:      fmd.Artist(),
+		AlbumArtist: fmd.AlbumArtist(),
+		Album:       fmd.Album(),
+		Year:        fmd.Year(),
+		Date:        fmd.Date(),
+		Title:       fmd.Title(),
+		Number:      fmd.Number(),
+		Tracks:      fmd.Tracks(),
+		Disc:        fmd.Disc(),
+		Discs:       fmd.Discs(),
+		Genre:       fmd.Genre(),
+		Composer:    fmd.Composer(),
+		Comment:     fmd.Comment(),
+		Length:      fmd.Length(),
+		Codec:       fmd.Codec(),
+		Bitrate:     fmd.Bitrate(),
+		BitDepth:    fmd.BitDepth(),
+		SampleRate:  fmd.SampleRate(),
+		Channels:    fmd.Channels(),
+		CueSheet:    fmd.CueSheet(),
 	}
 
 	err = db.Put(path.File(), serializeMetadata(md))
@@ -62,64 +99,123 @@ func getMetadata(path *Path) (*metadata, error) {
 	return md, nil
 }
 
-func deserializeMetadata(buf []byte) *metadata {
-	o := 0
-
-	mod := bytesToInt64(buf[o : o+8])
-	o += 8
-
-	nar := int(buf[o])
-	o++
-	ar := string(buf[o : o+nar])
-	o += nar
-
-	nal := int(buf[o])
-	o++
-	al := string(buf[o : o+nal])
-	o += nal
-
-	yr := bytesToInt32(buf[o : o+4])
-	o += 4
-
-	ntl := int(buf[o])
-	o++
-	tl := string(buf[o : o+ntl])
-	o += ntl
-
-	nm := bytesToInt32(buf[o : o+4])
-	o += 4
+// deserializeMetadata decodes metadata record. Returns false if the
+// record is written in unknown format or is corrupted.
+func deserializeMetadata(buf []byte) (*metadata, bool) {
+	n := len(metadataMagic)
+	if len(buf) < n+1 || string(buf[:n]) != metadataMagic ||
+		buf[n] != metadataVersion {
+		return nil, false
+	}
+	r := &metadataReader{buf: buf[n+1:]}
 
-	ln := bytesToInt32(buf[o : o+4])
-	o += 4
+	md := &metadata{}
+	md.modified = time.Unix(0, r.int64())
+	md.Artist = r.string()
+	md.AlbumArtist = r.string()
+	md.Album = r.string()
+	md.Year = r.int()
+	md.Date = r.string()
+	md.Title = r.string()
+	md.Number = r.int()
+	md.Tracks = r.int()
+	md.Disc = r.int()
+	md.Discs = r.int()
+	md.Genre = r.string()
+	md.Composer = r.string()
+	md.Comment = r.string()
+	md.Length = r.int()
+	md.Codec = r.string()
+	md.Bitrate = r.int()
+	md.BitDepth = r.int()
+	md.SampleRate = r.int()
+	md.Channels = r.int()
+	md.CueSheet = r.string()
 
-	return &metadata{
-		modified: time.Unix(mod, 0),
-		Artist:   ar,
-		Album:    al,
-		Year:     int(yr),
-		Title:    tl,
-		Number:   int(nm),
-		Length:   int(ln),
+	if r.err || len(r.buf) != 0 {
+		return nil, false
 	}
+
+	return md, true
 }
 
 func serializeMetadata(md *metadata) []byte {
 	var b []byte
 
-	b = append(b, int64ToBytes(md.modified.Unix())...)
-	b = append(b, byte(len(md.Artist)))
-	b = append(b, []byte(md.Artist)...)
-	b = append(b, byte(len(md.Album)))
-	b = append(b, []byte(md.Album)...)
+	b = append(b, []byte(metadataMagic)...)
+	b = append(b, metadataVersion)
+	b = append(b, int64ToBytes(md.modified.UnixNano())...)
+	b = appendString(b, md.Artist)
+	b = appendString(b, md.AlbumArtist)
+	b = appendString(b, md.Album)
 	b = append(b, int32ToBytes(int32(md.Year))...)
-	b = append(b, byte(len(md.Title)))
-	b = append(b, []byte(md.Title)...)
+	b = appendString(b, md.Date)
+	b = appendString(b, md.Title)
 	b = append(b, int32ToBytes(int32(md.Number))...)
+	b = append(b, int32ToBytes(int32(md.Tracks))...)
+	b = append(b, int32ToBytes(int32(md.Disc))...)
+	b = append(b, int32ToBytes(int32(md.Discs))...)
+	b = appendString(b, md.Genre)
+	b = appendString(b, md.Composer)
+	b = appendString(b, md.Comment)
 	b = append(b, int32ToBytes(int32(md.Length))...)
+	b = appendString(b, md.Codec)
+	b = append(b, int32ToBytes(int32(md.Bitrate))...)
+	b = append(b, int32ToBytes(int32(md.BitDepth))...)
+	b = append(b, int32ToBytes(int32(md.SampleRate))...)
+	b = append(b, int32ToBytes(int32(md.Channels))...)
+	b = appendString(b, md.CueSheet)
+
+	return b
+}
+
+func appendString(b []byte, s string) []byte {
+	b = append(b, int32ToBytes(int32(len(s)))...)
+
+	return append(b, []byte(s)...)
+}
+
+// metadataReader reads values from the serialized metadata record.
+// Once the record end is reached err is set and zero values
+// are returned.
+type metadataReader struct {
+	buf []byte
+	err bool
+}
+
+func (r *metadataReader) next(n int) []byte {
+	if r.err || n < 0 || n > len(r.buf) {
+		r.err = true
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
 
 	return b
 }
 
+func (r *metadataReader) int64() int64 {
+	b := r.next(8)
+	if b == nil {
+		return 0
+	}
+
+	return bytesToInt64(b)
+}
+
+func (r *metadataReader) int() int {
+	b := r.next(4)
+	if b == nil {
+		return 0
+	}
+
+	return int(bytesToInt32(b))
+}
+
+func (r *metadataReader) string() string {
+	return string(r.next(r.int()))
+}
+
 func bytesToInt32(b []byte) int32 {
 	if len(b) != 4 {
 		panic("four-element slice expected")
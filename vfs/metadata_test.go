@@ -0,0 +1,65 @@
+// Copyright 2026 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of Chub.
+//
+// Chub is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Chub is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Chub. If not, see <http://www.gnu.org/licenses/>.
+
+package vfs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vchimishuk/chub/assert"
+)
+
+func TestMetadataSerialize(t *testing.T) {
+	md := &metadata{
+		modified:    time.Unix(1700000000, 123456789),
+		Artist:      "Artist",
+		AlbumArtist: "Various",
+		Album:       "Album",
+		Year:        1999,
+		Date:        "1999-12-31",
+		Title:       "Title",
+		Number:      3,
+		Tracks:      12,
+		Disc:        1,
+		Discs:       2,
+		Genre:       "Jazz",
+		Composer:    "Composer",
+		Comment:     "Comment",
+		Length:      180000,
+		Codec:       "flac",
+		Bitrate:     1411200,
+		BitDepth:    24,
+		SampleRate:  96000,
+		Channels:    2,
+		CueSheet:    "FILE \"a.flac\" WAVE",
+	}
+	b := serializeMetadata(md)
+	md2, ok := deserializeMetadata(b)
+	assert.True(t, ok)
+	assert.True(t, *md2 == *md)
+
+	// Truncated record.
+	_, ok = deserializeMetadata(b[:len(b)-1])
+	assert.True(t, !ok)
+
+	// Record written by the previous version has no header.
+	old := append(int64ToBytes(1700000000), 6)
+	old = append(old, []byte("Artist")...)
+	_, ok = deserializeMetadata(old)
+	assert.True(t, !ok)
+}
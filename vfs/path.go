@@ -302,6 +302,18 @@ func readTracks(p *Path) ([]Entry, error) {
 		if err != nil {
 			// TODO: Log ignored file.
 		} else {
+			// Album image with embedded CUE sheet is split
+			// into tracks the same way as with external one.
+			// The file is listed as a whole if the sheet has
+			// no usable tracks.
+			sheet, err := embeddedCueSheet(pp)
+			if err == nil && sheet != nil {
+				cueTracks, err := cueSheetTracks(p, sheet)
+				if err == nil && len(cueTracks) > 0 {
+					tracks = append(tracks, cueTracks...)
+					continue
+				}
+			}
 			t, err := pp.Track()
 			// Ignore invalid and unsupported tracks.
 			if err == nil {
@@ -357,6 +369,11 @@ func cueSheetFileTrack(base *Path, sheet *cue.Sheet, file int, track int) (*Trac
 		return nil, errors.New("CUE start INDEX not found")
 	}
 
+	md, err := getMetadata(pth)
+	if err != nil {
+		return nil, err
+	}
+
 	var start int = i.Time.Milliseconds()
 	var end int
 
@@ -367,21 +384,22 @@ func cueSheetFileTrack(base *Path, sheet *cue.Sheet, file int, track int) (*Trac
 		}
 		end = ii.Time.Milliseconds()
 	} else {
-		md, err := getMetadata(pth)
-		if err != nil {
-			return nil, err
-		}
 		end = md.Length
 	}
 
 	return &Track{
-		Path:   pth,
-		Tag:    newTag(sheet, t),
-		Length: end - start,
-		Part:   true,
-		Number: t.Number,
-		Start:  start,
-		End:    end,
+		Path:       pth,
+		Tag:        newTag(sheet, t),
+		Length:     end - start,
+		Part:       true,
+		Number:     t.Number,
+		Start:      start,
+		End:        end,
+		Codec:      md.Codec,
+		Bitrate:    md.Bitrate,
+		BitDepth:   md.BitDepth,
+		SampleRate: md.SampleRate,
+		Channels:   md.Channels,
 	}, nil
 }
 
@@ -423,13 +441,26 @@ func newTrack(p *Path) (*Track, error) {
 		return &Track{
 			Path: p,
 			Tag: &Tag{
-				Artist: md.Artist,
-				Album:  md.Album,
-				Year:   md.Year,
-				Title:  md.Title,
-				Number: md.Number,
+				Artist:      md.Artist,
+				AlbumArtist: md.AlbumArtist,
+				Album:       md.Album,
+				Year:        md.Year,
+				Date:        md.Date,
+				Title:       md.Title,
+				Number:      md.Number,
+				Tracks:      md.Tracks,
+				Disc:        md.Disc,
+				Discs:       md.Discs,
+				Genre:       md.Genre,
+				Composer:    md.Composer,
+				Comment:     md.Comment,
 			},
-			Length: md.Length,
+			Length:     md.Length,
+			Codec:      md.Codec,
+			Bitrate:    md.Bitrate,
+			BitDepth:   md.BitDepth,
+			SampleRate: md.SampleRate,
+			Channels:   md.Channels,
 		}, nil
 	}
 }
@@ -527,15 +558,64 @@ func cueSheetForFile(p *Path) (*cue.Sheet, error) {
 		}
 	}
 
-	return nil, nil
+	return embeddedCueSheet(p)
+}
+
+// embeddedCueSheet returns CUE sheet embedded into the audio file
+// or nil if there is no one. Sheet's FILE is replaced with the file
+// itself, because embedded sheets often keep the name of the original
+// rip image.
+func embeddedCueSheet(p *Path) (*cue.Sheet, error) {
+	md, err := getMetadata(p)
+	if err != nil {
+		return nil, err
+	}
+	if md.CueSheet == "" {
+		return nil, nil
+	}
+	sheet, err := cue.Parse(strings.NewReader(md.CueSheet))
+	// Broken sheet must not make the file unplayable.
+	if err != nil || len(sheet.Files) != 1 {
+		return nil, nil
+	}
+	sheet.Files[0].Name = p.Base()
+
+	// Sheets generated from binary cue sheets have no album information.
+	if sheet.Title == "" {
+		sheet.Title = md.Album
+	}
+	if sheet.Performer == "" {
+		sheet.Performer = md.AlbumArtist
+	}
+	if sheet.Performer == "" {
+		sheet.Performer = md.Artist
+	}
+	for _, c := range []struct{ name, val string }{
+		{"DATE", md.Date},
+		{"GENRE", md.Genre},
+	} {
+		if c.val != "" && findComment(sheet.Comments, c.name) == "" {
+			sheet.Comments = append(sheet.Comments, c.name+" "+c.val)
+		}
+	}
+
+	return sheet, nil
 }
 
 func newTag(sheet *cue.Sheet, track *cue.Track) *Tag {
+	date := findComment(sheet.Comments, "DATE")
 	tag := &Tag{
-		Album:  sheet.Title,
-		Year:   findYear(sheet.Comments),
-		Title:  track.Title,
-		Number: track.Number,
+		AlbumArtist: sheet.Performer,
+		Album:       sheet.Title,
+		Year:        findYear(date),
+		Date:        date,
+		Title:       track.Title,
+		Number:      track.Number,
+		Tracks:      sheetTracks(sheet),
+		Disc:        findInt(sheet.Comments, "DISCNUMBER"),
+		Discs:       findInt(sheet.Comments, "TOTALDISCS"),
+		Genre:       findComment(sheet.Comments, "GENRE"),
+		Comment:     findComment(sheet.Comments, "COMMENT"),
 	}
 
 	if len(track.Performer) > 0 {
@@ -543,12 +623,41 @@ func newTag(sheet *cue.Sheet, track *cue.Track) *Tag {
 	} else {
 		tag.Artist = sheet.Performer
 	}
+	if len(track.Songwriter) > 0 {
+		tag.Composer = track.Songwriter
+	} else {
+		tag.Composer = sheet.Songwriter
+	}
 
 	return tag
 }
 
-func findYear(comments []string) int {
-	i, err := strconv.Atoi(findComment(comments, "DATE"))
+// sheetTracks returns total number of tracks in all files
+// of the CUE sheet.
+func sheetTracks(sheet *cue.Sheet) int {
+	n := 0
+	for _, f := range sheet.Files {
+		n += len(f.Tracks)
+	}
+
+	return n
+}
+
+// findYear returns year from the date string, e. g. 1999-12-31.
+func findYear(date string) int {
+	if len(date) < 4 {
+		return 0
+	}
+	i, err := strconv.Atoi(date[:4])
+	if err != nil {
+		return 0
+	}
+
+	return i
+}
+
+func findInt(comments []string, name string) int {
+	i, err := strconv.Atoi(findComment(comments, name))
 	if err != nil {
 		return 0
 	}